@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"payment-service/models"
+)
+
+func TestAPIv2_MiddlewareErrorsFollowGroupEnvelope(t *testing.T) {
+	t.Setenv("AUTH_REQUIRED", "true")
+	a := testApp(t)
+
+	req := httptest.NewRequest("GET", "/api/v2/balance/"+testWallet, nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	var v2 models.V2ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &v2); err != nil || rec.Code != 401 || v2.Error.Code != "unauthorized" || v2.Meta.RequestID != "req-42" { t.Fatalf("v2: %d %s", rec.Code, rec.Body) }
+	if rec.Header().Get("X-Request-ID") != "req-42" { t.Fatalf("X-Request-ID not echoed: %v", rec.Header()) }
+
+	rec = httptest.NewRecorder()
+	a.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/balance/"+testWallet, nil))
+	if rec.Code != 401 || rec.Body.String() != `{"success":false,"message":"Unauthorized"}` || rec.Header().Get("X-Request-ID") != "" { t.Fatalf("v1 changed: %d %s", rec.Code, rec.Body) }
+
+	rec = httptest.NewRecorder()
+	a.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v2/health", nil))
+	var ok models.V2Response[models.HealthStatus]
+	if err := json.Unmarshal(rec.Body.Bytes(), &ok); err != nil || rec.Code != 200 || ok.Data.Version == "" || len(ok.Meta.RequestID) != 32 { t.Fatalf("v2 health: %d %s", rec.Code, rec.Body) }
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"payment-service/config"
+	"payment-service/grpcapi"
+	"payment-service/handlers"
+	"payment-service/ledger"
+	"payment-service/middleware"
+	"payment-service/services"
+	"payment-service/storage"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// app — сервисы и роутер, собранные по конфигурации.
+type app struct {
+	live       *config.Live
+	router     *gin.Engine
+	grpc       *grpc.Server // nil без GRPC_PORT
+	health     *services.Health
+	hub        *services.Hub
+	watcher    *services.Watcher
+	exports    *services.ExportService
+	deposits   *services.DepositService // nil без DEPOSIT_MASTER_SEED
+	reconciler *services.Reconciler
+	wallets    func() []string // кошельки для сверки: WATCH_WALLETS и кошельки мерчантов
+}
+
+func newApp(live *config.Live, store *storage.Store) (*app, error) {
+	cfg := live.Get()
+	a := &app{live: live}
+
+	// Сервисы
+	tonService, err := services.NewTONService(live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TON service: %w", err)
+	}
+	proofs := services.NewTonProofService(cfg.TonProofDomains, cfg.TonProofTTL, cfg.SessionTTL, cfg.SessionSecret)
+	tonapi := services.NewLiveRestTonAPIAdapter(live)
+	a.hub = services.NewHub()
+	a.watcher = services.NewWatcher(tonService, cfg.WatchWallets, cfg.WatchInterval)
+	a.watcher.SetStore(store)
+	a.watcher.AddHook(func(_ context.Context, t services.IncomingTransfer) { a.hub.PublishTransfer(t) })
+
+	var rates services.RateProvider
+	switch cfg.RatesProvider {
+	case "file":
+		if rates, err = services.NewFileRateProvider(cfg.RatesFile); err != nil {
+			return nil, fmt.Errorf("failed to load rates: %w", err)
+		}
+	default:
+		rates = services.NewTonAPIRateProvider(tonapi, cfg.RatesCacheTTL)
+	}
+	invoices := services.NewInvoiceService(store, rates, a.hub, cfg.AppWallet, cfg.RateSlippagePct, cfg.InvoiceTTL)
+	invoices.SetWatcher(a.watcher)
+	invoices.SetConfig(live)
+	journal := ledger.New(store)
+	invoices.SetLedger(journal)
+	a.watcher.AddHook(invoices.OnTransfer)
+	orphans := services.NewOrphanService(store, invoices)
+	invoices.SetOrphans(orphans)
+	// Проверки готовности (/readyz, /api/health)
+	a.health = services.NewHealth(buildInfo(), cfg.HealthCheckTimeout)
+	a.health.Add(services.HealthCheck{Name: "storage", Group: services.HealthGroupStorage, Check: func(context.Context) error { return store.Ping() }})
+	a.health.Add(services.HealthCheck{Name: "tonapi", Group: services.HealthGroupTON, Check: tonapi.Status})
+	if cfg.WatchMaxLag > 0 {
+		a.health.Add(services.WatcherLagCheck(a.watcher, cfg.WatchMaxLag))
+	}
+	if cfg.DepositMasterSeed != "" {
+		sender, err := services.NewLiteSweepSender(cfg.LiteServerConfigURL, cfg.DepositMasterSeed, cfg.DepositWalletVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sweeper: %w", err)
+		}
+		a.health.Add(services.HealthCheck{Name: "liteserver", Group: services.HealthGroupTON, Check: sender.Ping})
+		a.deposits, err = services.NewDepositService(store, tonService, cfg.DepositMasterSeed, cfg.DepositWalletVersion, sender, cfg.SweepMinTon)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create deposit service: %w", err)
+		}
+		a.deposits.SetWatcher(a.watcher)
+		a.deposits.SetLedger(journal)
+		invoices.SetDeposits(a.deposits)
+	}
+	a.exports, err = services.NewExportService(tonService, rates, store, cfg.ExportDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export service: %w", err)
+	}
+	a.exports.SetTTL(cfg.ExportJobTTL)
+	merchants := services.NewMerchantService(store)
+	merchants.SetWatcher(a.watcher)
+
+	// Сверка с блокчейном
+	a.reconciler = services.NewReconciler(tonService, store, invoices, orphans, journal)
+	if a.deposits != nil {
+		a.reconciler.SetDeposits(a.deposits)
+	}
+	a.wallets = func() []string {
+		wallets := append([]string(nil), cfg.WatchWallets...)
+		all, _ := merchants.List()
+		for _, m := range all {
+			wallets = append(wallets, m.Wallets...)
+		}
+		return wallets
+	}
+	auth := middleware.NewAuthenticator(live, merchants, proofs)
+	if cfg.GRPCPort != "" {
+		a.grpc = grpcapi.NewServer(tonService, auth, live)
+	}
+
+	// Инициализация обработчиков
+	paymentHandler := handlers.NewPaymentHandlerWithService(tonService, live)
+	healthHandler := handlers.NewHealthHandler(a.health)
+	wsHandler := handlers.NewWSHandler(a.hub, auth, live)
+	authHandler := handlers.NewAuthHandler(proofs)
+	invoiceHandler := handlers.NewInvoiceHandler(invoices, rates, live)
+	orphanHandler := handlers.NewOrphanHandler(orphans)
+	exportHandler := handlers.NewExportHandler(a.exports, live)
+	configHandler := handlers.NewConfigHandler(live)
+	ledgerHandler := handlers.NewLedgerHandler(journal, a.hub)
+	reconcileHandler := handlers.NewReconcileHandler(a.reconciler, a.wallets)
+	merchantHandler := handlers.NewMerchantHandler(merchants)
+	walletAccess := auth.WalletParam("account")
+	operator := auth.RequireOperator()
+	idempotent := middleware.Idempotency(services.NewIdempotencyService(store, cfg.IdempotencyTTL))
+
+	// Настройка роутера
+	router := gin.Default()
+
+	// Middleware
+	router.Use(middleware.Tracing())
+	router.Use(middleware.CORS())
+	router.Use(middleware.Logger())
+
+	// Пробы Kubernetes: без аутентификации, вне /api
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+
+	// Маршруты (описаны в handlers.OpenAPISpec)
+	api := router.Group("/api", auth.Middleware(), idempotent)
+	{
+		api.POST("/check-payment", paymentHandler.CheckPayment)
+		api.POST("/validate-payment", paymentHandler.ValidatePayment)
+		api.GET("/account-info/:account", walletAccess, paymentHandler.GetAccountInfo)
+		api.GET("/transactions/:account", walletAccess, paymentHandler.GetTransactionHistory)
+		api.GET("/balance/:account", walletAccess, paymentHandler.GetBalance)
+		api.GET("/health", healthHandler.Health)
+		api.GET("/openapi.json", handlers.ServeOpenAPI)
+		api.POST("/payment-link", paymentHandler.CreatePaymentLink)
+		api.GET("/payment-link/qr", paymentHandler.PaymentLinkQR)
+		api.POST("/tonconnect/transaction", paymentHandler.CreateTonConnectTransaction)
+		api.POST("/tonconnect/verify", paymentHandler.VerifyTonConnectTransaction)
+		api.GET("/ws", wsHandler.Serve)
+		api.POST("/auth/ton-proof/payload", authHandler.TonProofPayload)
+		api.POST("/auth/ton-proof", authHandler.VerifyTonProof)
+		api.POST("/invoices", invoiceHandler.CreateInvoice)
+		api.GET("/invoices/:id", invoiceHandler.GetInvoice)
+		api.GET("/invoices/:id/payment-link", invoiceHandler.GetInvoicePaymentLink)
+		api.GET("/rates/:currency", invoiceHandler.GetRate)
+		api.GET("/orphans", orphanHandler.ListOrphans)
+		api.GET("/orphans/:id", orphanHandler.GetOrphan)
+		api.POST("/orphans/:id/attach", operator, orphanHandler.AttachOrphan)
+		api.POST("/orphans/:id/refund", operator, orphanHandler.RefundOrphan)
+		api.GET("/ledger/wallets/:account/balance", walletAccess, ledgerHandler.GetWalletBalance)
+		api.GET("/exports/transactions", exportHandler.ExportTransactions)
+		api.GET("/exports/jobs/:id", exportHandler.GetExportJob)
+		api.GET("/exports/jobs/:id/download", exportHandler.DownloadExport)
+	}
+
+	// API v2: типизированный data, ошибки {code, message, details}, meta.request_id; v1 выше не меняется
+	v2 := router.Group("/api/v2", middleware.APIv2(), auth.Middleware(), idempotent)
+	{
+		v2.POST("/check-payment", paymentHandler.CheckPaymentV2)
+		v2.POST("/validate-payment", paymentHandler.ValidatePaymentV2)
+		v2.GET("/account-info/:account", walletAccess, paymentHandler.GetAccountInfoV2)
+		v2.GET("/transactions/:account", walletAccess, paymentHandler.GetTransactionHistoryV2)
+		v2.GET("/balance/:account", walletAccess, paymentHandler.GetBalanceV2)
+		v2.GET("/health", healthHandler.HealthV2)
+		v2.POST("/payment-link", paymentHandler.CreatePaymentLinkV2)
+		v2.POST("/invoices", invoiceHandler.CreateInvoiceV2)
+		v2.GET("/invoices/:id", invoiceHandler.GetInvoiceV2)
+		v2.GET("/rates/:currency", invoiceHandler.GetRateV2)
+	}
+
+	admin := router.Group("/api/admin", middleware.AdminAuth(live), idempotent)
+	{
+		admin.POST("/merchants", merchantHandler.CreateMerchant)
+		admin.GET("/merchants", merchantHandler.ListMerchants)
+		admin.GET("/merchants/:id", merchantHandler.GetMerchant)
+		admin.PUT("/merchants/:id", merchantHandler.UpdateMerchant)
+		admin.DELETE("/merchants/:id", merchantHandler.DeleteMerchant)
+		admin.POST("/merchants/:id/api-keys", merchantHandler.IssueAPIKey)
+		admin.DELETE("/merchants/:id/api-keys/:key_id", merchantHandler.RevokeAPIKey)
+		admin.GET("/ledger/entries", ledgerHandler.ListEntries)
+		admin.GET("/ledger/balances", ledgerHandler.GetBalances)
+		admin.GET("/ledger/check", ledgerHandler.Check)
+		admin.POST("/ledger/payouts", ledgerHandler.RecordPayout)
+		admin.POST("/ledger/refunds", ledgerHandler.RecordRefund)
+		admin.POST("/ledger/openings", ledgerHandler.RecordOpening)
+		admin.POST("/reconcile", reconcileHandler.Run)
+		admin.GET("/reconcile/last", reconcileHandler.Last)
+		admin.GET("/config", configHandler.GetConfig)
+		admin.POST("/config/reload", configHandler.Reload)
+	}
+	a.router = router
+	return a, nil
+}
+
+// runBackground — watcher, перезагрузка конфигурации, sweeper депозитов, периодическая сверка
+// и фоновые выгрузки (их контекст отменяется при остановке).
+func (a *app) runBackground(bg *background) {
+	cfg := a.live.Get()
+	if a.deposits != nil {
+		bg.Go(func(ctx context.Context) { a.deposits.RunSweeper(ctx, cfg.SweepInterval) })
+	}
+	if cfg.ReconcileInterval > 0 {
+		bg.Go(func(ctx context.Context) { a.reconciler.RunEvery(ctx, cfg.ReconcileInterval, a.wallets) })
+	}
+	bg.Go(a.watcher.Run)
+	bg.Go(a.exports.Run)
+	bg.Go(func(ctx context.Context) { a.live.Watch(ctx, cfg.ConfigWatchInterval) })
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"payment-service/models"
+)
+
+func doRequest(a *app, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// Анонимный доступ к кошелькам — только пока не настроен ни один ключ.
+func TestAuth_AnonymousClosedOnceKeysExist(t *testing.T) {
+	a := testApp(t)
+	if rec := doRequest(a, "GET", "/api/balance/"+testWallet, "", ""); rec.Code != 200 { t.Fatalf("no keys configured: %d %s", rec.Code, rec.Body) }
+
+	rec := doRequest(a, "POST", "/api/admin/merchants", adminKey, `{"name":"Shop","wallets":["`+testWallet+`"]}`)
+	var created struct{ Data models.MerchantCreated }
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); rec.Code != 201 || err != nil || created.Data.APIKey == nil { t.Fatalf("create merchant: %d %s", rec.Code, rec.Body) }
+	key := created.Data.APIKey.Key
+
+	for _, path := range []string{"/api/balance/" + testWallet, "/api/transactions/" + testWallet, "/api/account-info/0:b"} {
+		if rec := doRequest(a, "GET", path, "", ""); rec.Code != 401 { t.Errorf("anonymous %s after merchant key issued: %d", path, rec.Code) }
+	}
+	if rec := doRequest(a, "POST", "/api/check-payment", "", `{"merchant_address":"0:b","min_amount_ton":"1"}`); rec.Code != 401 { t.Errorf("anonymous check-payment: %d", rec.Code) }
+	if rec := doRequest(a, "GET", "/api/balance/"+testWallet, key, ""); rec.Code != 200 { t.Errorf("merchant's own wallet: %d %s", rec.Code, rec.Body) }
+	if rec := doRequest(a, "GET", "/api/balance/0:b", key, ""); rec.Code != 403 { t.Errorf("merchant's foreign wallet: %d", rec.Code) }
+}
+
+func TestAuth_ClientKeysRejectAnonymous(t *testing.T) {
+	t.Setenv("CLIENT_API_KEYS", "client-key")
+	a := testApp(t)
+	if rec := doRequest(a, "GET", "/api/balance/"+testWallet, "", ""); rec.Code != 401 { t.Fatalf("anonymous with CLIENT_API_KEYS: %d", rec.Code) }
+	if rec := doRequest(a, "GET", "/api/balance/"+testWallet, "client-key", ""); rec.Code != 200 { t.Fatalf("client key: %d %s", rec.Code, rec.Body) }
+}
+
+// Разбор потерянных платежей меняет инвойсы и журнал — только по ключу, даже без настроенной аутентификации.
+func TestAuth_OrphanActionsRequireOperator(t *testing.T) {
+	a := testApp(t)
+	for _, path := range []string{"/api/orphans/missing/attach", "/api/orphans/missing/refund"} {
+		if rec := doRequest(a, "POST", path, "", `{"invoice_id":"inv"}`); rec.Code != 401 { t.Errorf("anonymous %s: %d", path, rec.Code) }
+		if rec := doRequest(a, "POST", path, adminKey, `{"invoice_id":"inv"}`); rec.Code != 404 { t.Errorf("admin %s: %d %s", path, rec.Code, rec.Body) }
+	}
+}
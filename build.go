@@ -0,0 +1,30 @@
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"payment-service/models"
+)
+
+// Версия сборки задаётся при сборке:
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD)"
+//
+// Без -ldflags коммит берётся из VCS-информации, которую записывает go build.
+var (
+	version = "dev"
+	commit  = ""
+)
+
+func buildInfo() models.BuildInfo {
+	b := models.BuildInfo{Version: version, Commit: commit, GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok && b.Commit == "" {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				b.Commit = s.Value
+			}
+		}
+	}
+	return b
+}
@@ -1,8 +1,9 @@
 package config
 
 import (
+	"errors"
 	"os"
-	"strconv"
+	"path/filepath"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -16,43 +17,166 @@ type Config struct {
 	MaxRetries       int
 	AppWallet        string
 	MinConfirmations int
+
+	// Ключи клиентов сервиса (дашборды, бэкенды). Пусто — аутентификация выключена.
+	ClientAPIKeys []string
+	// Кошельки, которые опрашивает watcher. По умолчанию — AppWallet.
+	WatchWallets  []string
+	WatchInterval time.Duration
+	// Размер исходящего буфера WebSocket-клиента; переполнение = медленный клиент.
+	WSSendBuffer int
+
+	// TON Connect ton_proof: допустимые домены (пусто — ton_proof выключен), окно подписи и сессии.
+	TonProofDomains []string
+	TonProofTTL     time.Duration
+	SessionTTL      time.Duration
+	SessionSecret   string
+
+	// Данные кошельков только для аутентифицированных вызовов (ключ клиента/мерчанта или сессия ton_proof).
+	AuthRequired bool
+	// Ключи админского API (реестр мерчантов). Пусто — админский API выключен.
+	AdminAPIKeys []string
+
+	// Хранилище: JSON-файл; пусто — только в памяти.
+	StoragePath string
+
+	// Инвойсы и курсы: RATES_PROVIDER = tonapi | file.
+	RatesProvider   string
+	RatesFile       string
+	RatesCacheTTL   time.Duration
+	RateSlippagePct float64
+	InvoiceTTL      time.Duration
+
+	// Депозитный режим: мастер-ключ (ed25519 seed, hex), версия кошелька (v4r2 | v5r1),
+	// сметание средств на кошелёк мерчанта через liteserver'ы.
+	DepositMasterSeed    string
+	DepositWalletVersion string
+	LiteServerConfigURL  string
+	SweepInterval        time.Duration
+	SweepMinTon          float64
+
+	// Периодическая сверка с блокчейном (0 — выключена; разово: `payment-service reconcile`)
+	ReconcileInterval time.Duration
+
+	// Выгрузки: каталог файлов фоновых задач, максимальный период для выгрузки потоком
+	// и сколько хранится завершённая фоновая выгрузка
+	ExportDir          string
+	ExportSyncMaxRange time.Duration
+	ExportJobTTL       time.Duration
+
+	// Сколько хранится ответ на запрос с Idempotency-Key
+	IdempotencyTTL time.Duration
+
+	// HTTP-сервер: таймауты соединений, предел заголовков и время на остановку
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration // с запасом на потоковые выгрузки
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownTimeout   time.Duration
+
+	// Опрос файла конфигурации для перезагрузки (0 — только по SIGHUP)
+	ConfigWatchInterval time.Duration
+
+	// Порт gRPC API; пусто — gRPC выключен.
+	GRPCPort string
+
+	// Проверки готовности: таймаут каждой проверки и допустимое отставание watcher (0 — не проверять)
+	HealthCheckTimeout time.Duration
+	WatchMaxLag        time.Duration
+
+	// Трассировка OpenTelemetry: экспортёр (none | stdout | otlp) и адрес OTLP/HTTP-коллектора
+	// (пусто — по умолчанию экспортёра, http://localhost:4318)
+	TracesExporter string
+	OTLPEndpoint   string
+
+	settings []Setting
 }
 
-func LoadConfig() *Config {
+// Settings — итоговые значения с источником (секреты скрыты), для --print-config.
+func (c *Config) Settings() []Setting { return c.settings }
+
+// Load — конфигурация слоями: значения по умолчанию < файл path (YAML/TOML; пусто — CONFIG_FILE)
+// < переменные окружения (включая .env). Секреты можно передать файлом: API_KEY_FILE и т.п.
+// Все ошибки разбора и валидации возвращаются вместе; cfg возвращается и при ошибке (для --print-config).
+func Load(path string) (*Config, error) {
 	godotenv.Load()
 
-	return &Config{
-		ServerPort:       getEnv("SERVER_PORT", "8080"),
-		TonApiURL:        getEnv("TON_API_URL", "https://tonapi.io"),
-		ApiKey:           getEnv("API_KEY", ""),
-		RequestTimeout:   getEnvAsDuration("REQUEST_TIMEOUT", 30*time.Second),
-		MaxRetries:       getEnvAsInt("MAX_RETRIES", 3),
-		AppWallet:        getEnv("APP_WALLET", ""),
-		MinConfirmations: getEnvAsInt("MIN_CONFIRMATIONS", 1),
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
 	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	l, err := newLoader(path)
+	if err != nil {
+		return nil, err
 	}
-	return defaultValue
-}
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+	appWallet := l.str("APP_WALLET", "")
+	var defaultWatch []string
+	if appWallet != "" {
+		defaultWatch = []string{appWallet}
 	}
-	return defaultValue
-}
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
-		}
+	cfg := &Config{
+		ServerPort:       l.str("SERVER_PORT", "8080"),
+		TonApiURL:        l.str("TON_API_URL", "https://tonapi.io"),
+		ApiKey:           l.secret("API_KEY"),
+		RequestTimeout:   l.duration("REQUEST_TIMEOUT", 30*time.Second),
+		MaxRetries:       l.integer("MAX_RETRIES", 3),
+		AppWallet:        appWallet,
+		MinConfirmations: l.integer("MIN_CONFIRMATIONS", 1),
+
+		ClientAPIKeys: l.secretList("CLIENT_API_KEYS"),
+		WatchWallets:  l.list("WATCH_WALLETS", defaultWatch),
+		WatchInterval: l.duration("WATCH_INTERVAL", 5*time.Second),
+		WSSendBuffer:  l.integer("WS_SEND_BUFFER", 64),
+
+		TonProofDomains: l.list("TON_PROOF_DOMAINS", nil),
+		TonProofTTL:     l.duration("TON_PROOF_TTL", 15*time.Minute),
+		SessionTTL:      l.duration("SESSION_TTL", time.Hour),
+		SessionSecret:   l.secret("SESSION_SECRET"),
+
+		AuthRequired: l.boolean("AUTH_REQUIRED", l.boolean("TON_PROOF_REQUIRED", false)),
+		AdminAPIKeys: l.secretList("ADMIN_API_KEYS"),
+
+		StoragePath: l.str("STORAGE_PATH", ""),
+
+		RatesProvider:   l.str("RATES_PROVIDER", "tonapi"),
+		RatesFile:       l.str("RATES_FILE", ""),
+		RatesCacheTTL:   l.duration("RATES_CACHE_TTL", time.Minute),
+		RateSlippagePct: l.float("RATE_SLIPPAGE_PCT", 1),
+		InvoiceTTL:      l.duration("INVOICE_TTL", 30*time.Minute),
+
+		DepositMasterSeed:    l.secret("DEPOSIT_MASTER_SEED"),
+		DepositWalletVersion: l.str("DEPOSIT_WALLET_VERSION", "v4r2"),
+		LiteServerConfigURL:  l.str("LITESERVER_CONFIG_URL", "https://ton.org/global.config.json"),
+		SweepInterval:        l.duration("SWEEP_INTERVAL", 10*time.Minute),
+		SweepMinTon:          l.float("SWEEP_MIN_TON", 0.1),
+
+		ReconcileInterval: l.duration("RECONCILE_INTERVAL", 0),
+
+		ExportDir:          l.str("EXPORT_DIR", filepath.Join(os.TempDir(), "payment-exports")),
+		ExportSyncMaxRange: l.duration("EXPORT_SYNC_MAX_RANGE", 31*24*time.Hour),
+		ExportJobTTL:       l.duration("EXPORT_JOB_TTL", 24*time.Hour),
+
+		IdempotencyTTL: l.duration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		ReadTimeout:       l.duration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ReadHeaderTimeout: l.duration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		WriteTimeout:      l.duration("SERVER_WRITE_TIMEOUT", 5*time.Minute),
+		IdleTimeout:       l.duration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+		MaxHeaderBytes:    l.integer("SERVER_MAX_HEADER_BYTES", 1<<20),
+		ShutdownTimeout:   l.duration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		ConfigWatchInterval: l.duration("CONFIG_WATCH_INTERVAL", 5*time.Second),
+
+		GRPCPort: l.str("GRPC_PORT", ""),
+
+		HealthCheckTimeout: l.duration("HEALTH_CHECK_TIMEOUT", 3*time.Second),
+		WatchMaxLag:        l.duration("WATCH_MAX_LAG", time.Minute),
+
+		TracesExporter: l.str("OTEL_TRACES_EXPORTER", "none"),
+		OTLPEndpoint:   l.str("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
 	}
-	return defaultValue
+	cfg.settings = l.settings
+	return cfg, errors.Join(append(l.errs, cfg.Validate())...)
 }
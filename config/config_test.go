@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWallet = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+
+func writeFile(t *testing.T, name, body string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil { t.Fatalf("write: %v", err) }
+	return path
+}
+
+func setting(c *Config, key string) Setting {
+	for _, s := range c.Settings() {
+		if s.Key == key {
+			return s
+		}
+	}
+	return Setting{}
+}
+
+func TestLoad_LayersFileEnvAndSecretFiles(t *testing.T) {
+	path := writeFile(t, "config.yaml", "app_wallet: "+testWallet+"\nserver:\n  port: 9090\nrequest_timeout: 10s\nwatch_wallets: ["+testWallet+"]\nmax_retries: 5\n")
+	t.Setenv("MAX_RETRIES", "7")
+	t.Setenv("API_KEY_FILE", writeFile(t, "tonapi.key", "secret-key\n"))
+
+	cfg, err := Load(path)
+	if err != nil { t.Fatalf("load: %v", err) }
+	if cfg.ServerPort != "9090" || cfg.RequestTimeout != 10*time.Second || cfg.AppWallet != testWallet || len(cfg.WatchWallets) != 1 { t.Fatalf("file layer: %+v", cfg) }
+	if cfg.MaxRetries != 7 || setting(cfg, "MAX_RETRIES").Source != SourceEnv { t.Fatalf("env must override file: %d", cfg.MaxRetries) }
+	if cfg.ApiKey != "secret-key" { t.Fatalf("API_KEY_FILE: %q", cfg.ApiKey) }
+	if s := setting(cfg, "API_KEY"); s.Value != redacted || s.Source != "API_KEY_FILE" { t.Fatalf("secret setting: %+v", s) }
+	if s := setting(cfg, "INVOICE_TTL"); s.Source != SourceDefault || s.Value != "30m0s" { t.Fatalf("default setting: %+v", s) }
+
+	toml := writeFile(t, "config.toml", "server_port = 8081\n[rates]\nprovider = \"file\"\nfile = \"rates.json\"\n")
+	if cfg, err = Load(toml); err != nil || cfg.ServerPort != "8081" || cfg.RatesProvider != "file" || cfg.RatesFile != "rates.json" { t.Fatalf("toml: %+v, %v", cfg, err) }
+}
+
+func TestLoad_ReportsAllErrorsTogether(t *testing.T) {
+	t.Setenv("MAX_RETRIES", "many")
+	t.Setenv("REQUEST_TIMEOUT", "-1s")
+	t.Setenv("APP_WALLET", "not-an-address")
+	t.Setenv("RATES_PROVIDER", "file")
+	t.Setenv("DEPOSIT_MASTER_SEED", "abcd")
+	t.Setenv("OTEL_TRACES_EXPORTER", "jaeger")
+	t.Setenv("SESSION_SECRET", "s3cret")
+
+	_, err := Load("")
+	if err == nil { t.Fatal("expected validation errors") }
+	for _, want := range []string{"MAX_RETRIES", "REQUEST_TIMEOUT", "APP_WALLET", "RATES_FILE is required", "DEPOSIT_MASTER_SEED", "OTEL_TRACES_EXPORTER", "TON_PROOF_DOMAINS"} {
+		if !strings.Contains(err.Error(), want) { t.Errorf("missing %s in:\n%v", want, err) }
+	}
+	if _, err := Load(writeFile(t, "config.ini", "x=1")); err == nil { t.Fatal("unknown file format accepted") }
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Live — текущий снимок конфигурации. Снимок неизменяем и заменяется атомарно:
+// потребители читают Get() на каждый запрос и видят либо старую, либо новую версию целиком.
+type Live struct {
+	path    string
+	current atomic.Pointer[Config]
+
+	mu       sync.Mutex // сериализует Reload
+	onChange []func(old, cur *Config)
+	stats    ReloadStats
+}
+
+// ReloadStats — счётчики перезагрузок (для админского API и логов).
+type ReloadStats struct {
+	Reloads    uint64    `json:"reloads"`
+	Failures   uint64    `json:"failures"`
+	LastReload time.Time `json:"last_reload,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	Changed    []string  `json:"changed,omitempty"` // ключи, изменённые последней успешной перезагрузкой
+	// изменённые ключи, которые читаются только при старте и вступят в силу после перезапуска
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// reloadable — настройки, которые читаются из снимка на каждый запрос и применяются без перезапуска.
+var reloadable = map[string]bool{
+	"TON_API_URL": true, "API_KEY": true, "REQUEST_TIMEOUT": true, "MAX_RETRIES": true, "APP_WALLET": true,
+	"CLIENT_API_KEYS": true, "ADMIN_API_KEYS": true, "AUTH_REQUIRED": true,
+	"WS_SEND_BUFFER": true, "EXPORT_SYNC_MAX_RANGE": true,
+}
+
+// NewLive — path: файл конфигурации, из которого читается перезагрузка (пусто — CONFIG_FILE).
+func NewLive(path string, cfg *Config) *Live {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	l := &Live{path: path}
+	l.current.Store(cfg)
+	return l
+}
+
+// Static — снимок без перезагрузки (тесты, разовые команды).
+func Static(cfg *Config) *Live { return NewLive("", cfg) }
+
+func (l *Live) Get() *Config { return l.current.Load() }
+
+// OnChange — колбэк после успешной замены снимка (вызывается под блокировкой Reload).
+func (l *Live) OnChange(fn func(old, cur *Config)) {
+	l.mu.Lock()
+	l.onChange = append(l.onChange, fn)
+	l.mu.Unlock()
+}
+
+// Reload — перечитывает файл, окружение и секреты; невалидная конфигурация
+// не применяется, текущий снимок остаётся прежним.
+func (l *Live) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cfg, err := Load(l.path)
+	l.stats.LastReload = time.Now().UTC()
+	if err != nil {
+		l.stats.Failures++
+		l.stats.LastError = err.Error()
+		log.Printf("config reload rejected (failures=%d): %v", l.stats.Failures, err)
+		return err
+	}
+	old := l.current.Swap(cfg)
+	l.stats.Reloads++
+	l.stats.LastError = ""
+	l.stats.Changed = changedKeys(old, cfg)
+	l.stats.RestartRequired = nil
+	for _, key := range l.stats.Changed {
+		if !reloadable[key] {
+			l.stats.RestartRequired = append(l.stats.RestartRequired, key)
+		}
+	}
+	log.Printf("config reloaded (reloads=%d), changed: %v", l.stats.Reloads, l.stats.Changed)
+	if len(l.stats.RestartRequired) > 0 {
+		log.Printf("config: restart required to apply %v", l.stats.RestartRequired)
+	}
+	for _, fn := range l.onChange {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+func (l *Live) Stats() ReloadStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats
+	s.Changed = append([]string(nil), s.Changed...)
+	s.RestartRequired = append([]string(nil), s.RestartRequired...)
+	return s
+}
+
+// changedKeys — ключи с новым значением или источником; секреты в settings скрыты,
+// поэтому сравниваются по полям Config.
+func changedKeys(old, cur *Config) []string {
+	prev := make(map[string]Setting, len(old.settings))
+	for _, s := range old.settings {
+		prev[s.Key] = s
+	}
+	var out []string
+	for _, s := range cur.settings {
+		if p, ok := prev[s.Key]; !ok || p != s {
+			out = append(out, s.Key)
+		}
+	}
+	for key, changed := range map[string]bool{
+		"API_KEY":             old.ApiKey != cur.ApiKey,
+		"SESSION_SECRET":      old.SessionSecret != cur.SessionSecret,
+		"DEPOSIT_MASTER_SEED": old.DepositMasterSeed != cur.DepositMasterSeed,
+		"CLIENT_API_KEYS":     !slices.Equal(old.ClientAPIKeys, cur.ClientAPIKeys),
+		"ADMIN_API_KEYS":      !slices.Equal(old.AdminAPIKeys, cur.AdminAPIKeys),
+	} {
+		if changed && !slices.Contains(out, key) {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// Watch — перезагрузка по SIGHUP и при изменении файла конфигурации (опрос mtime раз в interval;
+// 0 — только SIGHUP). Работает до отмены ctx.
+func (l *Live) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 && l.path != "" {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	mtime := l.modTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("config: SIGHUP received, reloading")
+			_ = l.Reload()
+			mtime = l.modTime()
+		case <-tick:
+			if m := l.modTime(); !m.Equal(mtime) {
+				mtime = m
+				log.Printf("config: %s changed, reloading", l.path)
+				_ = l.Reload()
+			}
+		}
+	}
+}
+
+func (l *Live) modTime() time.Time {
+	if l.path == "" {
+		return time.Time{}
+	}
+	st, err := os.Stat(l.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return st.ModTime()
+}
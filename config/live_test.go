@@ -0,0 +1,29 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestLive_ReloadSwapsValidSnapshotAndKeepsOldOnError(t *testing.T) {
+	path := writeFile(t, "config.yaml", "client_api_keys: [k1]\nrequest_timeout: 10s\n")
+	cfg, err := Load(path)
+	if err != nil { t.Fatalf("load: %v", err) }
+	live := NewLive(path, cfg)
+	var swapped [2]*Config
+	live.OnChange(func(old, cur *Config) { swapped = [2]*Config{old, cur} })
+
+	if err := os.WriteFile(path, []byte("client_api_keys: [k2, k3]\nrequest_timeout: 10s\nserver_port: 9000\nmax_retries: 5\n"), 0o600); err != nil { t.Fatalf("write: %v", err) }
+	if err := live.Reload(); err != nil { t.Fatalf("reload: %v", err) }
+	cur := live.Get()
+	if cur == cfg || !slices.Equal(cur.ClientAPIKeys, []string{"k2", "k3"}) || swapped != [2]*Config{cfg, cur} { t.Fatalf("snapshot not swapped: %+v", cur.ClientAPIKeys) }
+	st := live.Stats()
+	if st.Reloads != 1 || st.Failures != 0 || !slices.Contains(st.Changed, "CLIENT_API_KEYS") || slices.Contains(st.Changed, "REQUEST_TIMEOUT") { t.Fatalf("stats: %+v", st) }
+	if !slices.Equal(st.RestartRequired, []string{"SERVER_PORT"}) { t.Fatalf("restart required: %v", st.RestartRequired) }
+
+	if err := os.WriteFile(path, []byte("client_api_keys: [k4]\nrequest_timeout: -1s\n"), 0o600); err != nil { t.Fatalf("write: %v", err) }
+	if err := live.Reload(); err == nil { t.Fatal("invalid config applied") }
+	if live.Get() != cur { t.Fatal("invalid reload replaced the snapshot") }
+	if st = live.Stats(); st.Reloads != 1 || st.Failures != 1 || st.LastError == "" { t.Fatalf("failure not recorded: %+v", st) }
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Источники значения настройки.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// Setting — итоговое значение настройки и откуда оно взято.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default | file | env; для секретов из файла — "<KEY>_FILE"
+}
+
+const redacted = "<redacted>"
+
+// loader — чтение настроек слоями: окружение поверх файла поверх значений по умолчанию.
+// Ошибки разбора копятся в errs, чтобы сообщить обо всех сразу.
+type loader struct {
+	file     map[string]string // ключи как у переменных окружения (SERVER_PORT)
+	settings []Setting
+	errs     []error
+}
+
+func newLoader(path string) (*loader, error) {
+	l := &loader{file: map[string]string{}}
+	if path == "" {
+		return l, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &tree)
+	case ".toml":
+		err = toml.Unmarshal(raw, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	flatten("", tree, l.file)
+	return l, nil
+}
+
+// flatten — вложенные секции склеиваются через "_": server: {port: 8080} -> SERVER_PORT;
+// списки — через запятую, как в переменных окружения.
+func flatten(prefix string, tree map[string]any, out map[string]string) {
+	for k, v := range tree {
+		key := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case []any:
+			parts := make([]string, len(v))
+			for i, p := range v {
+				parts[i] = fmt.Sprint(p)
+			}
+			out[key] = strings.Join(parts, ",")
+		case nil:
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func (l *loader) lookup(key string) (string, string, bool) {
+	if v := os.Getenv(key); v != "" {
+		return v, SourceEnv, true
+	}
+	if v, ok := l.file[key]; ok && v != "" {
+		return v, SourceFile, true
+	}
+	return "", SourceDefault, false
+}
+
+// value — строковое значение и его разбор; при ошибке разбора — ошибка и значение по умолчанию.
+func value[T any](l *loader, key string, def T, parse func(string) (T, error)) T {
+	raw, src, ok := l.lookup(key)
+	if !ok {
+		shown := fmt.Sprint(def)
+		if list, isList := any(def).([]string); isList {
+			shown = strings.Join(list, ",")
+		}
+		l.settings = append(l.settings, Setting{Key: key, Value: shown, Source: SourceDefault})
+		return def
+	}
+	l.settings = append(l.settings, Setting{Key: key, Value: raw, Source: src})
+	v, err := parse(raw)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s=%q (%s): %w", key, raw, src, err))
+		return def
+	}
+	return v
+}
+
+func (l *loader) str(key, def string) string {
+	return value(l, key, def, func(s string) (string, error) { return s, nil })
+}
+
+func (l *loader) integer(key string, def int) int {
+	return value(l, key, def, strconv.Atoi)
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	return value(l, key, def, time.ParseDuration)
+}
+
+func (l *loader) float(key string, def float64) float64 {
+	return value(l, key, def, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+}
+
+func (l *loader) boolean(key string, def bool) bool {
+	return value(l, key, def, strconv.ParseBool)
+}
+
+// list — значения через запятую, пустые элементы отбрасываются.
+func (l *loader) list(key string, def []string) []string {
+	return value(l, key, def, func(s string) ([]string, error) { return splitList(s), nil })
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// secret — KEY или содержимое файла из KEY_FILE (Docker/K8s secrets); в Settings значение скрыто.
+func (l *loader) secret(key string) string {
+	if raw, src, ok := l.lookup(key); ok {
+		l.settings = append(l.settings, Setting{Key: key, Value: redacted, Source: src})
+		return raw
+	}
+	path, _, ok := l.lookup(key + "_FILE")
+	if !ok {
+		l.settings = append(l.settings, Setting{Key: key, Source: SourceDefault})
+		return ""
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s_FILE: %w", key, err))
+		return ""
+	}
+	l.settings = append(l.settings, Setting{Key: key, Value: redacted, Source: key + "_FILE"})
+	return strings.TrimSpace(string(b))
+}
+
+// secretList — как secret, но список (через запятую или по строке в файле).
+func (l *loader) secretList(key string) []string {
+	return splitList(l.secret(key))
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+)
+
+// Validate — проверка согласованности настроек; возвращает все найденные ошибки разом.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if p, err := strconv.Atoi(c.ServerPort); err != nil || p < 1 || p > 65535 {
+		fail("SERVER_PORT=%q: must be a port number 1-65535", c.ServerPort)
+	}
+	if c.GRPCPort != "" {
+		if p, err := strconv.Atoi(c.GRPCPort); err != nil || p < 1 || p > 65535 {
+			fail("GRPC_PORT=%q: must be a port number 1-65535", c.GRPCPort)
+		} else if c.GRPCPort == c.ServerPort {
+			fail("GRPC_PORT=%q: must differ from SERVER_PORT", c.GRPCPort)
+		}
+	}
+	if err := checkURL(c.TonApiURL); err != nil {
+		fail("TON_API_URL: %v", err)
+	}
+	if c.AppWallet != "" && !validAddress(c.AppWallet) {
+		fail("APP_WALLET=%q: not a TON address", c.AppWallet)
+	}
+	for _, w := range c.WatchWallets {
+		if !validAddress(w) {
+			fail("WATCH_WALLETS: %q is not a TON address", w)
+		}
+	}
+
+	for _, d := range []struct {
+		key string
+		val time.Duration
+	}{
+		{"REQUEST_TIMEOUT", c.RequestTimeout}, {"WATCH_INTERVAL", c.WatchInterval},
+		{"TON_PROOF_TTL", c.TonProofTTL}, {"SESSION_TTL", c.SessionTTL},
+		{"RATES_CACHE_TTL", c.RatesCacheTTL}, {"INVOICE_TTL", c.InvoiceTTL},
+		{"SWEEP_INTERVAL", c.SweepInterval}, {"EXPORT_SYNC_MAX_RANGE", c.ExportSyncMaxRange},
+		{"EXPORT_JOB_TTL", c.ExportJobTTL},
+		{"IDEMPOTENCY_TTL", c.IdempotencyTTL},
+		{"SERVER_READ_TIMEOUT", c.ReadTimeout}, {"SERVER_READ_HEADER_TIMEOUT", c.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.WriteTimeout}, {"SERVER_IDLE_TIMEOUT", c.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout}, {"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+	} {
+		if d.val <= 0 {
+			fail("%s=%s: must be positive", d.key, d.val)
+		}
+	}
+	if c.ReconcileInterval < 0 {
+		fail("RECONCILE_INTERVAL=%s: must be 0 (off) or positive", c.ReconcileInterval)
+	}
+	if c.WatchMaxLag < 0 {
+		fail("WATCH_MAX_LAG=%s: must be 0 (off) or positive", c.WatchMaxLag)
+	}
+	if c.ConfigWatchInterval < 0 {
+		fail("CONFIG_WATCH_INTERVAL=%s: must be 0 (SIGHUP only) or positive", c.ConfigWatchInterval)
+	}
+	if c.MaxRetries < 0 {
+		fail("MAX_RETRIES=%d: must not be negative", c.MaxRetries)
+	}
+	if c.MinConfirmations < 0 {
+		fail("MIN_CONFIRMATIONS=%d: must not be negative", c.MinConfirmations)
+	}
+	if c.WSSendBuffer <= 0 {
+		fail("WS_SEND_BUFFER=%d: must be positive", c.WSSendBuffer)
+	}
+	if c.MaxHeaderBytes <= 0 {
+		fail("SERVER_MAX_HEADER_BYTES=%d: must be positive", c.MaxHeaderBytes)
+	}
+	if c.RateSlippagePct < 0 || c.RateSlippagePct >= 100 {
+		fail("RATE_SLIPPAGE_PCT=%g: must be in [0, 100)", c.RateSlippagePct)
+	}
+	if c.SessionSecret != "" && len(c.TonProofDomains) == 0 {
+		fail("TON_PROOF_DOMAINS is required when ton_proof is enabled (SESSION_SECRET is set)")
+	}
+	if c.ExportDir == "" {
+		fail("EXPORT_DIR is required")
+	}
+
+	// требования включённых функций
+	switch c.RatesProvider {
+	case "tonapi":
+	case "file":
+		if c.RatesFile == "" {
+			fail("RATES_FILE is required when RATES_PROVIDER=file")
+		}
+	default:
+		fail("RATES_PROVIDER=%q: use tonapi or file", c.RatesProvider)
+	}
+	if c.DepositMasterSeed != "" {
+		if b, err := hex.DecodeString(strings.TrimSpace(c.DepositMasterSeed)); err != nil || len(b) != 32 {
+			fail("DEPOSIT_MASTER_SEED: must be a 32-byte ed25519 seed in hex")
+		}
+		switch strings.ToLower(c.DepositWalletVersion) {
+		case "v4", "v4r2", "v5", "v5r1":
+		default:
+			fail("DEPOSIT_WALLET_VERSION=%q: use v4r2 or v5r1", c.DepositWalletVersion)
+		}
+		if err := checkURL(c.LiteServerConfigURL); err != nil {
+			fail("LITESERVER_CONFIG_URL (deposit mode): %v", err)
+		}
+		if c.SweepMinTon <= 0 {
+			fail("SWEEP_MIN_TON=%g: must be positive in deposit mode", c.SweepMinTon)
+		}
+	}
+	switch c.TracesExporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.OTLPEndpoint != "" {
+			if err := checkURL(c.OTLPEndpoint); err != nil {
+				fail("OTEL_EXPORTER_OTLP_ENDPOINT: %v", err)
+			}
+		}
+	default:
+		fail("OTEL_TRACES_EXPORTER=%q: use none, stdout or otlp", c.TracesExporter)
+	}
+	return errors.Join(errs...)
+}
+
+func validAddress(s string) bool {
+	if _, err := address.ParseAddr(s); err == nil {
+		return true
+	}
+	_, err := address.ParseRawAddr(s)
+	return err == nil
+}
+
+func checkURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", s)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"payment-service/middleware"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type principalKey struct{}
+
+// requestToken — ключ из метаданных "authorization: Bearer ..." или "x-api-key", как middleware.RequestToken.
+func requestToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, h := range md.Get("authorization") {
+		if strings.HasPrefix(h, "Bearer ") {
+			return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+		}
+	}
+	if k := md.Get("x-api-key"); len(k) > 0 {
+		return k[0]
+	}
+	return ""
+}
+
+// Интерцепторы кладут Principal в контекст; сами вызов не отклоняют (как Authenticator.Middleware).
+func (s *service) unaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(context.WithValue(ctx, principalKey{}, s.auth.Resolve(requestToken(ctx))), req)
+}
+
+func (s *service) streamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := context.WithValue(ss.Context(), principalKey{}, s.auth.Resolve(requestToken(ss.Context())))
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
+
+// allowWallet — как middleware.AllowWallet: UNAUTHENTICATED для анонимных, PERMISSION_DENIED для чужого кошелька.
+func (s *service) allowWallet(ctx context.Context, wallet string) error {
+	p, _ := ctx.Value(principalKey{}).(middleware.Principal)
+	if s.auth.Allow(p, wallet) {
+		return nil
+	}
+	if p.Kind == middleware.PrincipalAnonymous {
+		return status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	return status.Error(codes.PermissionDenied, "Access to wallet "+wallet+" is not allowed")
+}
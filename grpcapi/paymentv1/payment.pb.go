@@ -0,0 +1,1618 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: payment/v1/payment.proto
+
+package paymentv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WaitPaymentUpdate_Status int32
+
+const (
+	WaitPaymentUpdate_STATUS_UNSPECIFIED WaitPaymentUpdate_Status = 0
+	WaitPaymentUpdate_STATUS_PENDING     WaitPaymentUpdate_Status = 1 // платежа пока нет, опрос продолжается
+	WaitPaymentUpdate_STATUS_PAID        WaitPaymentUpdate_Status = 2
+	WaitPaymentUpdate_STATUS_TIMEOUT     WaitPaymentUpdate_Status = 3
+)
+
+// Enum value maps for WaitPaymentUpdate_Status.
+var (
+	WaitPaymentUpdate_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_PENDING",
+		2: "STATUS_PAID",
+		3: "STATUS_TIMEOUT",
+	}
+	WaitPaymentUpdate_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_PENDING":     1,
+		"STATUS_PAID":        2,
+		"STATUS_TIMEOUT":     3,
+	}
+)
+
+func (x WaitPaymentUpdate_Status) Enum() *WaitPaymentUpdate_Status {
+	p := new(WaitPaymentUpdate_Status)
+	*p = x
+	return p
+}
+
+func (x WaitPaymentUpdate_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WaitPaymentUpdate_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_v1_payment_proto_enumTypes[0].Descriptor()
+}
+
+func (WaitPaymentUpdate_Status) Type() protoreflect.EnumType {
+	return &file_payment_v1_payment_proto_enumTypes[0]
+}
+
+func (x WaitPaymentUpdate_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WaitPaymentUpdate_Status.Descriptor instead.
+func (WaitPaymentUpdate_Status) EnumDescriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{3, 0}
+}
+
+type CheckPaymentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MerchantAddress string                 `protobuf:"bytes,1,opt,name=merchant_address,json=merchantAddress,proto3" json:"merchant_address,omitempty"`
+	Comment         string                 `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	MinAmountTon    string                 `protobuf:"bytes,3,opt,name=min_amount_ton,json=minAmountTon,proto3" json:"min_amount_ton,omitempty"`
+	Limit           int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"` // событий для просмотра (0-200), по умолчанию 50
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CheckPaymentRequest) Reset() {
+	*x = CheckPaymentRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPaymentRequest) ProtoMessage() {}
+
+func (x *CheckPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPaymentRequest.ProtoReflect.Descriptor instead.
+func (*CheckPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckPaymentRequest) GetMerchantAddress() string {
+	if x != nil {
+		return x.MerchantAddress
+	}
+	return ""
+}
+
+func (x *CheckPaymentRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *CheckPaymentRequest) GetMinAmountTon() string {
+	if x != nil {
+		return x.MinAmountTon
+	}
+	return ""
+}
+
+func (x *CheckPaymentRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type CheckPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Paid          bool                   `protobuf:"varint,1,opt,name=paid,proto3" json:"paid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckPaymentResponse) Reset() {
+	*x = CheckPaymentResponse{}
+	mi := &file_payment_v1_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPaymentResponse) ProtoMessage() {}
+
+func (x *CheckPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPaymentResponse.ProtoReflect.Descriptor instead.
+func (*CheckPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckPaymentResponse) GetPaid() bool {
+	if x != nil {
+		return x.Paid
+	}
+	return false
+}
+
+type WaitPaymentRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Payment         *CheckPaymentRequest   `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	TimeoutSec      int32                  `protobuf:"varint,2,opt,name=timeout_sec,json=timeoutSec,proto3" json:"timeout_sec,omitempty"`                  // по умолчанию 30
+	PollIntervalSec int32                  `protobuf:"varint,3,opt,name=poll_interval_sec,json=pollIntervalSec,proto3" json:"poll_interval_sec,omitempty"` // по умолчанию 3
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WaitPaymentRequest) Reset() {
+	*x = WaitPaymentRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitPaymentRequest) ProtoMessage() {}
+
+func (x *WaitPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitPaymentRequest.ProtoReflect.Descriptor instead.
+func (*WaitPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WaitPaymentRequest) GetPayment() *CheckPaymentRequest {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *WaitPaymentRequest) GetTimeoutSec() int32 {
+	if x != nil {
+		return x.TimeoutSec
+	}
+	return 0
+}
+
+func (x *WaitPaymentRequest) GetPollIntervalSec() int32 {
+	if x != nil {
+		return x.PollIntervalSec
+	}
+	return 0
+}
+
+type WaitPaymentUpdate struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Status        WaitPaymentUpdate_Status `protobuf:"varint,1,opt,name=status,proto3,enum=payment.v1.WaitPaymentUpdate_Status" json:"status,omitempty"`
+	Attempt       int32                    `protobuf:"varint,2,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	CheckedAt     *timestamppb.Timestamp   `protobuf:"bytes,3,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitPaymentUpdate) Reset() {
+	*x = WaitPaymentUpdate{}
+	mi := &file_payment_v1_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitPaymentUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitPaymentUpdate) ProtoMessage() {}
+
+func (x *WaitPaymentUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitPaymentUpdate.ProtoReflect.Descriptor instead.
+func (*WaitPaymentUpdate) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WaitPaymentUpdate) GetStatus() WaitPaymentUpdate_Status {
+	if x != nil {
+		return x.Status
+	}
+	return WaitPaymentUpdate_STATUS_UNSPECIFIED
+}
+
+func (x *WaitPaymentUpdate) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *WaitPaymentUpdate) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+type ValidateTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletAddress string                 `protobuf:"bytes,1,opt,name=wallet_address,json=walletAddress,proto3" json:"wallet_address,omitempty"`
+	TxHash        string                 `protobuf:"bytes,2,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	// Ожидания сверяются, только если заданы.
+	SenderAddress string `protobuf:"bytes,3,opt,name=sender_address,json=senderAddress,proto3" json:"sender_address,omitempty"`
+	Amount        string `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`     // в единицах currency
+	Currency      string `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"` // "TON" или символ/адрес джеттона
+	Comment       string `protobuf:"bytes,6,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateTransactionRequest) Reset() {
+	*x = ValidateTransactionRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTransactionRequest) ProtoMessage() {}
+
+func (x *ValidateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateTransactionRequest) GetWalletAddress() string {
+	if x != nil {
+		return x.WalletAddress
+	}
+	return ""
+}
+
+func (x *ValidateTransactionRequest) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *ValidateTransactionRequest) GetSenderAddress() string {
+	if x != nil {
+		return x.SenderAddress
+	}
+	return ""
+}
+
+func (x *ValidateTransactionRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *ValidateTransactionRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ValidateTransactionRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type TransactionValidation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // ok | failed | bounced; пусто — событие не найдено
+	Transfer      *Transaction           `protobuf:"bytes,3,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	Mismatches    []*FieldMismatch       `protobuf:"bytes,4,rep,name=mismatches,proto3" json:"mismatches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionValidation) Reset() {
+	*x = TransactionValidation{}
+	mi := &file_payment_v1_payment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionValidation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionValidation) ProtoMessage() {}
+
+func (x *TransactionValidation) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionValidation.ProtoReflect.Descriptor instead.
+func (*TransactionValidation) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TransactionValidation) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *TransactionValidation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransactionValidation) GetTransfer() *Transaction {
+	if x != nil {
+		return x.Transfer
+	}
+	return nil
+}
+
+func (x *TransactionValidation) GetMismatches() []*FieldMismatch {
+	if x != nil {
+		return x.Mismatches
+	}
+	return nil
+}
+
+type FieldMismatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Expected      string                 `protobuf:"bytes,2,opt,name=expected,proto3" json:"expected,omitempty"`
+	Actual        string                 `protobuf:"bytes,3,opt,name=actual,proto3" json:"actual,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldMismatch) Reset() {
+	*x = FieldMismatch{}
+	mi := &file_payment_v1_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldMismatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldMismatch) ProtoMessage() {}
+
+func (x *FieldMismatch) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldMismatch.ProtoReflect.Descriptor instead.
+func (*FieldMismatch) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FieldMismatch) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldMismatch) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *FieldMismatch) GetActual() string {
+	if x != nil {
+		return x.Actual
+	}
+	return ""
+}
+
+type Transaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Amount        string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Comment       string                 `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	Currency      string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	Direction     string                 `protobuf:"bytes,9,opt,name=direction,proto3" json:"direction,omitempty"` // in | out относительно запрошенного аккаунта
+	Kind          string                 `protobuf:"bytes,10,opt,name=kind,proto3" json:"kind,omitempty"`
+	Details       *TransactionDetails    `protobuf:"bytes,11,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_payment_v1_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Transaction) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Transaction) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Transaction) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Transaction) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Transaction) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Transaction) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Transaction) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Transaction) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *Transaction) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Transaction) GetDetails() *TransactionDetails {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type TransactionDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jetton        *Jetton                `protobuf:"bytes,1,opt,name=jetton,proto3" json:"jetton,omitempty"`
+	Nft           string                 `protobuf:"bytes,2,opt,name=nft,proto3" json:"nft,omitempty"`
+	Dex           string                 `protobuf:"bytes,3,opt,name=dex,proto3" json:"dex,omitempty"`
+	AssetIn       string                 `protobuf:"bytes,4,opt,name=asset_in,json=assetIn,proto3" json:"asset_in,omitempty"`
+	AmountIn      string                 `protobuf:"bytes,5,opt,name=amount_in,json=amountIn,proto3" json:"amount_in,omitempty"`
+	AssetOut      string                 `protobuf:"bytes,6,opt,name=asset_out,json=assetOut,proto3" json:"asset_out,omitempty"`
+	AmountOut     string                 `protobuf:"bytes,7,opt,name=amount_out,json=amountOut,proto3" json:"amount_out,omitempty"`
+	Contract      string                 `protobuf:"bytes,8,opt,name=contract,proto3" json:"contract,omitempty"`
+	Interfaces    []string               `protobuf:"bytes,9,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	Operation     string                 `protobuf:"bytes,10,opt,name=operation,proto3" json:"operation,omitempty"`
+	ActionType    string                 `protobuf:"bytes,11,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"`
+	Description   string                 `protobuf:"bytes,12,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionDetails) Reset() {
+	*x = TransactionDetails{}
+	mi := &file_payment_v1_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionDetails) ProtoMessage() {}
+
+func (x *TransactionDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionDetails.ProtoReflect.Descriptor instead.
+func (*TransactionDetails) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TransactionDetails) GetJetton() *Jetton {
+	if x != nil {
+		return x.Jetton
+	}
+	return nil
+}
+
+func (x *TransactionDetails) GetNft() string {
+	if x != nil {
+		return x.Nft
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetDex() string {
+	if x != nil {
+		return x.Dex
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetAssetIn() string {
+	if x != nil {
+		return x.AssetIn
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetAmountIn() string {
+	if x != nil {
+		return x.AmountIn
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetAssetOut() string {
+	if x != nil {
+		return x.AssetOut
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetAmountOut() string {
+	if x != nil {
+		return x.AmountOut
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetContract() string {
+	if x != nil {
+		return x.Contract
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetInterfaces() []string {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+func (x *TransactionDetails) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetActionType() string {
+	if x != nil {
+		return x.ActionType
+	}
+	return ""
+}
+
+func (x *TransactionDetails) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type Jetton struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Decimals      int32                  `protobuf:"varint,4,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Jetton) Reset() {
+	*x = Jetton{}
+	mi := &file_payment_v1_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Jetton) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Jetton) ProtoMessage() {}
+
+func (x *Jetton) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Jetton.ProtoReflect.Descriptor instead.
+func (*Jetton) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Jetton) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Jetton) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Jetton) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Jetton) GetDecimals() int32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+type GetAccountInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountInfoRequest) Reset() {
+	*x = GetAccountInfoRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountInfoRequest) ProtoMessage() {}
+
+func (x *GetAccountInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountInfoRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetAccountInfoRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+type AccountInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Balance       string                 `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	LastActivity  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	Jettons       []*JettonBalance       `protobuf:"bytes,5,rep,name=jettons,proto3" json:"jettons,omitempty"`
+	Nfts          []*NFTItem             `protobuf:"bytes,6,rep,name=nfts,proto3" json:"nfts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountInfo) Reset() {
+	*x = AccountInfo{}
+	mi := &file_payment_v1_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountInfo) ProtoMessage() {}
+
+func (x *AccountInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountInfo.ProtoReflect.Descriptor instead.
+func (*AccountInfo) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AccountInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AccountInfo) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *AccountInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AccountInfo) GetLastActivity() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivity
+	}
+	return nil
+}
+
+func (x *AccountInfo) GetJettons() []*JettonBalance {
+	if x != nil {
+		return x.Jettons
+	}
+	return nil
+}
+
+func (x *AccountInfo) GetNfts() []*NFTItem {
+	if x != nil {
+		return x.Nfts
+	}
+	return nil
+}
+
+type JettonBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Balance       string                 `protobuf:"bytes,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JettonBalance) Reset() {
+	*x = JettonBalance{}
+	mi := &file_payment_v1_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JettonBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JettonBalance) ProtoMessage() {}
+
+func (x *JettonBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JettonBalance.ProtoReflect.Descriptor instead.
+func (*JettonBalance) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *JettonBalance) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *JettonBalance) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *JettonBalance) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *JettonBalance) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type NFTItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Image         string                 `protobuf:"bytes,4,opt,name=image,proto3" json:"image,omitempty"`
+	MetadataJson  string                 `protobuf:"bytes,5,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NFTItem) Reset() {
+	*x = NFTItem{}
+	mi := &file_payment_v1_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NFTItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NFTItem) ProtoMessage() {}
+
+func (x *NFTItem) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NFTItem.ProtoReflect.Descriptor instead.
+func (*NFTItem) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *NFTItem) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *NFTItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NFTItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *NFTItem) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *NFTItem) GetMetadataJson() string {
+	if x != nil {
+		return x.MetadataJson
+	}
+	return ""
+}
+
+// Фильтры как у GET /api/transactions/{account}.
+type GetTransactionHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // 1-100, по умолчанию 10
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Direction     string                 `protobuf:"bytes,5,opt,name=direction,proto3" json:"direction,omitempty"`
+	MinAmount     string                 `protobuf:"bytes,6,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	MaxAmount     string                 `protobuf:"bytes,7,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"`
+	Counterparty  string                 `protobuf:"bytes,8,opt,name=counterparty,proto3" json:"counterparty,omitempty"`
+	Comment       string                 `protobuf:"bytes,9,opt,name=comment,proto3" json:"comment,omitempty"`
+	CommentMatch  string                 `protobuf:"bytes,10,opt,name=comment_match,json=commentMatch,proto3" json:"comment_match,omitempty"`
+	Types         []string               `protobuf:"bytes,11,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionHistoryRequest) Reset() {
+	*x = GetTransactionHistoryRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionHistoryRequest) ProtoMessage() {}
+
+func (x *GetTransactionHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTransactionHistoryRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetTransactionHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetTransactionHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetTransactionHistoryRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetMinAmount() string {
+	if x != nil {
+		return x.MinAmount
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetMaxAmount() string {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetCounterparty() string {
+	if x != nil {
+		return x.Counterparty
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetCommentMatch() string {
+	if x != nil {
+		return x.CommentMatch
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type GetTransactionHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionHistoryResponse) Reset() {
+	*x = GetTransactionHistoryResponse{}
+	mi := &file_payment_v1_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionHistoryResponse) ProtoMessage() {}
+
+func (x *GetTransactionHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetTransactionHistoryResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_payment_v1_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetBalanceRequest) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+type Balance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Balance       string                 `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Unit          string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Balance) Reset() {
+	*x = Balance{}
+	mi := &file_payment_v1_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Balance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Balance) ProtoMessage() {}
+
+func (x *Balance) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_v1_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Balance.ProtoReflect.Descriptor instead.
+func (*Balance) Descriptor() ([]byte, []int) {
+	return file_payment_v1_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Balance) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Balance) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *Balance) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+var File_payment_v1_payment_proto protoreflect.FileDescriptor
+
+const file_payment_v1_payment_proto_rawDesc = "" +
+	"\n" +
+	"\x18payment/v1/payment.proto\x12\n" +
+	"payment.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x96\x01\n" +
+	"\x13CheckPaymentRequest\x12)\n" +
+	"\x10merchant_address\x18\x01 \x01(\tR\x0fmerchantAddress\x12\x18\n" +
+	"\acomment\x18\x02 \x01(\tR\acomment\x12$\n" +
+	"\x0emin_amount_ton\x18\x03 \x01(\tR\fminAmountTon\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"*\n" +
+	"\x14CheckPaymentResponse\x12\x12\n" +
+	"\x04paid\x18\x01 \x01(\bR\x04paid\"\x9c\x01\n" +
+	"\x12WaitPaymentRequest\x129\n" +
+	"\apayment\x18\x01 \x01(\v2\x1f.payment.v1.CheckPaymentRequestR\apayment\x12\x1f\n" +
+	"\vtimeout_sec\x18\x02 \x01(\x05R\n" +
+	"timeoutSec\x12*\n" +
+	"\x11poll_interval_sec\x18\x03 \x01(\x05R\x0fpollIntervalSec\"\x81\x02\n" +
+	"\x11WaitPaymentUpdate\x12<\n" +
+	"\x06status\x18\x01 \x01(\x0e2$.payment.v1.WaitPaymentUpdate.StatusR\x06status\x12\x18\n" +
+	"\aattempt\x18\x02 \x01(\x05R\aattempt\x129\n" +
+	"\n" +
+	"checked_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"Y\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSTATUS_PENDING\x10\x01\x12\x0f\n" +
+	"\vSTATUS_PAID\x10\x02\x12\x12\n" +
+	"\x0eSTATUS_TIMEOUT\x10\x03\"\xd1\x01\n" +
+	"\x1aValidateTransactionRequest\x12%\n" +
+	"\x0ewallet_address\x18\x01 \x01(\tR\rwalletAddress\x12\x17\n" +
+	"\atx_hash\x18\x02 \x01(\tR\x06txHash\x12%\n" +
+	"\x0esender_address\x18\x03 \x01(\tR\rsenderAddress\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x18\n" +
+	"\acomment\x18\x06 \x01(\tR\acomment\"\xb5\x01\n" +
+	"\x15TransactionValidation\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x123\n" +
+	"\btransfer\x18\x03 \x01(\v2\x17.payment.v1.TransactionR\btransfer\x129\n" +
+	"\n" +
+	"mismatches\x18\x04 \x03(\v2\x19.payment.v1.FieldMismatchR\n" +
+	"mismatches\"Y\n" +
+	"\rFieldMismatch\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1a\n" +
+	"\bexpected\x18\x02 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\x03 \x01(\tR\x06actual\"\xd1\x02\n" +
+	"\vTransaction\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\acomment\x18\a \x01(\tR\acomment\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x1c\n" +
+	"\tdirection\x18\t \x01(\tR\tdirection\x12\x12\n" +
+	"\x04kind\x18\n" +
+	" \x01(\tR\x04kind\x128\n" +
+	"\adetails\x18\v \x01(\v2\x1e.payment.v1.TransactionDetailsR\adetails\"\xf5\x02\n" +
+	"\x12TransactionDetails\x12*\n" +
+	"\x06jetton\x18\x01 \x01(\v2\x12.payment.v1.JettonR\x06jetton\x12\x10\n" +
+	"\x03nft\x18\x02 \x01(\tR\x03nft\x12\x10\n" +
+	"\x03dex\x18\x03 \x01(\tR\x03dex\x12\x19\n" +
+	"\basset_in\x18\x04 \x01(\tR\aassetIn\x12\x1b\n" +
+	"\tamount_in\x18\x05 \x01(\tR\bamountIn\x12\x1b\n" +
+	"\tasset_out\x18\x06 \x01(\tR\bassetOut\x12\x1d\n" +
+	"\n" +
+	"amount_out\x18\a \x01(\tR\tamountOut\x12\x1a\n" +
+	"\bcontract\x18\b \x01(\tR\bcontract\x12\x1e\n" +
+	"\n" +
+	"interfaces\x18\t \x03(\tR\n" +
+	"interfaces\x12\x1c\n" +
+	"\toperation\x18\n" +
+	" \x01(\tR\toperation\x12\x1f\n" +
+	"\vaction_type\x18\v \x01(\tR\n" +
+	"actionType\x12 \n" +
+	"\vdescription\x18\f \x01(\tR\vdescription\"j\n" +
+	"\x06Jetton\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1a\n" +
+	"\bdecimals\x18\x04 \x01(\x05R\bdecimals\"1\n" +
+	"\x15GetAccountInfoRequest\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\"\xf8\x01\n" +
+	"\vAccountInfo\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\tR\abalance\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12?\n" +
+	"\rlast_activity\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\flastActivity\x123\n" +
+	"\ajettons\x18\x05 \x03(\v2\x19.payment.v1.JettonBalanceR\ajettons\x12'\n" +
+	"\x04nfts\x18\x06 \x03(\v2\x13.payment.v1.NFTItemR\x04nfts\"o\n" +
+	"\rJettonBalance\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x18\n" +
+	"\abalance\x18\x03 \x01(\tR\abalance\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\"\x94\x01\n" +
+	"\aNFTItem\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05image\x18\x04 \x01(\tR\x05image\x12#\n" +
+	"\rmetadata_json\x18\x05 \x01(\tR\fmetadataJson\"\xff\x02\n" +
+	"\x1cGetTransactionHistoryRequest\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1c\n" +
+	"\tdirection\x18\x05 \x01(\tR\tdirection\x12\x1d\n" +
+	"\n" +
+	"min_amount\x18\x06 \x01(\tR\tminAmount\x12\x1d\n" +
+	"\n" +
+	"max_amount\x18\a \x01(\tR\tmaxAmount\x12\"\n" +
+	"\fcounterparty\x18\b \x01(\tR\fcounterparty\x12\x18\n" +
+	"\acomment\x18\t \x01(\tR\acomment\x12#\n" +
+	"\rcomment_match\x18\n" +
+	" \x01(\tR\fcommentMatch\x12\x14\n" +
+	"\x05types\x18\v \x03(\tR\x05types\"\\\n" +
+	"\x1dGetTransactionHistoryResponse\x12;\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x17.payment.v1.TransactionR\ftransactions\"-\n" +
+	"\x11GetBalanceRequest\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\"Q\n" +
+	"\aBalance\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\tR\abalance\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit2\x93\x04\n" +
+	"\x0ePaymentService\x12Q\n" +
+	"\fCheckPayment\x12\x1f.payment.v1.CheckPaymentRequest\x1a .payment.v1.CheckPaymentResponse\x12N\n" +
+	"\vWaitPayment\x12\x1e.payment.v1.WaitPaymentRequest\x1a\x1d.payment.v1.WaitPaymentUpdate0\x01\x12`\n" +
+	"\x13ValidateTransaction\x12&.payment.v1.ValidateTransactionRequest\x1a!.payment.v1.TransactionValidation\x12L\n" +
+	"\x0eGetAccountInfo\x12!.payment.v1.GetAccountInfoRequest\x1a\x17.payment.v1.AccountInfo\x12l\n" +
+	"\x15GetTransactionHistory\x12(.payment.v1.GetTransactionHistoryRequest\x1a).payment.v1.GetTransactionHistoryResponse\x12@\n" +
+	"\n" +
+	"GetBalance\x12\x1d.payment.v1.GetBalanceRequest\x1a\x13.payment.v1.BalanceB-Z+payment-service/grpcapi/paymentv1;paymentv1b\x06proto3"
+
+var (
+	file_payment_v1_payment_proto_rawDescOnce sync.Once
+	file_payment_v1_payment_proto_rawDescData []byte
+)
+
+func file_payment_v1_payment_proto_rawDescGZIP() []byte {
+	file_payment_v1_payment_proto_rawDescOnce.Do(func() {
+		file_payment_v1_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_payment_v1_payment_proto_rawDesc), len(file_payment_v1_payment_proto_rawDesc)))
+	})
+	return file_payment_v1_payment_proto_rawDescData
+}
+
+var file_payment_v1_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_payment_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_payment_v1_payment_proto_goTypes = []any{
+	(WaitPaymentUpdate_Status)(0),         // 0: payment.v1.WaitPaymentUpdate.Status
+	(*CheckPaymentRequest)(nil),           // 1: payment.v1.CheckPaymentRequest
+	(*CheckPaymentResponse)(nil),          // 2: payment.v1.CheckPaymentResponse
+	(*WaitPaymentRequest)(nil),            // 3: payment.v1.WaitPaymentRequest
+	(*WaitPaymentUpdate)(nil),             // 4: payment.v1.WaitPaymentUpdate
+	(*ValidateTransactionRequest)(nil),    // 5: payment.v1.ValidateTransactionRequest
+	(*TransactionValidation)(nil),         // 6: payment.v1.TransactionValidation
+	(*FieldMismatch)(nil),                 // 7: payment.v1.FieldMismatch
+	(*Transaction)(nil),                   // 8: payment.v1.Transaction
+	(*TransactionDetails)(nil),            // 9: payment.v1.TransactionDetails
+	(*Jetton)(nil),                        // 10: payment.v1.Jetton
+	(*GetAccountInfoRequest)(nil),         // 11: payment.v1.GetAccountInfoRequest
+	(*AccountInfo)(nil),                   // 12: payment.v1.AccountInfo
+	(*JettonBalance)(nil),                 // 13: payment.v1.JettonBalance
+	(*NFTItem)(nil),                       // 14: payment.v1.NFTItem
+	(*GetTransactionHistoryRequest)(nil),  // 15: payment.v1.GetTransactionHistoryRequest
+	(*GetTransactionHistoryResponse)(nil), // 16: payment.v1.GetTransactionHistoryResponse
+	(*GetBalanceRequest)(nil),             // 17: payment.v1.GetBalanceRequest
+	(*Balance)(nil),                       // 18: payment.v1.Balance
+	(*timestamppb.Timestamp)(nil),         // 19: google.protobuf.Timestamp
+}
+var file_payment_v1_payment_proto_depIdxs = []int32{
+	1,  // 0: payment.v1.WaitPaymentRequest.payment:type_name -> payment.v1.CheckPaymentRequest
+	0,  // 1: payment.v1.WaitPaymentUpdate.status:type_name -> payment.v1.WaitPaymentUpdate.Status
+	19, // 2: payment.v1.WaitPaymentUpdate.checked_at:type_name -> google.protobuf.Timestamp
+	8,  // 3: payment.v1.TransactionValidation.transfer:type_name -> payment.v1.Transaction
+	7,  // 4: payment.v1.TransactionValidation.mismatches:type_name -> payment.v1.FieldMismatch
+	19, // 5: payment.v1.Transaction.timestamp:type_name -> google.protobuf.Timestamp
+	9,  // 6: payment.v1.Transaction.details:type_name -> payment.v1.TransactionDetails
+	10, // 7: payment.v1.TransactionDetails.jetton:type_name -> payment.v1.Jetton
+	19, // 8: payment.v1.AccountInfo.last_activity:type_name -> google.protobuf.Timestamp
+	13, // 9: payment.v1.AccountInfo.jettons:type_name -> payment.v1.JettonBalance
+	14, // 10: payment.v1.AccountInfo.nfts:type_name -> payment.v1.NFTItem
+	19, // 11: payment.v1.GetTransactionHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	19, // 12: payment.v1.GetTransactionHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	8,  // 13: payment.v1.GetTransactionHistoryResponse.transactions:type_name -> payment.v1.Transaction
+	1,  // 14: payment.v1.PaymentService.CheckPayment:input_type -> payment.v1.CheckPaymentRequest
+	3,  // 15: payment.v1.PaymentService.WaitPayment:input_type -> payment.v1.WaitPaymentRequest
+	5,  // 16: payment.v1.PaymentService.ValidateTransaction:input_type -> payment.v1.ValidateTransactionRequest
+	11, // 17: payment.v1.PaymentService.GetAccountInfo:input_type -> payment.v1.GetAccountInfoRequest
+	15, // 18: payment.v1.PaymentService.GetTransactionHistory:input_type -> payment.v1.GetTransactionHistoryRequest
+	17, // 19: payment.v1.PaymentService.GetBalance:input_type -> payment.v1.GetBalanceRequest
+	2,  // 20: payment.v1.PaymentService.CheckPayment:output_type -> payment.v1.CheckPaymentResponse
+	4,  // 21: payment.v1.PaymentService.WaitPayment:output_type -> payment.v1.WaitPaymentUpdate
+	6,  // 22: payment.v1.PaymentService.ValidateTransaction:output_type -> payment.v1.TransactionValidation
+	12, // 23: payment.v1.PaymentService.GetAccountInfo:output_type -> payment.v1.AccountInfo
+	16, // 24: payment.v1.PaymentService.GetTransactionHistory:output_type -> payment.v1.GetTransactionHistoryResponse
+	18, // 25: payment.v1.PaymentService.GetBalance:output_type -> payment.v1.Balance
+	20, // [20:26] is the sub-list for method output_type
+	14, // [14:20] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_payment_v1_payment_proto_init() }
+func file_payment_v1_payment_proto_init() {
+	if File_payment_v1_payment_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payment_v1_payment_proto_rawDesc), len(file_payment_v1_payment_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_payment_v1_payment_proto_goTypes,
+		DependencyIndexes: file_payment_v1_payment_proto_depIdxs,
+		EnumInfos:         file_payment_v1_payment_proto_enumTypes,
+		MessageInfos:      file_payment_v1_payment_proto_msgTypes,
+	}.Build()
+	File_payment_v1_payment_proto = out.File
+	file_payment_v1_payment_proto_goTypes = nil
+	file_payment_v1_payment_proto_depIdxs = nil
+}
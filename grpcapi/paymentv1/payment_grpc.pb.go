@@ -0,0 +1,333 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: payment/v1/payment.proto
+
+package paymentv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PaymentService_CheckPayment_FullMethodName          = "/payment.v1.PaymentService/CheckPayment"
+	PaymentService_WaitPayment_FullMethodName           = "/payment.v1.PaymentService/WaitPayment"
+	PaymentService_ValidateTransaction_FullMethodName   = "/payment.v1.PaymentService/ValidateTransaction"
+	PaymentService_GetAccountInfo_FullMethodName        = "/payment.v1.PaymentService/GetAccountInfo"
+	PaymentService_GetTransactionHistory_FullMethodName = "/payment.v1.PaymentService/GetTransactionHistory"
+	PaymentService_GetBalance_FullMethodName            = "/payment.v1.PaymentService/GetBalance"
+)
+
+// PaymentServiceClient is the client API for PaymentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Те же операции, что и REST /api/check-payment, /api/validate-payment, /api/account-info,
+// /api/transactions и /api/balance; суммы — строки в TON ("1.500000000"), как в JSON-ответах.
+// Аутентификация — метаданные "authorization: Bearer <token>" или "x-api-key: <token>"
+// (ключ клиента, мерчанта или сессия ton_proof). Коды ошибок соответствуют HTTP:
+// 400 — INVALID_ARGUMENT, 401 — UNAUTHENTICATED, 403 — PERMISSION_DENIED, 500 — INTERNAL.
+type PaymentServiceClient interface {
+	// Есть ли на кошельке входящий перевод с комментарием на сумму не меньше заданной.
+	CheckPayment(ctx context.Context, in *CheckPaymentRequest, opts ...grpc.CallOption) (*CheckPaymentResponse, error)
+	// Опрашивает кошелёк до оплаты или таймаута; сообщение на каждую проверку.
+	WaitPayment(ctx context.Context, in *WaitPaymentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WaitPaymentUpdate], error)
+	// Сверка транзакции с ожидаемыми отправителем, суммой, валютой и комментарием.
+	ValidateTransaction(ctx context.Context, in *ValidateTransactionRequest, opts ...grpc.CallOption) (*TransactionValidation, error)
+	GetAccountInfo(ctx context.Context, in *GetAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error)
+	GetTransactionHistory(ctx context.Context, in *GetTransactionHistoryRequest, opts ...grpc.CallOption) (*GetTransactionHistoryResponse, error)
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error)
+}
+
+type paymentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPaymentServiceClient(cc grpc.ClientConnInterface) PaymentServiceClient {
+	return &paymentServiceClient{cc}
+}
+
+func (c *paymentServiceClient) CheckPayment(ctx context.Context, in *CheckPaymentRequest, opts ...grpc.CallOption) (*CheckPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckPaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_CheckPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) WaitPayment(ctx context.Context, in *WaitPaymentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WaitPaymentUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PaymentService_ServiceDesc.Streams[0], PaymentService_WaitPayment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WaitPaymentRequest, WaitPaymentUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_WaitPaymentClient = grpc.ServerStreamingClient[WaitPaymentUpdate]
+
+func (c *paymentServiceClient) ValidateTransaction(ctx context.Context, in *ValidateTransactionRequest, opts ...grpc.CallOption) (*TransactionValidation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionValidation)
+	err := c.cc.Invoke(ctx, PaymentService_ValidateTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetAccountInfo(ctx context.Context, in *GetAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountInfo)
+	err := c.cc.Invoke(ctx, PaymentService_GetAccountInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetTransactionHistory(ctx context.Context, in *GetTransactionHistoryRequest, opts ...grpc.CallOption) (*GetTransactionHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTransactionHistoryResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, PaymentService_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PaymentServiceServer is the server API for PaymentService service.
+// All implementations must embed UnimplementedPaymentServiceServer
+// for forward compatibility.
+//
+// Те же операции, что и REST /api/check-payment, /api/validate-payment, /api/account-info,
+// /api/transactions и /api/balance; суммы — строки в TON ("1.500000000"), как в JSON-ответах.
+// Аутентификация — метаданные "authorization: Bearer <token>" или "x-api-key: <token>"
+// (ключ клиента, мерчанта или сессия ton_proof). Коды ошибок соответствуют HTTP:
+// 400 — INVALID_ARGUMENT, 401 — UNAUTHENTICATED, 403 — PERMISSION_DENIED, 500 — INTERNAL.
+type PaymentServiceServer interface {
+	// Есть ли на кошельке входящий перевод с комментарием на сумму не меньше заданной.
+	CheckPayment(context.Context, *CheckPaymentRequest) (*CheckPaymentResponse, error)
+	// Опрашивает кошелёк до оплаты или таймаута; сообщение на каждую проверку.
+	WaitPayment(*WaitPaymentRequest, grpc.ServerStreamingServer[WaitPaymentUpdate]) error
+	// Сверка транзакции с ожидаемыми отправителем, суммой, валютой и комментарием.
+	ValidateTransaction(context.Context, *ValidateTransactionRequest) (*TransactionValidation, error)
+	GetAccountInfo(context.Context, *GetAccountInfoRequest) (*AccountInfo, error)
+	GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error)
+	GetBalance(context.Context, *GetBalanceRequest) (*Balance, error)
+	mustEmbedUnimplementedPaymentServiceServer()
+}
+
+// UnimplementedPaymentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPaymentServiceServer struct{}
+
+func (UnimplementedPaymentServiceServer) CheckPayment(context.Context, *CheckPaymentRequest) (*CheckPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) WaitPayment(*WaitPaymentRequest, grpc.ServerStreamingServer[WaitPaymentUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WaitPayment not implemented")
+}
+func (UnimplementedPaymentServiceServer) ValidateTransaction(context.Context, *ValidateTransactionRequest) (*TransactionValidation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateTransaction not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetAccountInfo(context.Context, *GetAccountInfoRequest) (*AccountInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountInfo not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionHistory not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
+func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafePaymentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PaymentServiceServer will
+// result in compilation errors.
+type UnsafePaymentServiceServer interface {
+	mustEmbedUnimplementedPaymentServiceServer()
+}
+
+func RegisterPaymentServiceServer(s grpc.ServiceRegistrar, srv PaymentServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPaymentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PaymentService_ServiceDesc, srv)
+}
+
+func _PaymentService_CheckPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).CheckPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_CheckPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).CheckPayment(ctx, req.(*CheckPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_WaitPayment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WaitPaymentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentServiceServer).WaitPayment(m, &grpc.GenericServerStream[WaitPaymentRequest, WaitPaymentUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PaymentService_WaitPaymentServer = grpc.ServerStreamingServer[WaitPaymentUpdate]
+
+func _PaymentService_ValidateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ValidateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ValidateTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ValidateTransaction(ctx, req.(*ValidateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetAccountInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetAccountInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetAccountInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetAccountInfo(ctx, req.(*GetAccountInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetTransactionHistory(ctx, req.(*GetTransactionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PaymentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "payment.v1.PaymentService",
+	HandlerType: (*PaymentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckPayment",
+			Handler:    _PaymentService_CheckPayment_Handler,
+		},
+		{
+			MethodName: "ValidateTransaction",
+			Handler:    _PaymentService_ValidateTransaction_Handler,
+		},
+		{
+			MethodName: "GetAccountInfo",
+			Handler:    _PaymentService_GetAccountInfo_Handler,
+		},
+		{
+			MethodName: "GetTransactionHistory",
+			Handler:    _PaymentService_GetTransactionHistory_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _PaymentService_GetBalance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WaitPayment",
+			Handler:       _PaymentService_WaitPayment_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "payment/v1/payment.proto",
+}
@@ -0,0 +1,285 @@
+// Package grpcapi — gRPC API (payment.v1.PaymentService) поверх TONService: те же правила
+// валидации запросов, аутентификация, доступ к кошелькам и коды ошибок, что и у REST.
+package grpcapi
+
+//go:generate sh -c "cd .. && buf generate"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"payment-service/config"
+	"payment-service/grpcapi/paymentv1"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Пределы WaitPayment: поток держит соединение, поэтому ожидание ограничено.
+const (
+	maxWaitTimeout  = 5 * time.Minute
+	maxPollInterval = time.Minute
+)
+
+type service struct {
+	paymentv1.UnimplementedPaymentServiceServer
+	ton    *services.TONService
+	auth   *middleware.Authenticator
+	config *config.Live
+}
+
+// NewServer — grpc.Server с PaymentService и интерцепторами аутентификации.
+func NewServer(ton *services.TONService, auth *middleware.Authenticator, cfg *config.Live, opts ...grpc.ServerOption) *grpc.Server {
+	s := &service{ton: ton, auth: auth, config: cfg}
+	srv := grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(s.unaryAuth), grpc.ChainStreamInterceptor(s.streamAuth))...)
+	paymentv1.RegisterPaymentServiceServer(srv, s)
+	return srv
+}
+
+// invalid — INVALID_ARGUMENT с тем же текстом, что и ответ 400 REST API.
+func invalid(err error) error {
+	return status.Error(codes.InvalidArgument, "Invalid request: "+err.Error())
+}
+
+func internal(prefix string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		return st.Err() // отмена вызова клиентом
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, prefix+err.Error())
+}
+
+func (s *service) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, s.config.Get().RequestTimeout)
+}
+
+func checkPaymentRequest(in *paymentv1.CheckPaymentRequest) (models.CheckPaymentRequest, error) {
+	req := models.CheckPaymentRequest{
+		MerchantAddress: in.GetMerchantAddress(),
+		Comment:         in.GetComment(),
+		MinAmountTon:    in.GetMinAmountTon(),
+		Limit:           int(in.GetLimit()),
+	}
+	return req, binding.Validator.ValidateStruct(&req)
+}
+
+func (s *service) CheckPayment(ctx context.Context, in *paymentv1.CheckPaymentRequest) (*paymentv1.CheckPaymentResponse, error) {
+	req, err := checkPaymentRequest(in)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if err := s.allowWallet(ctx, req.MerchantAddress); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	paid, err := s.ton.CheckPayment(ctx, req)
+	if err != nil {
+		return nil, internal("Error checking payment: ", err)
+	}
+	return &paymentv1.CheckPaymentResponse{Paid: paid}, nil
+}
+
+func (s *service) WaitPayment(in *paymentv1.WaitPaymentRequest, stream grpc.ServerStreamingServer[paymentv1.WaitPaymentUpdate]) error {
+	ctx := stream.Context()
+	req, err := checkPaymentRequest(in.GetPayment())
+	if err != nil {
+		return invalid(err)
+	}
+	timeout := time.Duration(in.GetTimeoutSec()) * time.Second
+	tick := time.Duration(in.GetPollIntervalSec()) * time.Second
+	if timeout < 0 || timeout > maxWaitTimeout {
+		return invalid(errors.New("timeout_sec must be between 0 and 300"))
+	}
+	if tick < 0 || tick > maxPollInterval {
+		return invalid(errors.New("poll_interval_sec must be between 0 and 60"))
+	}
+	if err := s.allowWallet(ctx, req.MerchantAddress); err != nil {
+		return err
+	}
+
+	send := func(st paymentv1.WaitPaymentUpdate_Status, attempt int) error {
+		return stream.Send(&paymentv1.WaitPaymentUpdate{Status: st, Attempt: int32(attempt), CheckedAt: timestamppb.Now()})
+	}
+	attempts := 0
+	paid, err := s.ton.PollPayment(ctx, req, timeout, tick, func(attempt int, paid bool) error {
+		attempts = attempt
+		if paid {
+			return send(paymentv1.WaitPaymentUpdate_STATUS_PAID, attempt)
+		}
+		return send(paymentv1.WaitPaymentUpdate_STATUS_PENDING, attempt)
+	})
+	if err != nil {
+		return internal("Error checking payment: ", err)
+	}
+	if !paid {
+		return send(paymentv1.WaitPaymentUpdate_STATUS_TIMEOUT, attempts)
+	}
+	return nil
+}
+
+func (s *service) ValidateTransaction(ctx context.Context, in *paymentv1.ValidateTransactionRequest) (*paymentv1.TransactionValidation, error) {
+	req := models.PaymentValidationRequest{
+		WalletAddress: in.GetWalletAddress(),
+		TxHash:        in.GetTxHash(),
+		SenderAddress: in.GetSenderAddress(),
+		Amount:        in.GetAmount(),
+		Currency:      in.GetCurrency(),
+		Comment:       in.GetComment(),
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, invalid(err)
+	}
+	if err := s.allowWallet(ctx, req.WalletAddress); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.ton.ValidateTransaction(ctx, req)
+	if err != nil {
+		return nil, internal("Validation failed: ", err)
+	}
+	out := &paymentv1.TransactionValidation{Valid: result.Valid, Status: result.Status}
+	if result.Transfer != nil {
+		out.Transfer = transaction(*result.Transfer)
+	}
+	for _, m := range result.Mismatches {
+		out.Mismatches = append(out.Mismatches, &paymentv1.FieldMismatch{Field: m.Field, Expected: m.Expected, Actual: m.Actual})
+	}
+	return out, nil
+}
+
+func (s *service) GetAccountInfo(ctx context.Context, in *paymentv1.GetAccountInfoRequest) (*paymentv1.AccountInfo, error) {
+	if err := s.allowWallet(ctx, in.GetAccount()); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	info, err := s.ton.GetAccountInfo(ctx, in.GetAccount())
+	if err != nil {
+		return nil, internal("Failed to get account info: ", err)
+	}
+	out := &paymentv1.AccountInfo{Address: info.Address, Balance: info.Balance, Status: info.Status, LastActivity: timestamp(info.LastActivity)}
+	for _, j := range info.Jettons {
+		out.Jettons = append(out.Jettons, &paymentv1.JettonBalance{Name: j.Name, Symbol: j.Symbol, Balance: j.Balance, Address: j.Address})
+	}
+	for _, n := range info.NFTs {
+		item := &paymentv1.NFTItem{Address: n.Address, Name: n.Name, Description: n.Description, Image: n.Image}
+		if n.Metadata != nil {
+			if b, err := json.Marshal(n.Metadata); err == nil {
+				item.MetadataJson = string(b)
+			}
+		}
+		out.Nfts = append(out.Nfts, item)
+	}
+	return out, nil
+}
+
+func (s *service) GetTransactionHistory(ctx context.Context, in *paymentv1.GetTransactionHistoryRequest) (*paymentv1.GetTransactionHistoryResponse, error) {
+	if err := s.allowWallet(ctx, in.GetAccount()); err != nil {
+		return nil, err
+	}
+	limit := int(in.GetLimit())
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	filter := models.TransactionFilter{
+		Direction:    in.GetDirection(),
+		MinAmount:    in.GetMinAmount(),
+		MaxAmount:    in.GetMaxAmount(),
+		Counterparty: in.GetCounterparty(),
+		Comment:      in.GetComment(),
+		CommentMatch: in.GetCommentMatch(),
+		Types:        in.GetTypes(),
+	}
+	if in.GetFrom() != nil {
+		filter.From = in.GetFrom().AsTime()
+	}
+	if in.GetTo() != nil {
+		filter.To = in.GetTo().AsTime()
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	transactions, err := s.ton.GetTransactionHistory(ctx, in.GetAccount(), limit, filter)
+	if errors.Is(err, services.ErrBadFilter) {
+		return nil, invalid(err)
+	}
+	if err != nil {
+		return nil, internal("Failed to get transaction history: ", err)
+	}
+	out := &paymentv1.GetTransactionHistoryResponse{}
+	for _, t := range transactions {
+		out.Transactions = append(out.Transactions, transaction(t))
+	}
+	return out, nil
+}
+
+func (s *service) GetBalance(ctx context.Context, in *paymentv1.GetBalanceRequest) (*paymentv1.Balance, error) {
+	if err := s.allowWallet(ctx, in.GetAccount()); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	balance, err := s.ton.GetWalletBalance(ctx, in.GetAccount())
+	if err != nil {
+		return nil, internal("Failed to get balance: ", err)
+	}
+	return &paymentv1.Balance{Address: in.GetAccount(), Balance: balance, Unit: "TON"}, nil
+}
+
+func transaction(t models.TransactionInfo) *paymentv1.Transaction {
+	out := &paymentv1.Transaction{
+		Hash:      t.Hash,
+		From:      t.From,
+		To:        t.To,
+		Amount:    t.Amount,
+		Status:    t.Status,
+		Timestamp: timestamp(t.Timestamp),
+		Comment:   t.Comment,
+		Currency:  t.Currency,
+		Direction: t.Direction,
+		Kind:      t.Kind,
+	}
+	if d := t.Details; d != nil {
+		out.Details = &paymentv1.TransactionDetails{
+			Nft:         d.NFT,
+			Dex:         d.Dex,
+			AssetIn:     d.AssetIn,
+			AmountIn:    d.AmountIn,
+			AssetOut:    d.AssetOut,
+			AmountOut:   d.AmountOut,
+			Contract:    d.Contract,
+			Interfaces:  d.Interfaces,
+			Operation:   d.Operation,
+			ActionType:  d.ActionType,
+			Description: d.Description,
+		}
+		if j := d.Jetton; j != nil {
+			out.Details.Jetton = &paymentv1.Jetton{Address: j.Address, Symbol: j.Symbol, Name: j.Name, Decimals: int32(j.Decimals)}
+		}
+	}
+	return out
+}
+
+// timestamp — nil для нулевого времени (поле не задано).
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
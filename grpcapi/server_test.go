@@ -0,0 +1,190 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"payment-service/config"
+	"payment-service/grpcapi/paymentv1"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+	"payment-service/storage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testWallet  = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+	otherWallet = "0:b"
+	testTxHash  = "9f3c8a0b7e6d5c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928170605f4e3"
+	clientKey   = "client-key"
+)
+
+// fakeTonAPI — первые emptyPolls запросов истории возвращают пустой список, дальше — оплату 1.5 TON.
+func fakeTonAPI(t *testing.T, emptyPolls int32) *httptest.Server {
+	event := `{"event_id":"` + testTxHash + `","timestamp":1700000000,"actions":[
+	 {"type":"TonTransfer","status":"ok","TonTransfer":{"sender":{"address":"0:a"},"recipient":{"address":"` + testWallet + `"},"amount":1500000000,"comment":"hi"}}]}`
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.Path; {
+		case strings.HasSuffix(p, "/events"):
+			if polls.Add(1) <= emptyPolls {
+				_, _ = io.WriteString(w, `{"events":[]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"events":[`+event+`]}`)
+		case strings.HasPrefix(p, "/v2/events/"):
+			_, _ = io.WriteString(w, event)
+		case strings.HasPrefix(p, "/v2/accounts/"):
+			_, _ = io.WriteString(w, `{"balance":2500000000,"status":"active"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// dial — PaymentService на bufconn; AUTH_REQUIRED, общий ключ clientKey и мерчант с кошельком testWallet.
+// Возвращает клиента и ключ мерчанта.
+func dial(t *testing.T, emptyPolls int32) (paymentv1.PaymentServiceClient, string) {
+	cfg, err := config.Load("")
+	if err != nil { t.Fatalf("config: %v", err) }
+	cfg.ClientAPIKeys, cfg.AuthRequired = []string{clientKey}, true
+	live := config.Static(cfg)
+	merchants := services.NewMerchantService(storage.NewMemory())
+	_, key, err := merchants.Create(models.MerchantRequest{Name: "Shop", Wallets: []string{testWallet}})
+	if err != nil { t.Fatalf("merchant: %v", err) }
+	ton := services.NewTONServiceWithClient(services.NewRestTonAPIAdapter(fakeTonAPI(t, emptyPolls).URL, ""))
+	srv := NewServer(ton, middleware.NewAuthenticator(live, merchants, nil), live)
+
+	ln := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil { t.Fatalf("dial: %v", err) }
+	t.Cleanup(func() { _ = conn.Close() })
+	return paymentv1.NewPaymentServiceClient(conn), key.Key
+}
+
+func as(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGRPC_OperationsMirrorREST(t *testing.T) {
+	client, _ := dial(t, 0)
+	ctx := as(clientKey)
+
+	paid, err := client.CheckPayment(ctx, &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, Comment: "hi", MinAmountTon: "1"})
+	if err != nil || !paid.GetPaid() { t.Fatalf("check payment: %v, %v", paid, err) }
+	paid, err = client.CheckPayment(ctx, &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, Comment: "hi", MinAmountTon: "2"})
+	if err != nil || paid.GetPaid() { t.Fatalf("check payment above amount: %v, %v", paid, err) }
+
+	v, err := client.ValidateTransaction(ctx, &paymentv1.ValidateTransactionRequest{WalletAddress: testWallet, TxHash: testTxHash, Amount: "1.5", Comment: "hi"})
+	if err != nil || !v.GetValid() || v.GetTransfer().GetAmount() != "1.500000000" || v.GetTransfer().GetTimestamp().GetSeconds() != 1700000000 { t.Fatalf("validate: %v, %v", v, err) }
+	v, err = client.ValidateTransaction(ctx, &paymentv1.ValidateTransactionRequest{WalletAddress: testWallet, TxHash: testTxHash, Amount: "2"})
+	if err != nil || v.GetValid() || len(v.GetMismatches()) != 1 || v.GetMismatches()[0].GetField() != "amount" { t.Fatalf("validate mismatch: %v, %v", v, err) }
+
+	info, err := client.GetAccountInfo(ctx, &paymentv1.GetAccountInfoRequest{Account: testWallet})
+	if err != nil || info.GetBalance() != "2.500000000" || info.GetStatus() != "active" { t.Fatalf("account info: %v, %v", info, err) }
+	bal, err := client.GetBalance(ctx, &paymentv1.GetBalanceRequest{Account: testWallet})
+	if err != nil || bal.GetBalance() != "2.500000000" || bal.GetUnit() != "TON" { t.Fatalf("balance: %v, %v", bal, err) }
+	hist, err := client.GetTransactionHistory(ctx, &paymentv1.GetTransactionHistoryRequest{Account: testWallet, Direction: "in", Limit: 5})
+	if err != nil || len(hist.GetTransactions()) != 1 || hist.GetTransactions()[0].GetComment() != "hi" { t.Fatalf("history: %v, %v", hist, err) }
+}
+
+func TestGRPC_AuthAndErrorCodes(t *testing.T) {
+	client, merchantKey := dial(t, 0)
+
+	for _, tc := range []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"anonymous", func() error {
+			_, err := client.GetBalance(context.Background(), &paymentv1.GetBalanceRequest{Account: testWallet})
+			return err
+		}, codes.Unauthenticated},
+		{"unknown key", func() error {
+			_, err := client.GetAccountInfo(as("nope"), &paymentv1.GetAccountInfoRequest{Account: testWallet})
+			return err
+		}, codes.Unauthenticated},
+		{"merchant's own wallet", func() error {
+			_, err := client.GetBalance(as(merchantKey), &paymentv1.GetBalanceRequest{Account: testWallet})
+			return err
+		}, codes.OK},
+		{"merchant via x-api-key", func() error {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", merchantKey)
+			_, err := client.CheckPayment(ctx, &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, MinAmountTon: "1"})
+			return err
+		}, codes.OK},
+		{"merchant's foreign wallet", func() error {
+			_, err := client.GetTransactionHistory(as(merchantKey), &paymentv1.GetTransactionHistoryRequest{Account: otherWallet})
+			return err
+		}, codes.PermissionDenied},
+		{"bad amount", func() error {
+			_, err := client.CheckPayment(as(clientKey), &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, MinAmountTon: "lots"})
+			return err
+		}, codes.InvalidArgument},
+		{"missing tx hash", func() error {
+			_, err := client.ValidateTransaction(as(clientKey), &paymentv1.ValidateTransactionRequest{WalletAddress: testWallet})
+			return err
+		}, codes.InvalidArgument},
+		{"bad filter", func() error {
+			_, err := client.GetTransactionHistory(as(clientKey), &paymentv1.GetTransactionHistoryRequest{Account: testWallet, Direction: "sideways"})
+			return err
+		}, codes.InvalidArgument},
+		{"wait anonymous", func() error {
+			stream, err := client.WaitPayment(context.Background(), &paymentv1.WaitPaymentRequest{Payment: &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, MinAmountTon: "1"}})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			return err
+		}, codes.Unauthenticated},
+		{"wait too long", func() error {
+			stream, err := client.WaitPayment(as(clientKey), &paymentv1.WaitPaymentRequest{Payment: &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, MinAmountTon: "1"}, TimeoutSec: 3600})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			return err
+		}, codes.InvalidArgument},
+	} {
+		if got := status.Code(tc.call()); got != tc.want { t.Errorf("%s: code %s, want %s", tc.name, got, tc.want) }
+	}
+}
+
+func TestGRPC_WaitPaymentStreamsEachCheck(t *testing.T) {
+	client, _ := dial(t, 1)
+	stream, err := client.WaitPayment(as(clientKey), &paymentv1.WaitPaymentRequest{
+		Payment:         &paymentv1.CheckPaymentRequest{MerchantAddress: testWallet, Comment: "hi", MinAmountTon: "1"},
+		TimeoutSec:      10,
+		PollIntervalSec: 1,
+	})
+	if err != nil { t.Fatalf("wait: %v", err) }
+	var got []paymentv1.WaitPaymentUpdate_Status
+	for {
+		u, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil { t.Fatalf("recv: %v", err) }
+		if int(u.GetAttempt()) != len(got)+1 || u.GetCheckedAt() == nil { t.Fatalf("update %d: %v", len(got), u) }
+		got = append(got, u.GetStatus())
+	}
+	want := []paymentv1.WaitPaymentUpdate_Status{paymentv1.WaitPaymentUpdate_STATUS_PENDING, paymentv1.WaitPaymentUpdate_STATUS_PAID}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] { t.Fatalf("updates %v, want %v", got, want) }
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+type AuthHandler struct {
+	proofs *services.TonProofService
+}
+
+func NewAuthHandler(proofs *services.TonProofService) *AuthHandler {
+	return &AuthHandler{proofs: proofs}
+}
+
+// TonProofPayload — POST /api/auth/ton-proof/payload: nonce для подписи кошельком.
+func (h *AuthHandler) TonProofPayload(c *gin.Context) {
+	if !h.proofs.Enabled() {
+		c.JSON(http.StatusNotImplemented, models.Response{
+			Success: false,
+			Message: services.ErrProofDisabled.Error(),
+		})
+		return
+	}
+	payload, exp := h.proofs.NewPayload()
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Proof payload issued",
+		Data:    models.TonProofPayload{Payload: payload, ExpiresAt: exp},
+	})
+}
+
+// VerifyTonProof — POST /api/auth/ton-proof: проверка ton_proof и выдача сессионного токена.
+func (h *AuthHandler) VerifyTonProof(c *gin.Context) {
+	var req models.TonProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	session, err := h.proofs.Verify(req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrProofDisabled) {
+			status = http.StatusNotImplemented
+		} else if errors.Is(err, services.ErrProofSignature) || errors.Is(err, services.ErrProofStateInit) ||
+			errors.Is(err, services.ErrProofPayload) || errors.Is(err, services.ErrProofDomain) ||
+			errors.Is(err, services.ErrProofExpired) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, models.Response{
+			Success: false,
+			Message: "Proof verification failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Wallet ownership verified",
+		Data:    session,
+	})
+}
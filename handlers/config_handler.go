@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/config"
+	"payment-service/models"
+)
+
+// ConfigHandler — текущая конфигурация и её перезагрузка (/api/admin/config).
+type ConfigHandler struct {
+	config *config.Live
+}
+
+func NewConfigHandler(cfg *config.Live) *ConfigHandler {
+	return &ConfigHandler{config: cfg}
+}
+
+type configState struct {
+	Settings []config.Setting   `json:"settings"` // секреты скрыты
+	Reload   config.ReloadStats `json:"reload"`
+}
+
+func (h *ConfigHandler) state() configState {
+	return configState{Settings: h.config.Get().Settings(), Reload: h.config.Stats()}
+}
+
+// GetConfig — GET /api/admin/config: действующие настройки с источниками и счётчики перезагрузок.
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Configuration retrieved",
+		Data:    h.state(),
+	})
+}
+
+// Reload — POST /api/admin/config/reload: то же, что SIGHUP. Невалидная конфигурация
+// не применяется: 422 с ошибками, продолжает действовать прежняя.
+func (h *ConfigHandler) Reload(c *gin.Context) {
+	if err := h.config.Reload(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.Response{
+			Success: false,
+			Message: "Configuration rejected: " + err.Error(),
+			Data:    h.state(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Configuration reloaded",
+		Data:    h.state(),
+	})
+}
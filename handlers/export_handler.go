@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/config"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+type ExportHandler struct {
+	exports *services.ExportService
+	config  *config.Live
+}
+
+func NewExportHandler(exports *services.ExportService, cfg *config.Live) *ExportHandler {
+	return &ExportHandler{exports: exports, config: cfg}
+}
+
+// ExportTransactions — GET /api/exports/transactions?account=&from=&to=&format=csv|jsonl|xlsx&currency=USD.
+// Период до EXPORT_SYNC_MAX_RANGE отдаётся потоком; больший (или async=true) — фоновой задачей (202).
+func (h *ExportHandler) ExportTransactions(c *gin.Context) {
+	req := models.ExportRequest{
+		Account:  c.Query("account"),
+		Format:   strings.ToLower(c.DefaultQuery("format", models.ExportCSV)),
+		Currency: strings.ToUpper(c.Query("currency")),
+	}
+	var err error
+	if req.Account == "" {
+		err = errors.New("account is required")
+	}
+	if err == nil {
+		req.From, err = parseTimeParam(c.Query("from"))
+	}
+	if err == nil {
+		req.To, err = parseTimeParam(c.Query("to"))
+	}
+	if err == nil && !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		err = errors.New("from must be before to")
+	}
+	if err == nil && req.Format != models.ExportCSV && req.Format != models.ExportJSONL && req.Format != models.ExportXLSX {
+		err = services.ErrExportFormat
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if !middleware.AllowWallet(c, req.Account) {
+		return
+	}
+
+	if h.async(c, req) {
+		job, err := h.exports.StartJob(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.Response{
+				Success: false,
+				Message: "Failed to start export: " + err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusAccepted, models.Response{
+			Success: true,
+			Message: "Export started",
+			Data:    job,
+		})
+		return
+	}
+
+	c.Header("Content-Type", services.ExportContentType(req.Format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", services.ExportFileName(req)))
+	c.Status(http.StatusOK)
+	if _, err := h.exports.Write(c.Request.Context(), req, c.Writer); err != nil {
+		// заголовки уже отправлены — остаётся оборвать поток
+		log.Printf("export %s: %v", req.Account, err)
+		c.Abort()
+	}
+}
+
+// GetExportJob — GET /api/exports/jobs/:id
+func (h *ExportHandler) GetExportJob(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Export job retrieved",
+		Data:    job,
+	})
+}
+
+// DownloadExport — GET /api/exports/jobs/:id/download
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	path, err := h.exports.JobFile(job)
+	if err != nil {
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Message: "Failed to download export: " + err.Error(),
+		})
+		return
+	}
+	c.Header("Content-Type", services.ExportContentType(job.Request.Format))
+	c.FileAttachment(path, services.ExportFileName(job.Request))
+}
+
+func (h *ExportHandler) async(c *gin.Context, req models.ExportRequest) bool {
+	if v, _ := strconv.ParseBool(c.Query("async")); v {
+		return true
+	}
+	if req.From.IsZero() {
+		return true // вся история
+	}
+	to := req.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	return to.Sub(req.From) > h.config.Get().ExportSyncMaxRange
+}
+
+func (h *ExportHandler) loadJob(c *gin.Context) (*models.ExportJob, bool) {
+	job, err := h.exports.Job(c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrExportNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.Response{
+			Success: false,
+			Message: "Failed to get export job: " + err.Error(),
+		})
+		return nil, false
+	}
+	if !middleware.AllowWallet(c, job.Request.Account) {
+		return nil, false
+	}
+	return job, true
+}
+
+// parseTimeParam — RFC3339, дата YYYY-MM-DD (UTC) или unix-время в секундах; пусто — нулевое время.
+func parseTimeParam(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("bad time %q: use RFC3339, YYYY-MM-DD or unix seconds", s)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// HealthHandler — пробы Kubernetes (/livez, /readyz) и подробный /api/health.
+type HealthHandler struct {
+	health *services.Health
+}
+
+func NewHealthHandler(health *services.Health) *HealthHandler {
+	return &HealthHandler{health: health}
+}
+
+func (h *HealthHandler) check(c *gin.Context) models.HealthStatus {
+	st := h.health.Check(c.Request.Context())
+	st.Version = apiVersion
+	return st
+}
+
+// failedChecks — "name: error" проверок, от которых зависит готовность.
+func failedChecks(st models.HealthStatus) []models.DependencyHealth {
+	var out []models.DependencyHealth
+	for _, r := range st.Checks {
+		if !r.OK && r.Group != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Livez — GET /livez: процесс жив и обслуживает запросы; зависимости не проверяются,
+// чтобы сбой TonAPI не приводил к перезапуску подов.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{Success: true, Message: "alive"})
+}
+
+// Readyz — GET /readyz: хранилище доступно, работает хотя бы один провайдер TON, watcher не отстаёт.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	st := h.check(c)
+	if !st.Ready {
+		var reasons []string
+		for _, r := range failedChecks(st) {
+			reasons = append(reasons, r.Name+": "+r.Error)
+		}
+		c.JSON(http.StatusServiceUnavailable, models.Response{
+			Success: false,
+			Message: "Not ready: " + strings.Join(reasons, "; "),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{Success: true, Message: "ready"})
+}
+
+// Health — GET /api/health: каждая зависимость с задержкой и временем последнего успеха, версия сборки.
+// 503 — если сервис не готов (как /readyz).
+func (h *HealthHandler) Health(c *gin.Context) {
+	st := h.check(c)
+	status, message := http.StatusOK, "Service is healthy"
+	switch st.Status {
+	case models.HealthDegraded:
+		message = "Service is degraded"
+	case models.HealthDown:
+		status, message = http.StatusServiceUnavailable, "Service unavailable"
+	}
+	c.JSON(status, models.Response{
+		Success: st.Ready,
+		Message: message,
+		Data:    st,
+	})
+}
+
+// HealthV2 — GET /api/v2/health; неготовность — ошибка unavailable с отказавшими проверками в details.
+func (h *HealthHandler) HealthV2(c *gin.Context) {
+	st := h.check(c)
+	if !st.Ready {
+		var details []models.V2ErrorDetail
+		for _, r := range failedChecks(st) {
+			details = append(details, models.V2ErrorDetail{Field: r.Name, Reason: r.Error})
+		}
+		failV2(c, http.StatusServiceUnavailable, "Service unavailable", details...)
+		return
+	}
+	respondV2(c, http.StatusOK, st)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/config"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+type InvoiceHandler struct {
+	invoices *services.InvoiceService
+	rates    services.RateProvider
+	config   *config.Live
+}
+
+func NewInvoiceHandler(invoices *services.InvoiceService, rates services.RateProvider, cfg *config.Live) *InvoiceHandler {
+	return &InvoiceHandler{invoices: invoices, rates: rates, config: cfg}
+}
+
+// CreateInvoice — POST /api/invoices. Сумма в TON или в фиате (курс фиксируется сразу).
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	var req models.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if !middleware.AllowWallet(c, h.invoiceWallet(c, &req)) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	inv, err := h.invoices.Create(ctx, req)
+	if err != nil {
+		c.JSON(createInvoiceStatus(err), models.Response{
+			Success: false,
+			Message: "Failed to create invoice: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Invoice created",
+		Data:    inv,
+	})
+}
+
+// invoiceWallet — кошелёк, на который выставляется инвойс. С ключом мерчанта инвойс привязывается
+// к нему, кошелёк и валюта берутся по умолчанию из реестра.
+func (h *InvoiceHandler) invoiceWallet(c *gin.Context, req *models.CreateInvoiceRequest) string {
+	if p := middleware.GetPrincipal(c); p.Kind == middleware.PrincipalMerchant {
+		req.MerchantID = p.Merchant.ID
+		if req.MerchantAddress == "" {
+			req.MerchantAddress = p.Merchant.Wallets[0]
+		}
+		if req.FiatAmount != "" && req.FiatCurrency == "" {
+			req.FiatCurrency = p.Merchant.DefaultCurrency
+		}
+	}
+	if req.MerchantAddress == "" {
+		return h.invoices.DefaultWallet()
+	}
+	return req.MerchantAddress
+}
+
+func createInvoiceStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCommentInUse):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrDepositsDisabled):
+		return http.StatusNotImplemented
+	}
+	return http.StatusBadRequest
+}
+
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	inv, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Invoice retrieved",
+		Data:    inv,
+	})
+}
+
+// GetInvoicePaymentLink — GET /api/invoices/:id/payment-link: ссылки и QR по сумме инвойса.
+func (h *InvoiceHandler) GetInvoicePaymentLink(c *gin.Context) {
+	inv, ok := h.loadInvoice(c)
+	if !ok {
+		return
+	}
+	req := models.PaymentLinkRequest{MerchantAddress: inv.MerchantAddress, AmountTon: inv.AmountTon, Comment: inv.Comment}
+	if inv.DepositAddress != "" {
+		// депозитный режим: платят на отдельный адрес, комментарий не нужен
+		req = models.PaymentLinkRequest{MerchantAddress: inv.DepositAddress, AmountTon: inv.AmountTon}
+	}
+	link, err := services.BuildPaymentLink(req)
+	if err == nil {
+		link.QRPNG, err = services.QRDataURI(link.TonURI, 256)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Message: "Failed to build payment link: " + err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Payment link created",
+		Data:    link,
+	})
+}
+
+// GetRate — GET /api/rates/:currency: текущая цена 1 TON.
+func (h *InvoiceHandler) GetRate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	currency := strings.ToUpper(c.Param("currency"))
+	price, err := h.rates.TonPrice(ctx, currency)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.Response{
+			Success: false,
+			Message: "Failed to get rate: " + err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Rate retrieved",
+		Data:    models.Rate{Currency: currency, TonPrice: price.String(), Source: h.rates.Name(), At: time.Now().UTC()},
+	})
+}
+
+func (h *InvoiceHandler) loadInvoice(c *gin.Context) (*models.Invoice, bool) {
+	inv, err := h.invoices.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(getInvoiceStatus(err), models.Response{
+			Success: false,
+			Message: "Failed to get invoice: " + err.Error(),
+		})
+		return nil, false
+	}
+	if !middleware.AllowWallet(c, inv.MerchantAddress) {
+		return nil, false
+	}
+	return inv, true
+}
+
+func getInvoiceStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// LedgerHandler — журнал движения средств: проводки, сальдо, проверка инварианта.
+type LedgerHandler struct {
+	ledger *ledger.Ledger
+	hub    *services.Hub
+}
+
+// NewLedgerHandler — записанные выплаты и возвраты рассылаются подписчикам кошелька через hub.
+func NewLedgerHandler(l *ledger.Ledger, hub *services.Hub) *LedgerHandler {
+	return &LedgerHandler{ledger: l, hub: hub}
+}
+
+// ListEntries — GET /api/admin/ledger/entries?account=... | ?wallet=...
+func (h *LedgerHandler) ListEntries(c *gin.Context) {
+	entries, err := h.ledger.Entries(accountParam(c))
+	if err != nil {
+		ledgerError(c, "Failed to list ledger entries", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Ledger entries retrieved",
+		Data:    entries,
+	})
+}
+
+// GetBalances — GET /api/admin/ledger/balances?account=... | ?wallet=...
+func (h *LedgerHandler) GetBalances(c *gin.Context) {
+	account := accountParam(c)
+	if account == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: account or wallet is required",
+		})
+		return
+	}
+	h.balances(c, account)
+}
+
+// GetWalletBalance — GET /api/ledger/wallets/:account/balance: сальдо кошелька по журналу.
+func (h *LedgerHandler) GetWalletBalance(c *gin.Context) {
+	h.balances(c, ledger.Wallet(services.LedgerWallet(c.Param("account"))))
+}
+
+// Check — GET /api/admin/ledger/check: дебет равен кредиту.
+func (h *LedgerHandler) Check(c *gin.Context) {
+	if err := h.ledger.Check(); err != nil {
+		c.JSON(http.StatusConflict, models.Response{
+			Success: false,
+			Message: "Ledger is inconsistent: " + err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Ledger is balanced",
+	})
+}
+
+// RecordPayout — POST /api/admin/ledger/payouts: выплата, выполненная вне сервиса.
+func (h *LedgerHandler) RecordPayout(c *gin.Context) {
+	h.record(c, ledger.KindPayout)
+}
+
+// RecordRefund — POST /api/admin/ledger/refunds: возврат покупателю.
+func (h *LedgerHandler) RecordRefund(c *gin.Context) {
+	h.record(c, ledger.KindRefund)
+}
+
+// RecordOpening — POST /api/admin/ledger/openings: входящий остаток кошелька на начало учёта.
+func (h *LedgerHandler) RecordOpening(c *gin.Context) {
+	h.record(c, ledger.KindOpening)
+}
+
+func (h *LedgerHandler) record(c *gin.Context, kind string) {
+	var req models.LedgerOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	fee := decimal.Zero
+	if err == nil && req.Fee != "" {
+		fee, err = decimal.NewFromString(req.Fee)
+	}
+	if err == nil && (!amount.IsPositive() || fee.IsNegative()) {
+		err = errors.New("amount must be positive and fee non-negative")
+	}
+	if err == nil && kind == ledger.KindPayout && req.Destination == "" {
+		err = errors.New("destination is required for payouts")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = ledger.CurrencyTON
+	}
+
+	wallet := services.LedgerWallet(req.Wallet)
+	now := time.Now()
+	var e ledger.Entry
+	switch kind {
+	case ledger.KindPayout:
+		e = ledger.Payout(req.Reference, wallet, services.LedgerWallet(req.Destination), amount, fee, currency, now)
+	case ledger.KindOpening:
+		e = ledger.Opening(req.Reference, wallet, amount, currency, now)
+	default:
+		e = ledger.Refund(req.Reference, wallet, amount, fee, currency, now)
+	}
+	e.Memo = req.Memo
+
+	posted, err := h.ledger.Post(e)
+	if err != nil {
+		ledgerError(c, "Failed to post ledger entry", err)
+		return
+	}
+	if h.hub != nil && kind != ledger.KindOpening {
+		h.hub.PublishPayout(wallet, *posted)
+	}
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Ledger entry posted",
+		Data:    posted,
+	})
+}
+
+func (h *LedgerHandler) balances(c *gin.Context, account string) {
+	b, err := h.ledger.Balances(account)
+	if err != nil {
+		ledgerError(c, "Failed to get balance", err)
+		return
+	}
+	out := models.LedgerBalance{Account: account, Balances: make(map[string]string, len(b))}
+	for currency, amount := range b {
+		out.Balances[currency] = amount.String()
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Balance retrieved",
+		Data:    out,
+	})
+}
+
+func accountParam(c *gin.Context) string {
+	if w := c.Query("wallet"); w != "" {
+		return ledger.Wallet(services.LedgerWallet(w))
+	}
+	return c.Query("account")
+}
+
+func ledgerError(c *gin.Context, msg string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ledger.ErrDuplicate):
+		status = http.StatusConflict
+	case errors.Is(err, ledger.ErrUnbalanced):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.Response{
+		Success: false,
+		Message: msg + ": " + err.Error(),
+	})
+}
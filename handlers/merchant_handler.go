@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// MerchantHandler — админский API реестра мерчантов (/api/admin/merchants).
+type MerchantHandler struct {
+	merchants *services.MerchantService
+}
+
+func NewMerchantHandler(merchants *services.MerchantService) *MerchantHandler {
+	return &MerchantHandler{merchants: merchants}
+}
+
+func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
+	var req models.MerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	m, key, err := h.merchants.Create(req)
+	if err != nil {
+		merchantError(c, "Failed to create merchant", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Merchant created",
+		Data:    models.MerchantCreated{Merchant: services.PublicMerchant(m), APIKey: key},
+	})
+}
+
+func (h *MerchantHandler) ListMerchants(c *gin.Context) {
+	all, err := h.merchants.List()
+	if err != nil {
+		merchantError(c, "Failed to list merchants", err)
+		return
+	}
+	out := make([]models.Merchant, 0, len(all))
+	for i := range all {
+		out = append(out, services.PublicMerchant(&all[i]))
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Merchants retrieved",
+		Data:    out,
+	})
+}
+
+func (h *MerchantHandler) GetMerchant(c *gin.Context) {
+	m, err := h.merchants.Get(c.Param("id"))
+	if err != nil {
+		merchantError(c, "Failed to get merchant", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Merchant retrieved",
+		Data:    services.PublicMerchant(m),
+	})
+}
+
+func (h *MerchantHandler) UpdateMerchant(c *gin.Context) {
+	var req models.MerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	m, err := h.merchants.Update(c.Param("id"), req)
+	if err != nil {
+		merchantError(c, "Failed to update merchant", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Merchant updated",
+		Data:    services.PublicMerchant(m),
+	})
+}
+
+func (h *MerchantHandler) DeleteMerchant(c *gin.Context) {
+	if err := h.merchants.Delete(c.Param("id")); err != nil {
+		merchantError(c, "Failed to delete merchant", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Merchant deleted",
+	})
+}
+
+func (h *MerchantHandler) IssueAPIKey(c *gin.Context) {
+	key, err := h.merchants.IssueKey(c.Param("id"))
+	if err != nil {
+		merchantError(c, "Failed to issue API key", err)
+		return
+	}
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "API key issued",
+		Data:    key,
+	})
+}
+
+func (h *MerchantHandler) RevokeAPIKey(c *gin.Context) {
+	if err := h.merchants.RevokeKey(c.Param("id"), c.Param("key_id")); err != nil {
+		merchantError(c, "Failed to revoke API key", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "API key revoked",
+	})
+}
+
+func merchantError(c *gin.Context, msg string, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrMerchantNotFound), errors.Is(err, services.ErrAPIKeyNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrWalletTaken):
+		status = http.StatusConflict
+	}
+	c.JSON(status, models.Response{
+		Success: false,
+		Message: msg + ": " + err.Error(),
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/openapi"
+)
+
+// apiVersion — версия HTTP API (в спецификации и /api/health).
+const apiVersion = "1.0.0"
+
+var txQuery = []openapi.Parameter{
+	openapi.Query("limit", "integer", "1-100, по умолчанию 10"),
+	openapi.Query("from", "string", "RFC3339, YYYY-MM-DD или unix-время"),
+	openapi.Query("to", "string", "не включительно"),
+	openapi.Query("direction", "string", "in | out"),
+	openapi.Query("min_amount", "string", "TON; строки не в TON отбрасываются"),
+	openapi.Query("max_amount", "string", "TON; строки не в TON отбрасываются"),
+	openapi.Query("counterparty", "string", "отправитель входящих, получатель исходящих"),
+	openapi.Query("comment", "string", ""),
+	openapi.Query("comment_match", "string", "exact | prefix"),
+	openapi.Query("types", "string", "через запятую: ton_transfer, jetton_transfer, nft_transfer, swap, contract_deploy, contract_call, other"),
+}
+
+var exportQuery = []openapi.Parameter{
+	{Name: "account", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	openapi.Query("from", "string", "RFC3339, YYYY-MM-DD или unix-время"),
+	openapi.Query("to", "string", "не включительно"),
+	openapi.Query("format", "string", "csv | jsonl | xlsx"),
+	openapi.Query("currency", "string", "фиатная оценка, например USD"),
+	openapi.Query("async", "boolean", "всегда фоновой задачей"),
+}
+
+var ledgerQuery = []openapi.Parameter{
+	openapi.Query("account", "string", "счёт журнала"),
+	openapi.Query("wallet", "string", "или кошелёк"),
+}
+
+var exportTypes = []string{"text/csv", "application/x-ndjson", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}
+
+// apiOperations — все маршруты HTTP API; тест сверяет их с роутером.
+func apiOperations() []openapi.Op {
+	const (
+		q = http.StatusBadRequest
+		n = http.StatusNotFound
+		c = http.StatusConflict
+		e = http.StatusInternalServerError
+	)
+	return []openapi.Op{
+		{Method: "POST", Path: "/api/check-payment", ID: "checkPayment", Tag: "payments", Summary: "Есть ли входящий перевод с комментарием на сумму не меньше заданной", Body: models.CheckPaymentRequest{}, Data: false, Errors: []int{q, e}},
+		{Method: "POST", Path: "/api/validate-payment", ID: "validatePayment", Tag: "payments", Summary: "Проверка транзакции по hash и ожидаемым полям", Body: models.PaymentValidationRequest{}, Data: models.TransactionValidation{}, Errors: []int{q, e}},
+		{Method: "GET", Path: "/api/account-info/:account", ID: "getAccountInfo", Tag: "accounts", Summary: "Состояние аккаунта", Data: models.AccountInfo{}, Errors: []int{e}},
+		{Method: "GET", Path: "/api/transactions/:account", ID: "getTransactions", Tag: "accounts", Summary: "История операций аккаунта", Params: txQuery, Data: []models.TransactionInfo{}, Errors: []int{q, e}},
+		{Method: "GET", Path: "/api/balance/:account", ID: "getBalance", Tag: "accounts", Summary: "Баланс кошелька в TON", Data: models.WalletBalance{}, Errors: []int{e}},
+		{Method: "GET", Path: "/livez", ID: "livez", Tag: "service", Summary: "Процесс жив (liveness-проба)", Auth: openapi.AuthNone},
+		{Method: "GET", Path: "/readyz", ID: "readyz", Tag: "service", Summary: "Хранилище, провайдер TON и watcher в порядке (readiness-проба)", Auth: openapi.AuthNone, Errors: []int{http.StatusServiceUnavailable}},
+		{Method: "GET", Path: "/api/health", ID: "health", Tag: "service", Summary: "Состояние зависимостей и версия сборки; 503 — не готов", Auth: openapi.AuthNone, Data: models.HealthStatus{}, Also: map[int]any{http.StatusServiceUnavailable: models.HealthStatus{}}},
+		{Method: "GET", Path: "/api/openapi.json", ID: "openapi", Tag: "service", Summary: "Эта спецификация", Auth: openapi.AuthNone, Raw: []string{"application/json"}},
+		{Method: "POST", Path: "/api/payment-link", ID: "createPaymentLink", Tag: "payments", Summary: "ton:// URI, ссылки кошельков и QR", Body: models.PaymentLinkRequest{}, Data: models.PaymentLink{}, Errors: []int{q, e}},
+		{Method: "GET", Path: "/api/payment-link/qr", ID: "paymentLinkQR", Tag: "payments", Summary: "QR-код ссылки на оплату",
+			Params: append(openapi.QueryParams(models.PaymentLinkRequest{}), openapi.Query("format", "string", "png | svg"), openapi.Query("size", "integer", "px, по умолчанию 256")),
+			Raw:    []string{"image/png", "image/svg+xml"}, Errors: []int{q}},
+		{Method: "POST", Path: "/api/tonconnect/transaction", ID: "createTonConnectTransaction", Tag: "tonconnect", Summary: "Запрос sendTransaction для TON Connect", Body: models.TonConnectTxRequest{}, Data: models.TonConnectTransaction{}, Errors: []int{q}},
+		{Method: "POST", Path: "/api/tonconnect/verify", ID: "verifyTonConnectTransaction", Tag: "tonconnect", Summary: "Поиск отправленной транзакции по BOC или hash сообщения", Body: models.TonConnectVerifyRequest{}, Data: models.TonConnectVerification{}, Errors: []int{q, e}},
+		{Method: "GET", Path: "/api/ws", ID: "websocket", Tag: "events", Summary: "WebSocket: события по кошелькам и инвойсам", Status: http.StatusSwitchingProtocols, Raw: []string{}},
+		{Method: "POST", Path: "/api/auth/ton-proof/payload", ID: "tonProofPayload", Tag: "auth", Summary: "Nonce для ton_proof", Auth: openapi.AuthNone, Data: models.TonProofPayload{}, Errors: []int{http.StatusNotImplemented}},
+		{Method: "POST", Path: "/api/auth/ton-proof", ID: "verifyTonProof", Tag: "auth", Summary: "Проверка ton_proof и выдача сессии", Auth: openapi.AuthNone, Body: models.TonProofRequest{}, Data: models.TonProofSession{}, Errors: []int{q, http.StatusUnauthorized, http.StatusNotImplemented}},
+		{Method: "POST", Path: "/api/invoices", ID: "createInvoice", Tag: "invoices", Summary: "Инвойс в TON или в фиате", Body: models.CreateInvoiceRequest{}, Status: http.StatusCreated, Data: models.Invoice{}, Errors: []int{q, c, http.StatusNotImplemented}},
+		{Method: "GET", Path: "/api/invoices/:id", ID: "getInvoice", Tag: "invoices", Data: models.Invoice{}, Errors: []int{n, e}},
+		{Method: "GET", Path: "/api/invoices/:id/payment-link", ID: "getInvoicePaymentLink", Tag: "invoices", Summary: "Ссылки и QR по сумме инвойса", Data: models.PaymentLink{}, Errors: []int{n, e}},
+		{Method: "GET", Path: "/api/rates/:currency", ID: "getRate", Tag: "invoices", Summary: "Цена 1 TON в валюте", Data: models.Rate{}, Errors: []int{http.StatusBadGateway}},
+		{Method: "GET", Path: "/api/orphans", ID: "listOrphans", Tag: "orphans", Summary: "Несопоставленные платежи", Params: []openapi.Parameter{openapi.Query("status", "string", "unmatched | attached | refund"), openapi.Query("wallet", "string", "")}, Data: []models.OrphanPayment{}, Errors: []int{e}},
+		{Method: "GET", Path: "/api/orphans/:id", ID: "getOrphan", Tag: "orphans", Data: models.OrphanPayment{}, Errors: []int{n, e}},
+		{Method: "POST", Path: "/api/orphans/:id/attach", ID: "attachOrphan", Tag: "orphans", Summary: "Привязать платёж к инвойсу", Auth: openapi.AuthOperator, Body: models.AttachOrphanRequest{}, Data: models.OrphanAttachment{}, Errors: []int{q, n, c, e}},
+		{Method: "POST", Path: "/api/orphans/:id/refund", ID: "refundOrphan", Tag: "orphans", Summary: "Пометить к возврату", Auth: openapi.AuthOperator, Body: models.RefundOrphanRequest{}, Data: models.OrphanPayment{}, Errors: []int{q, n, c, e}},
+		{Method: "GET", Path: "/api/ledger/wallets/:account/balance", ID: "getLedgerWalletBalance", Tag: "ledger", Summary: "Сальдо кошелька по журналу", Data: models.LedgerBalance{}, Errors: []int{e}},
+		{Method: "GET", Path: "/api/exports/transactions", ID: "exportTransactions", Tag: "exports", Summary: "Выгрузка переводов: потоком или фоновой задачей (202)", Params: exportQuery, Raw: exportTypes, Also: map[int]any{http.StatusAccepted: models.ExportJob{}}, Errors: []int{q, e}},
+		{Method: "GET", Path: "/api/exports/jobs/:id", ID: "getExportJob", Tag: "exports", Data: models.ExportJob{}, Errors: []int{n, e}},
+		{Method: "GET", Path: "/api/exports/jobs/:id/download", ID: "downloadExport", Tag: "exports", Raw: exportTypes, Errors: []int{n, c, e}},
+
+		{Method: "POST", Path: "/api/v2/check-payment", ID: "checkPaymentV2", Tag: "v2", Summary: "Есть ли входящий перевод; ненайденный платёж — paid=false", Body: models.CheckPaymentRequest{}, Data: models.PaymentCheckResult{}, Errors: []int{q, e}, V2: true},
+		{Method: "POST", Path: "/api/v2/validate-payment", ID: "validatePaymentV2", Tag: "v2", Summary: "Проверка транзакции по hash и ожидаемым полям", Body: models.PaymentValidationRequest{}, Data: models.TransactionValidation{}, Errors: []int{q, e}, V2: true},
+		{Method: "GET", Path: "/api/v2/account-info/:account", ID: "getAccountInfoV2", Tag: "v2", Summary: "Состояние аккаунта", Data: models.AccountInfo{}, Errors: []int{e}, V2: true},
+		{Method: "GET", Path: "/api/v2/transactions/:account", ID: "getTransactionsV2", Tag: "v2", Summary: "История операций аккаунта; meta.pagination", Params: txQuery, Data: []models.TransactionInfo{}, Errors: []int{q, e}, V2: true},
+		{Method: "GET", Path: "/api/v2/balance/:account", ID: "getBalanceV2", Tag: "v2", Summary: "Баланс кошелька в TON", Data: models.WalletBalance{}, Errors: []int{e}, V2: true},
+		{Method: "GET", Path: "/api/v2/health", ID: "healthV2", Tag: "v2", Summary: "Состояние зависимостей и версия сборки", Auth: openapi.AuthNone, Data: models.HealthStatus{}, Errors: []int{http.StatusServiceUnavailable}, V2: true},
+		{Method: "POST", Path: "/api/v2/payment-link", ID: "createPaymentLinkV2", Tag: "v2", Summary: "ton:// URI, ссылки кошельков и QR", Body: models.PaymentLinkRequest{}, Data: models.PaymentLink{}, Errors: []int{q, e}, V2: true},
+		{Method: "POST", Path: "/api/v2/invoices", ID: "createInvoiceV2", Tag: "v2", Summary: "Инвойс в TON или в фиате", Body: models.CreateInvoiceRequest{}, Status: http.StatusCreated, Data: models.Invoice{}, Errors: []int{q, c, http.StatusNotImplemented}, V2: true},
+		{Method: "GET", Path: "/api/v2/invoices/:id", ID: "getInvoiceV2", Tag: "v2", Data: models.Invoice{}, Errors: []int{n, e}, V2: true},
+		{Method: "GET", Path: "/api/v2/rates/:currency", ID: "getRateV2", Tag: "v2", Summary: "Цена 1 TON в валюте", Data: models.Rate{}, Errors: []int{http.StatusBadGateway}, V2: true},
+
+		{Method: "POST", Path: "/api/admin/merchants", ID: "createMerchant", Tag: "admin", Auth: openapi.AuthAdmin, Body: models.MerchantRequest{}, Status: http.StatusCreated, Data: models.MerchantCreated{}, Errors: []int{q, c}},
+		{Method: "GET", Path: "/api/admin/merchants", ID: "listMerchants", Tag: "admin", Auth: openapi.AuthAdmin, Data: []models.Merchant{}, Errors: []int{q}},
+		{Method: "GET", Path: "/api/admin/merchants/:id", ID: "getMerchant", Tag: "admin", Auth: openapi.AuthAdmin, Data: models.Merchant{}, Errors: []int{q, n}},
+		{Method: "PUT", Path: "/api/admin/merchants/:id", ID: "updateMerchant", Tag: "admin", Auth: openapi.AuthAdmin, Body: models.MerchantRequest{}, Data: models.Merchant{}, Errors: []int{q, n, c}},
+		{Method: "DELETE", Path: "/api/admin/merchants/:id", ID: "deleteMerchant", Tag: "admin", Auth: openapi.AuthAdmin, Errors: []int{q, n}},
+		{Method: "POST", Path: "/api/admin/merchants/:id/api-keys", ID: "issueMerchantAPIKey", Tag: "admin", Auth: openapi.AuthAdmin, Status: http.StatusCreated, Data: models.IssuedAPIKey{}, Errors: []int{q, n}},
+		{Method: "DELETE", Path: "/api/admin/merchants/:id/api-keys/:key_id", ID: "revokeMerchantAPIKey", Tag: "admin", Auth: openapi.AuthAdmin, Errors: []int{q, n}},
+		{Method: "GET", Path: "/api/admin/ledger/entries", ID: "listLedgerEntries", Tag: "admin", Auth: openapi.AuthAdmin, Params: ledgerQuery, Data: []ledger.Entry{}, Errors: []int{q, c, e}},
+		{Method: "GET", Path: "/api/admin/ledger/balances", ID: "getLedgerBalances", Tag: "admin", Auth: openapi.AuthAdmin, Params: ledgerQuery, Data: models.LedgerBalance{}, Errors: []int{q, c, e}},
+		{Method: "GET", Path: "/api/admin/ledger/check", ID: "checkLedger", Tag: "admin", Auth: openapi.AuthAdmin, Summary: "Дебет равен кредиту", Errors: []int{c}},
+		{Method: "POST", Path: "/api/admin/ledger/payouts", ID: "recordPayout", Tag: "admin", Auth: openapi.AuthAdmin, Body: models.LedgerOperationRequest{}, Status: http.StatusCreated, Data: ledger.Entry{}, Errors: []int{q, c, e}},
+		{Method: "POST", Path: "/api/admin/ledger/refunds", ID: "recordRefund", Tag: "admin", Auth: openapi.AuthAdmin, Body: models.LedgerOperationRequest{}, Status: http.StatusCreated, Data: ledger.Entry{}, Errors: []int{q, c, e}},
+		{Method: "POST", Path: "/api/admin/ledger/openings", ID: "recordOpening", Tag: "admin", Auth: openapi.AuthAdmin, Summary: "Входящий остаток кошелька: с ним включается сверка баланса", Body: models.LedgerOperationRequest{}, Status: http.StatusCreated, Data: ledger.Entry{}, Errors: []int{q, c, e}},
+		{Method: "POST", Path: "/api/admin/reconcile", ID: "runReconcile", Tag: "admin", Auth: openapi.AuthAdmin, Summary: "Сверка с блокчейном", Data: models.ReconciliationReport{}, Errors: []int{e}},
+		{Method: "GET", Path: "/api/admin/reconcile/last", ID: "lastReconcile", Tag: "admin", Auth: openapi.AuthAdmin, Data: models.ReconciliationReport{}, Errors: []int{n, e}},
+		{Method: "GET", Path: "/api/admin/config", ID: "getConfig", Tag: "admin", Auth: openapi.AuthAdmin, Summary: "Действующие настройки и счётчики перезагрузок", Data: configState{}},
+		{Method: "POST", Path: "/api/admin/config/reload", ID: "reloadConfig", Tag: "admin", Auth: openapi.AuthAdmin, Summary: "Перечитать конфигурацию", Data: configState{}, Errors: []int{http.StatusUnprocessableEntity}},
+	}
+}
+
+// withMiddlewareErrors — ответы, которые дают middleware, а не хендлер:
+// проверка доступа (401/403) и Idempotency-Key у изменяющих запросов.
+func withMiddlewareErrors(ops []openapi.Op) []openapi.Op {
+	out := make([]openapi.Op, len(ops))
+	for i, op := range ops {
+		errs := append([]int(nil), op.Errors...)
+		if op.Auth != openapi.AuthNone {
+			errs = append(errs, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		if op.Method != "GET" {
+			op.Params = append(append([]openapi.Parameter(nil), op.Params...), openapi.Parameter{
+				Name: "Idempotency-Key", In: "header", Description: "повтор с тем же ключом возвращает сохранённый ответ (анонимные запросы не кешируются)",
+				Schema: &openapi.Schema{Type: "string"},
+			})
+			errs = append(errs, http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge,
+				http.StatusUnprocessableEntity, http.StatusInternalServerError)
+		}
+		op.Errors = errs
+		out[i] = op
+	}
+	return out
+}
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Document
+)
+
+// OpenAPISpec — спецификация HTTP API.
+func OpenAPISpec() *openapi.Document {
+	specOnce.Do(func() {
+		spec = openapi.New(
+			openapi.Info{Title: "TON payment service", Version: apiVersion,
+				Description: "Ответы /api — {success, message, data}, /api/v2 — {data, meta} или {error, meta}. Ключ клиента: Authorization: Bearer, X-API-Key или ?token=."},
+			[]openapi.Tag{
+				{Name: "payments"}, {Name: "accounts"}, {Name: "invoices"}, {Name: "orphans"}, {Name: "tonconnect"},
+				{Name: "auth"}, {Name: "ledger"}, {Name: "exports"}, {Name: "events"}, {Name: "service"},
+				{Name: "v2", Description: "API v2: типизированный data, ошибки {code, message, details}, meta.request_id (X-Request-ID)"},
+				{Name: "admin", Description: "ключи ADMIN_API_KEYS"},
+			},
+			withMiddlewareErrors(apiOperations()),
+		)
+	})
+	return spec
+}
+
+// ServeOpenAPI — GET /api/openapi.json
+func ServeOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, OpenAPISpec())
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// OrphanHandler — разбор «потерянных» платежей (/api/orphans).
+type OrphanHandler struct {
+	orphans *services.OrphanService
+}
+
+func NewOrphanHandler(orphans *services.OrphanService) *OrphanHandler {
+	return &OrphanHandler{orphans: orphans}
+}
+
+// ListOrphans — GET /api/orphans?status=unmatched&wallet=...
+// Мерчант видит только платежи на свои кошельки.
+func (h *OrphanHandler) ListOrphans(c *gin.Context) {
+	wallet := c.Query("wallet")
+	if wallet != "" && !middleware.AllowWallet(c, wallet) {
+		return
+	}
+	all, err := h.orphans.List(c.Query("status"))
+	if err != nil {
+		orphanError(c, "Failed to list orphan payments", err)
+		return
+	}
+	out := make([]models.OrphanPayment, 0, len(all))
+	for _, o := range all {
+		if wallet != "" && !services.SameAddress(o.MerchantAddress, wallet) && !services.SameAddress(o.Wallet, wallet) {
+			continue
+		}
+		if middleware.WalletVisible(c, o.MerchantAddress) {
+			out = append(out, o)
+		}
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Orphan payments retrieved",
+		Data:    out,
+	})
+}
+
+// GetOrphan — GET /api/orphans/:id, вместе с подсказками, к какому инвойсу платёж относится.
+func (h *OrphanHandler) GetOrphan(c *gin.Context) {
+	o, ok := h.loadOrphan(c)
+	if !ok {
+		return
+	}
+	if o.Status == models.OrphanUnmatched {
+		suggestions, err := h.orphans.Suggest(o)
+		if err != nil {
+			orphanError(c, "Failed to build suggestions", err)
+			return
+		}
+		o.Suggestions = suggestions
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Orphan payment retrieved",
+		Data:    o,
+	})
+}
+
+// AttachOrphan — POST /api/orphans/:id/attach: платёж закрывает указанный инвойс.
+func (h *OrphanHandler) AttachOrphan(c *gin.Context) {
+	var req models.AttachOrphanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if _, ok := h.loadOrphan(c); !ok {
+		return
+	}
+
+	o, inv, err := h.orphans.Attach(c.Param("id"), req.InvoiceID)
+	if err != nil {
+		orphanError(c, "Failed to attach payment", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Payment attached to invoice",
+		Data:    models.OrphanAttachment{Orphan: o, Invoice: inv},
+	})
+}
+
+// RefundOrphan — POST /api/orphans/:id/refund: пометка к возврату отправителю.
+func (h *OrphanHandler) RefundOrphan(c *gin.Context) {
+	var req models.RefundOrphanRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if _, ok := h.loadOrphan(c); !ok {
+		return
+	}
+
+	o, err := h.orphans.MarkRefund(c.Param("id"), req.Note)
+	if err != nil {
+		orphanError(c, "Failed to mark payment for refund", err)
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Payment marked for refund",
+		Data:    o,
+	})
+}
+
+func (h *OrphanHandler) loadOrphan(c *gin.Context) (*models.OrphanPayment, bool) {
+	o, err := h.orphans.Get(c.Param("id"))
+	if err != nil {
+		orphanError(c, "Failed to get orphan payment", err)
+		return nil, false
+	}
+	if !middleware.AllowWallet(c, o.MerchantAddress) {
+		return nil, false
+	}
+	return o, true
+}
+
+func orphanError(c *gin.Context, msg string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrOrphanNotFound), errors.Is(err, services.ErrInvoiceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrOrphanResolved), errors.Is(err, services.ErrInvoicePaid):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvoiceMismatch):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, models.Response{
+		Success: false,
+		Message: msg + ": " + err.Error(),
+	})
+}
@@ -2,32 +2,40 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"payment-service/config"
+	"payment-service/middleware"
 	"payment-service/models"
 	"payment-service/services"
 )
 
 type PaymentHandler struct {
 	tonService *services.TONService
-	config     *config.Config
+	config     *config.Live
 }
 
-func NewPaymentHandler(cfg *config.Config) (*PaymentHandler, error) {
+func NewPaymentHandler(cfg *config.Live) (*PaymentHandler, error) {
 	tonService, err := services.NewTONService(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TON service: %v", err)
 	}
 
+	return NewPaymentHandlerWithService(tonService, cfg), nil
+}
+
+// NewPaymentHandlerWithService — хендлер поверх уже созданного сервиса
+// (сервис разделяется с watcher и другими хендлерами).
+func NewPaymentHandlerWithService(tonService *services.TONService, cfg *config.Live) *PaymentHandler {
 	return &PaymentHandler{
 		tonService: tonService,
 		config:     cfg,
-	}, nil
+	}
 }
 
 func (h *PaymentHandler) CheckPayment(c *gin.Context) {
@@ -40,7 +48,11 @@ func (h *PaymentHandler) CheckPayment(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.RequestTimeout)
+	if !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
 	defer cancel()
 
 	isValid, err := h.tonService.CheckPayment(ctx, req)
@@ -59,6 +71,8 @@ func (h *PaymentHandler) CheckPayment(c *gin.Context) {
 	})
 }
 
+// ValidatePayment — POST /api/validate-payment: транзакция по hash и, если заданы,
+// сверка sender_address, amount, currency и comment; расхождения — в data.mismatches.
 func (h *PaymentHandler) ValidatePayment(c *gin.Context) {
 	var req models.PaymentValidationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -69,10 +83,14 @@ func (h *PaymentHandler) ValidatePayment(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.RequestTimeout)
+	if !middleware.AllowWallet(c, req.WalletAddress) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
 	defer cancel()
 
-	isValid, err := h.tonService.ValidateTransaction(ctx, req.TxHash, req.WalletAddress)
+	result, err := h.tonService.ValidateTransaction(ctx, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success: false,
@@ -82,16 +100,16 @@ func (h *PaymentHandler) ValidatePayment(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, models.Response{
-		Success: isValid,
+		Success: result.Valid,
 		Message: "Payment validation completed",
-		Data:    isValid,
+		Data:    result,
 	})
 }
 
 func (h *PaymentHandler) GetAccountInfo(c *gin.Context) {
 	accountID := c.Param("account")
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
 	defer cancel()
 
 	info, err := h.tonService.GetAccountInfo(ctx, accountID)
@@ -110,19 +128,31 @@ func (h *PaymentHandler) GetAccountInfo(c *gin.Context) {
 	})
 }
 
+// GetTransactionHistory — GET /api/transactions/:account?limit=&from=&to=&direction=in|out
+// &min_amount=&max_amount=&counterparty=&comment=&comment_match=exact|prefix
+// &types=ton_transfer,jetton_transfer,nft_transfer,swap,contract_deploy,contract_call,other
 func (h *PaymentHandler) GetTransactionHistory(c *gin.Context) {
 	accountID := c.Param("account")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 100 {
-		limit = 10
+	limit, filter, err := historyQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
 	defer cancel()
 
-	transactions, err := h.tonService.GetTransactionHistory(ctx, accountID, limit)
+	transactions, err := h.tonService.GetTransactionHistory(ctx, accountID, limit, filter)
+	if errors.Is(err, services.ErrBadFilter) {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.Response{
 			Success: false,
@@ -138,10 +168,34 @@ func (h *PaymentHandler) GetTransactionHistory(c *gin.Context) {
 	})
 }
 
+// historyQuery — limit (1-100, по умолчанию 10) и фильтры истории из строки запроса.
+func historyQuery(c *gin.Context) (int, models.TransactionFilter, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	filter := models.TransactionFilter{
+		Direction:    c.Query("direction"),
+		MinAmount:    c.Query("min_amount"),
+		MaxAmount:    c.Query("max_amount"),
+		Counterparty: c.Query("counterparty"),
+		Comment:      c.Query("comment"),
+		CommentMatch: c.Query("comment_match"),
+	}
+	if types := c.Query("types"); types != "" {
+		filter.Types = strings.Split(types, ",")
+	}
+	if filter.From, err = parseTimeParam(c.Query("from")); err == nil {
+		filter.To, err = parseTimeParam(c.Query("to"))
+	}
+	return limit, filter, err
+}
+
 func (h *PaymentHandler) GetBalance(c *gin.Context) {
 	accountID := c.Param("account")
 
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.RequestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
 	defer cancel()
 
 	balance, err := h.tonService.GetWalletBalance(ctx, accountID)
@@ -156,34 +210,6 @@ func (h *PaymentHandler) GetBalance(c *gin.Context) {
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
 		Message: "Balance retrieved successfully",
-		Data: map[string]interface{}{
-			"address": accountID,
-			"balance": balance,
-			"unit":    "TON",
-		},
-	})
-}
-
-func (h *PaymentHandler) HealthCheck(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// Проверяем соединение с TON API
-	_, err := h.tonService.GetWalletBalance(ctx, h.config.AppWallet)
-	if err != nil {
-		c.JSON(http.StatusServiceUnavailable, models.Response{
-			Success: false,
-			Message: "Service unavailable: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, models.Response{
-		Success: true,
-		Message: "Service is healthy",
-		Data: map[string]interface{}{
-			"timestamp": time.Now(),
-			"version":   "1.0.0",
-		},
+		Data:    models.WalletBalance{Address: accountID, Balance: balance, Unit: "TON"},
 	})
 }
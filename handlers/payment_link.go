@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// CreatePaymentLink — POST /api/payment-link: ton:// URI, ссылки Tonkeeper/Tonhub и PNG-QR.
+func (h *PaymentHandler) CreatePaymentLink(c *gin.Context) {
+	var req models.PaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	link, err := services.BuildPaymentLink(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	if link.QRPNG, err = services.QRDataURI(link.TonURI, 256); err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Message: "Failed to render QR code: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Payment link created",
+		Data:    link,
+	})
+}
+
+// PaymentLinkQR — GET /api/payment-link/qr?merchant_address=&amount_ton=&comment=&format=png|svg&size=
+func (h *PaymentHandler) PaymentLinkQR(c *gin.Context) {
+	var req models.PaymentLinkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	link, err := services.BuildPaymentLink(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "256"))
+	img, contentType, err := services.RenderQR(link.TonURI, c.DefaultQuery("format", "png"), size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Failed to render QR code: " + err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, img)
+}
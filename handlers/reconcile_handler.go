@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// ReconcileHandler — сверка с блокчейном (/api/admin/reconcile).
+type ReconcileHandler struct {
+	reconciler *services.Reconciler
+	wallets    func() []string
+}
+
+func NewReconcileHandler(r *services.Reconciler, wallets func() []string) *ReconcileHandler {
+	return &ReconcileHandler{reconciler: r, wallets: wallets}
+}
+
+// Run — POST /api/admin/reconcile: прогон сейчас. Проходит всю историю, может занять время.
+func (h *ReconcileHandler) Run(c *gin.Context) {
+	rep, err := h.reconciler.Run(c.Request.Context(), h.wallets())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Message: "Reconciliation failed: " + err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Reconciliation completed",
+		Data:    rep,
+	})
+}
+
+// Last — GET /api/admin/reconcile/last: отчёт последнего прогона.
+func (h *ReconcileHandler) Last(c *gin.Context) {
+	rep, err := h.reconciler.Last()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Message: "Failed to get report: " + err.Error(),
+		})
+		return
+	}
+	if rep == nil {
+		c.JSON(http.StatusNotFound, models.Response{
+			Success: false,
+			Message: "No reconciliation report yet",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Reconciliation report retrieved",
+		Data:    rep,
+	})
+}
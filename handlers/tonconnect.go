@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// CreateTonConnectTransaction — POST /api/tonconnect/transaction: запрос sendTransaction для кошелька.
+func (h *PaymentHandler) CreateTonConnectTransaction(c *gin.Context) {
+	var req models.TonConnectTxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	tx, err := services.BuildTonConnectTransaction(req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "TON Connect transaction created",
+		Data:    tx,
+	})
+}
+
+// VerifyTonConnectTransaction — POST /api/tonconnect/verify: BOC/hash сообщения -> событие на кошельке мерчанта.
+func (h *PaymentHandler) VerifyTonConnectTransaction(c *gin.Context) {
+	var req models.TonConnectVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	if req.BOC == "" && req.MessageHash == "" {
+		c.JSON(http.StatusBadRequest, models.Response{
+			Success: false,
+			Message: "Invalid request: boc or message_hash is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	res, err := h.tonService.VerifyTonConnectTransaction(ctx, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.Response{
+			Success: false,
+			Message: "Verification failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Response{
+		Success: res.Matched,
+		Message: "TON Connect transaction verified",
+		Data:    res,
+	})
+}
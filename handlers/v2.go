@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"payment-service/middleware"
+	"payment-service/models"
+	"payment-service/services"
+)
+
+// API v2 (/api/v2): те же операции, что и в v1, но с типизированным data и ошибкой
+// {code, message, details} вместо Success/Message. Логика v1 не меняется.
+
+func respondV2[T any](c *gin.Context, status int, data T) {
+	c.JSON(status, models.V2Response[T]{Data: data, Meta: models.V2Meta{RequestID: middleware.RequestID(c)}})
+}
+
+func failV2(c *gin.Context, status int, message string, details ...models.V2ErrorDetail) {
+	c.JSON(status, middleware.V2Error(c, status, message, details...))
+}
+
+// bindV2 — ShouldBindJSON; при ошибке — 400 с нарушенными правилами по полям JSON в details.
+func bindV2(c *gin.Context, obj any) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+	var verrs validator.ValidationErrors
+	var terr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &verrs):
+		details := make([]models.V2ErrorDetail, 0, len(verrs))
+		for _, fe := range verrs {
+			reason := fe.Tag()
+			if fe.Param() != "" {
+				reason += "=" + fe.Param()
+			}
+			details = append(details, models.V2ErrorDetail{Field: jsonPath(reflect.TypeOf(obj), fe.StructNamespace()), Reason: reason})
+		}
+		failV2(c, http.StatusBadRequest, "Invalid request", details...)
+	case errors.As(err, &terr):
+		failV2(c, http.StatusBadRequest, "Invalid request: "+err.Error(), models.V2ErrorDetail{Field: terr.Field, Reason: "type=" + terr.Type.String()})
+	default:
+		failV2(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+	}
+	return false
+}
+
+// jsonPath — путь поля в JSON по пути validator: "CheckPaymentRequest.MinAmountTon" -> "min_amount_ton",
+// "MerchantRequest.Wallets[0]" -> "wallets[0]".
+func jsonPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:]
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, index, indexed := strings.Cut(part, "[")
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		f, ok := reflect.StructField{}, false
+		if t.Kind() == reflect.Struct {
+			f, ok = t.FieldByName(name)
+		}
+		if !ok {
+			out = append(out, part)
+			continue
+		}
+		if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" && tag != "-" {
+			name = tag
+		}
+		if indexed {
+			name += "[" + index
+		}
+		out = append(out, name)
+		t = f.Type
+	}
+	return strings.Join(out, ".")
+}
+
+// CheckPaymentV2 — POST /api/v2/check-payment: платёж не найден — 200 с paid=false, а не неуспех запроса.
+func (h *PaymentHandler) CheckPaymentV2(c *gin.Context) {
+	var req models.CheckPaymentRequest
+	if !bindV2(c, &req) || !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	paid, err := h.tonService.CheckPayment(ctx, req)
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, "Error checking payment: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, models.PaymentCheckResult{Paid: paid})
+}
+
+func (h *PaymentHandler) ValidatePaymentV2(c *gin.Context) {
+	var req models.PaymentValidationRequest
+	if !bindV2(c, &req) || !middleware.AllowWallet(c, req.WalletAddress) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	result, err := h.tonService.ValidateTransaction(ctx, req)
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, "Validation failed: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, *result)
+}
+
+func (h *PaymentHandler) GetAccountInfoV2(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	info, err := h.tonService.GetAccountInfo(ctx, c.Param("account"))
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, "Failed to get account info: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, *info)
+}
+
+// GetTransactionHistoryV2 — GET /api/v2/transactions/:account: data — массив, limit и количество — в meta.pagination.
+func (h *PaymentHandler) GetTransactionHistoryV2(c *gin.Context) {
+	limit, filter, err := historyQuery(c)
+	if err != nil {
+		failV2(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	transactions, err := h.tonService.GetTransactionHistory(ctx, c.Param("account"), limit, filter)
+	if errors.Is(err, services.ErrBadFilter) {
+		failV2(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, "Failed to get transaction history: "+err.Error())
+		return
+	}
+	if transactions == nil {
+		transactions = []models.TransactionInfo{}
+	}
+	c.JSON(http.StatusOK, models.V2Response[[]models.TransactionInfo]{
+		Data: transactions,
+		Meta: models.V2Meta{RequestID: middleware.RequestID(c), Pagination: &models.V2Pagination{Limit: limit, Count: len(transactions)}},
+	})
+}
+
+func (h *PaymentHandler) GetBalanceV2(c *gin.Context) {
+	accountID := c.Param("account")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	balance, err := h.tonService.GetWalletBalance(ctx, accountID)
+	if err != nil {
+		failV2(c, http.StatusInternalServerError, "Failed to get balance: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, models.WalletBalance{Address: accountID, Balance: balance, Unit: "TON"})
+}
+
+func (h *PaymentHandler) CreatePaymentLinkV2(c *gin.Context) {
+	var req models.PaymentLinkRequest
+	if !bindV2(c, &req) || !middleware.AllowWallet(c, req.MerchantAddress) {
+		return
+	}
+
+	link, err := services.BuildPaymentLink(req)
+	if err != nil {
+		failV2(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if link.QRPNG, err = services.QRDataURI(link.TonURI, 256); err != nil {
+		failV2(c, http.StatusInternalServerError, "Failed to render QR code: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, *link)
+}
+
+func (h *InvoiceHandler) CreateInvoiceV2(c *gin.Context) {
+	var req models.CreateInvoiceRequest
+	if !bindV2(c, &req) || !middleware.AllowWallet(c, h.invoiceWallet(c, &req)) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	inv, err := h.invoices.Create(ctx, req)
+	if err != nil {
+		failV2(c, createInvoiceStatus(err), "Failed to create invoice: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusCreated, *inv)
+}
+
+func (h *InvoiceHandler) GetInvoiceV2(c *gin.Context) {
+	inv, err := h.invoices.Get(c.Param("id"))
+	if err != nil {
+		failV2(c, getInvoiceStatus(err), "Failed to get invoice: "+err.Error())
+		return
+	}
+	if !middleware.AllowWallet(c, inv.MerchantAddress) {
+		return
+	}
+	respondV2(c, http.StatusOK, *inv)
+}
+
+func (h *InvoiceHandler) GetRateV2(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Get().RequestTimeout)
+	defer cancel()
+
+	currency := strings.ToUpper(c.Param("currency"))
+	price, err := h.rates.TonPrice(ctx, currency)
+	if err != nil {
+		failV2(c, http.StatusBadGateway, "Failed to get rate: "+err.Error())
+		return
+	}
+	respondV2(c, http.StatusOK, models.Rate{Currency: currency, TonPrice: price.String(), Source: h.rates.Name(), At: time.Now().UTC()})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"payment-service/config"
+	"payment-service/middleware"
+	"payment-service/services"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 64 * 1024
+	// как часто переоценивать доступ: ключ мерчанта могли выпустить или отозвать без перезагрузки конфигурации
+	wsAuthRecheck = 15 * time.Second
+)
+
+// Сообщение клиента: auth / subscribe / unsubscribe / ping.
+type wsClientMessage struct {
+	Action     string   `json:"action"`
+	Token      string   `json:"token,omitempty"`
+	Wallets    []string `json:"wallets,omitempty"`
+	InvoiceIDs []string `json:"invoice_ids,omitempty"`
+}
+
+// Служебный ответ сервера (события хаба отправляются как services.HubEvent).
+type wsReply struct {
+	Type    string   `json:"type"` // "ack" | "error" | "pong"
+	Action  string   `json:"action,omitempty"`
+	Message string   `json:"message,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
+}
+
+type WSHandler struct {
+	hub      *services.Hub
+	auth     *middleware.Authenticator
+	config   *config.Live
+	upgrader websocket.Upgrader
+
+	mu sync.Mutex
+	// reloaded закрывается и заменяется новым после каждой перезагрузки конфигурации
+	reloaded chan struct{}
+}
+
+func NewWSHandler(hub *services.Hub, auth *middleware.Authenticator, cfg *config.Live) *WSHandler {
+	h := &WSHandler{
+		hub:      hub,
+		auth:     auth,
+		config:   cfg,
+		reloaded: make(chan struct{}),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// CORS у нас открытый, а доступ закрыт ключом в auth-сообщении
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+	cfg.OnChange(func(_, _ *config.Config) {
+		h.mu.Lock()
+		close(h.reloaded)
+		h.reloaded = make(chan struct{})
+		h.mu.Unlock()
+	})
+	return h
+}
+
+func (h *WSHandler) reloadSignal() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.reloaded
+}
+
+// wsAuth — чем аутентифицировано соединение; ключ перепроверяется при смене конфигурации.
+// Проверка и Restrict выполняются под mu, чтобы повторная проверка не затёрла свежий auth.
+type wsAuth struct {
+	mu    sync.Mutex
+	token string
+	ok    bool
+}
+
+func (a *wsAuth) authed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ok
+}
+
+// Serve — GET /api/ws. Клиент аутентифицируется ключом (заголовок/?token= или
+// первым сообщением {"action":"auth"}), затем подписывается на кошельки и инвойсы.
+// Мерчант может подписаться только на свои кошельки. Доступ переоценивается после
+// перезагрузки конфигурации и периодически: отозванный ключ или закрывшийся анонимный
+// доступ разрывают соединение, а ограничение по кошелькам обновляется.
+func (h *WSHandler) Serve(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(h.config.Get().WSSendBuffer)
+	defer h.hub.Unsubscribe(sub)
+
+	state := &wsAuth{token: middleware.RequestToken(c)}
+	state.ok = h.authorize(sub, middleware.GetPrincipal(c))
+	replies := make(chan wsReply, 8)
+	readerDone := make(chan struct{})
+
+	conn.SetReadLimit(wsMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error { return conn.SetReadDeadline(time.Now().Add(wsPongWait)) })
+
+	go func() {
+		defer close(readerDone)
+		for {
+			var msg wsClientMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			var r wsReply
+			switch {
+			case msg.Action == "ping":
+				r = wsReply{Type: "pong"}
+			case msg.Action == "auth":
+				state.mu.Lock()
+				state.token = msg.Token
+				state.ok = h.authorize(sub, h.auth.Resolve(msg.Token))
+				ok := state.ok
+				state.mu.Unlock()
+				if ok {
+					r = wsReply{Type: "ack", Action: "auth"}
+				} else {
+					r = wsReply{Type: "error", Action: "auth", Message: "invalid token"}
+				}
+			case !state.authed():
+				r = wsReply{Type: "error", Action: msg.Action, Message: "unauthorized"}
+			case msg.Action == "subscribe":
+				r = wsReply{Type: "ack", Action: "subscribe", Denied: sub.Watch(msg.Wallets, msg.InvoiceIDs)}
+			case msg.Action == "unsubscribe":
+				sub.Unwatch(msg.Wallets, msg.InvoiceIDs)
+				r = wsReply{Type: "ack", Action: "unsubscribe"}
+			default:
+				r = wsReply{Type: "error", Action: msg.Action, Message: "unknown action"}
+			}
+			select {
+			case replies <- r:
+			default:
+				// клиент шлёт команды быстрее, чем читает ответы
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	recheck := time.NewTicker(wsAuthRecheck)
+	defer recheck.Stop()
+	reloaded := h.reloadSignal()
+
+	for {
+		select {
+		case <-readerDone:
+			return
+		case <-sub.Done():
+			// хаб отключил нас как медленного клиента или сервер останавливается
+			code, reason := websocket.CloseTryAgainLater, "slow consumer"
+			if h.hub.Closed() {
+				code, reason = websocket.CloseGoingAway, "server shutting down"
+			}
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, reason),
+				time.Now().Add(wsWriteWait))
+			return
+		case r := <-replies:
+			if err := h.write(conn, r); err != nil {
+				return
+			}
+		case ev := <-sub.C():
+			if err := h.write(conn, ev); err != nil {
+				return
+			}
+		case <-reloaded:
+			reloaded = h.reloadSignal()
+			if !h.reauthorize(conn, sub, state) {
+				return
+			}
+		case <-recheck.C:
+			if !h.reauthorize(conn, sub, state) {
+				return
+			}
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// authorize — пускает ли principal в поток; для мерчанта/сессии ограничивает кошельки.
+func (h *WSHandler) authorize(sub *services.Subscriber, p middleware.Principal) bool {
+	switch p.Kind {
+	case middleware.PrincipalClient, middleware.PrincipalAdmin:
+		sub.Restrict(nil)
+		return true
+	case middleware.PrincipalMerchant:
+		sub.Restrict(p.Merchant.Wallets)
+		return true
+	case middleware.PrincipalSession:
+		sub.Restrict([]string{p.Wallet})
+		return true
+	}
+	// анонимно — только если аутентификация не настроена вовсе
+	return h.auth.AnonymousAllowed()
+}
+
+// reauthorize — повторная проверка уже пущенного соединения тем же ключом; false — соединение
+// закрыто, потому что доступа больше нет. Неаутентифицированное соединение не трогаем:
+// оно по-прежнему может прислать {"action":"auth"}.
+func (h *WSHandler) reauthorize(conn *websocket.Conn, sub *services.Subscriber, state *wsAuth) bool {
+	state.mu.Lock()
+	lost := state.ok && !h.authorize(sub, h.auth.Resolve(state.token))
+	if lost {
+		state.ok = false
+	}
+	state.mu.Unlock()
+	if !lost {
+		return true
+	}
+	_ = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"),
+		time.Now().Add(wsWriteWait))
+	return false
+}
+
+func (h *WSHandler) write(conn *websocket.Conn, v any) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(v)
+}
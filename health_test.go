@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"payment-service/handlers"
+	"payment-service/services"
+)
+
+func TestProbes_DependencyFailureFailsReadinessNotLiveness(t *testing.T) {
+	a := testApp(t)
+	a.health.Add(services.HealthCheck{Name: "disk", Group: "disk", Check: func(context.Context) error { return errors.New("read-only file system") }})
+	spec := handlers.OpenAPISpec()
+
+	for _, tc := range []struct {
+		path string
+		want int
+		body string
+	}{
+		{"/livez", 200, `"alive"`},
+		{"/readyz", 503, "disk: read-only file system"},
+		{"/api/health", 503, `"status":"down"`},
+		{"/api/v2/health", 503, `"field":"disk"`},
+	} {
+		rec := httptest.NewRecorder()
+		a.router.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		if rec.Code != tc.want || !strings.Contains(rec.Body.String(), tc.body) { t.Errorf("%s: %d %s", tc.path, rec.Code, rec.Body) }
+		if err := spec.ValidateResponse("GET", tc.path, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()); err != nil { t.Error(err) }
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"payment-service/config"
+	"payment-service/models"
+	"payment-service/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func idempotentRequest(a *app, path, token, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func invoiceID(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct{ Data models.Invoice }
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Data.ID == "" { t.Fatalf("invoice response: %d %s", rec.Code, rec.Body) }
+	return resp.Data.ID
+}
+
+// Idempotency-Key на маршрутах: повтор, другое тело, 5xx, разделение по мерчантам и анонимные запросы.
+func TestIdempotency_RouterBehaviour(t *testing.T) {
+	var failing atomic.Bool
+	fake, _ := url.Parse(fakeTonAPI(t).URL)
+	proxy := httputil.NewSingleHostReverseProxy(fake)
+	tonapi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "bad", http.StatusBadRequest)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(tonapi.Close)
+
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TON_API_URL", tonapi.URL)
+	t.Setenv("APP_WALLET", testWallet)
+	t.Setenv("ADMIN_API_KEYS", adminKey)
+	t.Setenv("EXPORT_DIR", t.TempDir())
+	cfg, err := config.Load("")
+	if err != nil { t.Fatalf("config: %v", err) }
+	a, err := newApp(config.Static(cfg), storage.NewMemory())
+	if err != nil { t.Fatalf("app: %v", err) }
+
+	// анонимные запросы не делят один ключ на всех
+	first, second := idempotentRequest(a, "/api/invoices", "", "anon", `{"amount_ton":"1"}`), idempotentRequest(a, "/api/invoices", "", "anon", `{"amount_ton":"1"}`)
+	if second.Header().Get("Idempotent-Replayed") != "" || invoiceID(t, first) == invoiceID(t, second) { t.Fatalf("anonymous request replayed: %s", second.Body) }
+
+	keys := map[string]string{}
+	for name, wallet := range map[string]string{"a": testWallet, "b": "0:0000000000000000000000000000000000000000000000000000000000000001"} {
+		rec := doRequest(a, "POST", "/api/admin/merchants", adminKey, `{"name":"`+name+`","wallets":["`+wallet+`"]}`)
+		var created struct{ Data models.MerchantCreated }
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); rec.Code != 201 || err != nil || created.Data.APIKey == nil { t.Fatalf("create merchant: %d %s", rec.Code, rec.Body) }
+		keys[name] = created.Data.APIKey.Key
+	}
+
+	body := `{"amount_ton":"1","comment":"K-1"}`
+	created := idempotentRequest(a, "/api/invoices", keys["a"], "k1", body)
+	replay := idempotentRequest(a, "/api/invoices", keys["a"], "k1", body)
+	if created.Code != 201 || created.Header().Get("Idempotent-Replayed") != "" { t.Fatalf("create: %d %s", created.Code, created.Body) }
+	if replay.Code != 201 || replay.Header().Get("Idempotent-Replayed") != "true" || replay.Body.String() != created.Body.String() { t.Fatalf("replay: %d %v %s", replay.Code, replay.Header(), replay.Body) }
+	if rec := idempotentRequest(a, "/api/invoices", keys["a"], "k1", `{"amount_ton":"2","comment":"K-1"}`); rec.Code != 422 { t.Fatalf("changed body: %d %s", rec.Code, rec.Body) }
+
+	other := idempotentRequest(a, "/api/invoices", keys["b"], "k1", body)
+	if other.Code != 201 || other.Header().Get("Idempotent-Replayed") != "" || invoiceID(t, other) == invoiceID(t, created) { t.Fatalf("other merchant got a replay: %d %s", other.Code, other.Body) }
+
+	check := `{"merchant_address":"` + testWallet + `","comment":"hi","min_amount_ton":"1"}`
+	failing.Store(true)
+	if rec := idempotentRequest(a, "/api/check-payment", keys["a"], "k2", check); rec.Code != 500 { t.Fatalf("upstream failure: %d %s", rec.Code, rec.Body) }
+	failing.Store(false)
+	if rec := idempotentRequest(a, "/api/check-payment", keys["a"], "k2", check); rec.Code != 200 || rec.Header().Get("Idempotent-Replayed") != "" { t.Fatalf("5xx was stored: %d %v %s", rec.Code, rec.Header(), rec.Body) }
+}
@@ -0,0 +1,217 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/storage"
+)
+
+const entriesCollection = "ledger_entries"
+
+// Виды проводок.
+const (
+	KindPayment  = "payment"  // подтверждённая оплата инвойса
+	KindTransfer = "transfer" // перевод между своими кошельками (сметание депозита)
+	KindPayout   = "payout"   // выплата на внешний адрес
+	KindRefund   = "refund"   // возврат покупателю
+	KindOpening  = "opening"  // входящий остаток кошелька на начало учёта
+)
+
+const CurrencyTON = "TON"
+
+var (
+	ErrDuplicate  = errors.New("ledger entry already exists")
+	ErrUnbalanced = errors.New("ledger entry is not balanced")
+)
+
+// Счета. Адреса кошельков передаются уже нормализованными (см. services.LedgerWallet).
+func Wallet(addr string) string    { return "wallet:" + addr }    // кошелёк мерчанта/депозитный адрес
+func Customer(id string) string    { return "customer:" + id }    // расчёты с покупателем
+func Fees(wallet string) string    { return "fees:" + wallet }    // комиссии сети
+func Refunds(wallet string) string { return "refunds:" + wallet } // возвраты покупателям
+func External(addr string) string  { return "external:" + addr }  // получатель выплаты
+func Equity(wallet string) string  { return "equity:" + wallet }  // средства на кошельке до начала учёта
+
+// Line — одна сторона проводки: заполнен либо Debit, либо Credit.
+type Line struct {
+	Account  string          `json:"account"`
+	Currency string          `json:"currency"`
+	Debit    decimal.Decimal `json:"debit"`
+	Credit   decimal.Decimal `json:"credit"`
+}
+
+// Entry — проводка журнала. После записи не меняется и не удаляется;
+// исправления делаются новой (сторнирующей) проводкой.
+type Entry struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Reference string    `json:"reference"` // инвойс, hash транзакции, id выплаты
+	Memo      string    `json:"memo,omitempty"`
+	Time      time.Time `json:"time"`
+	Lines     []Line    `json:"lines"`
+}
+
+func Debit(account, currency string, amount decimal.Decimal) Line {
+	return Line{Account: account, Currency: currency, Debit: amount}
+}
+
+func Credit(account, currency string, amount decimal.Decimal) Line {
+	return Line{Account: account, Currency: currency, Credit: amount}
+}
+
+// Ledger — журнал двойной записи поверх storage.Store.
+type Ledger struct {
+	store *storage.Store
+	mu    sync.Mutex
+}
+
+func New(store *storage.Store) *Ledger { return &Ledger{store: store} }
+
+// Post — записывает проводку. ID = kind:reference, поэтому повторная проводка
+// по тому же событию не проходит (ErrDuplicate, возвращается существующая).
+func (l *Ledger) Post(e Entry) (*Entry, error) {
+	if e.Kind == "" || e.Reference == "" {
+		return nil, fmt.Errorf("ledger entry needs kind and reference")
+	}
+	for i := range e.Lines {
+		e.Lines[i].Currency = strings.ToUpper(e.Lines[i].Currency)
+	}
+	if err := validate(e); err != nil {
+		return nil, err
+	}
+	e.ID = e.Kind + ":" + e.Reference
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var existing Entry
+	if ok, err := l.store.Get(entriesCollection, e.ID, &existing); err != nil {
+		return nil, err
+	} else if ok {
+		return &existing, ErrDuplicate
+	}
+	if err := l.store.Put(entriesCollection, e.ID, e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (l *Ledger) Get(id string) (*Entry, bool, error) {
+	var e Entry
+	ok, err := l.store.Get(entriesCollection, id, &e)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return &e, true, nil
+}
+
+// Entries — проводки по счёту (пустой — все) в хронологическом порядке.
+func (l *Ledger) Entries(account string) ([]Entry, error) {
+	all, err := storage.List[Entry](l.store, entriesCollection)
+	if err != nil {
+		return nil, err
+	}
+	out := all[:0]
+	for _, e := range all {
+		if account == "" || e.touches(account) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Time.Equal(out[j].Time) {
+			return out[i].Time.Before(out[j].Time)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// Balance — дебет минус кредит по счёту в валюте.
+func (l *Ledger) Balance(account, currency string) (decimal.Decimal, error) {
+	all, err := l.Balances(account)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return all[strings.ToUpper(currency)], nil
+}
+
+// Balances — сальдо счёта по всем валютам.
+func (l *Ledger) Balances(account string) (map[string]decimal.Decimal, error) {
+	entries, err := l.Entries(account)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]decimal.Decimal)
+	for _, e := range entries {
+		for _, ln := range e.Lines {
+			if ln.Account == account {
+				out[ln.Currency] = out[ln.Currency].Add(ln.Debit).Sub(ln.Credit)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Check — инвариант журнала: в каждой проводке и в сумме по журналу
+// дебет равен кредиту в каждой валюте.
+func (l *Ledger) Check() error {
+	all, err := storage.List[Entry](l.store, entriesCollection)
+	if err != nil {
+		return err
+	}
+	total := make(map[string]decimal.Decimal)
+	for _, e := range all {
+		if err := validate(e); err != nil {
+			return fmt.Errorf("entry %s: %w", e.ID, err)
+		}
+		for _, ln := range e.Lines {
+			total[ln.Currency] = total[ln.Currency].Add(ln.Debit).Sub(ln.Credit)
+		}
+	}
+	for cur, diff := range total {
+		if !diff.IsZero() {
+			return fmt.Errorf("%w: journal %s differs by %s", ErrUnbalanced, cur, diff)
+		}
+	}
+	return nil
+}
+
+func validate(e Entry) error {
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("%w: at least two lines are required", ErrUnbalanced)
+	}
+	diff := make(map[string]decimal.Decimal)
+	for _, ln := range e.Lines {
+		if ln.Account == "" || ln.Currency == "" {
+			return fmt.Errorf("ledger line needs account and currency")
+		}
+		if ln.Debit.IsNegative() || ln.Credit.IsNegative() || ln.Debit.IsZero() == ln.Credit.IsZero() {
+			return fmt.Errorf("ledger line %s: exactly one of debit/credit must be positive", ln.Account)
+		}
+		diff[ln.Currency] = diff[ln.Currency].Add(ln.Debit).Sub(ln.Credit)
+	}
+	for cur, d := range diff {
+		if !d.IsZero() {
+			return fmt.Errorf("%w: %s differs by %s", ErrUnbalanced, cur, d)
+		}
+	}
+	return nil
+}
+
+func (e Entry) touches(account string) bool {
+	for _, ln := range e.Lines {
+		if ln.Account == account {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/storage"
+)
+
+func dec(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func TestLedger_PostingsAndBalances(t *testing.T) {
+	l := New(storage.NewMemory())
+	at := time.Unix(1700000000, 0)
+
+	if _, err := l.Post(Payment("inv_1", "0:aa", "cust", dec("5"), "ton", at)); err != nil { t.Fatalf("payment: %v", err) }
+	if _, err := l.Post(Payout("tx1", "0:aa", "0:bb", dec("2"), dec("0.01"), CurrencyTON, at.Add(time.Minute))); err != nil { t.Fatalf("payout: %v", err) }
+	if _, err := l.Post(Refund("tx2", "0:aa", dec("1"), decimal.Zero, CurrencyTON, at.Add(2*time.Minute))); err != nil { t.Fatalf("refund: %v", err) }
+
+	cases := map[string]string{
+		Wallet("0:aa"):   "1.99",
+		Customer("cust"): "-5",
+		External("0:bb"): "2",
+		Fees("0:aa"):     "0.01",
+		Refunds("0:aa"):  "1",
+	}
+	for account, want := range cases {
+		got, err := l.Balance(account, "TON")
+		if err != nil || !got.Equal(dec(want)) { t.Errorf("%s: got %s, want %s (%v)", account, got, want, err) }
+	}
+
+	entries, _ := l.Entries(Wallet("0:aa"))
+	if len(entries) != 3 || entries[0].ID != "payment:inv_1" || entries[2].ID != "refund:tx2" { t.Fatalf("entries: %+v", entries) }
+	if err := l.Check(); err != nil { t.Fatalf("check: %v", err) }
+}
+
+func TestLedger_RejectsUnbalancedAndDuplicates(t *testing.T) {
+	l := New(storage.NewMemory())
+	_, err := l.Post(Entry{Kind: KindPayout, Reference: "x", Lines: []Line{
+		Debit(External("0:bb"), CurrencyTON, dec("1")),
+		Credit(Wallet("0:aa"), CurrencyTON, dec("0.9")),
+	}})
+	if !errors.Is(err, ErrUnbalanced) { t.Fatalf("want ErrUnbalanced, got %v", err) }
+
+	e := Payment("inv_1", "0:aa", "cust", dec("1"), CurrencyTON, time.Now())
+	if _, err := l.Post(e); err != nil { t.Fatalf("post: %v", err) }
+	if _, err := l.Post(e); !errors.Is(err, ErrDuplicate) { t.Fatalf("want ErrDuplicate, got %v", err) }
+
+	// запись в обход Post (испорченный файл хранилища) ловится проверкой инварианта
+	bad := Entry{ID: "bad", Kind: KindPayment, Reference: "bad", Lines: []Line{Debit(Wallet("0:aa"), CurrencyTON, dec("1")), Credit(Customer("c"), CurrencyTON, dec("2"))}}
+	l.store.Put(entriesCollection, bad.ID, bad)
+	if err := l.Check(); !errors.Is(err, ErrUnbalanced) { t.Fatalf("check: %v", err) }
+}
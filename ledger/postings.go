@@ -0,0 +1,75 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Типовые проводки. Кошелёк — актив (дебетовое сальдо = деньги на кошельке).
+
+// Payment — оплата получена: Дт кошелёк получателя, Кт расчёты с покупателем.
+func Payment(reference, wallet, customer string, amount decimal.Decimal, currency string, at time.Time) Entry {
+	return Entry{
+		Kind:      KindPayment,
+		Reference: reference,
+		Time:      at,
+		Lines: []Line{
+			Debit(Wallet(wallet), currency, amount),
+			Credit(Customer(customer), currency, amount),
+		},
+	}
+}
+
+// Transfer — перевод между своими кошельками (например, депозит -> кошелёк мерчанта):
+// amount — сколько дошло до получателя, комиссия сети списывается с отправителя.
+func Transfer(reference, from, to string, amount, fee decimal.Decimal, currency string, at time.Time) Entry {
+	return withFee(Entry{
+		Kind:      KindTransfer,
+		Reference: reference,
+		Time:      at,
+		Lines:     []Line{Debit(Wallet(to), currency, amount)},
+	}, from, amount, fee, currency)
+}
+
+// Payout — выплата на внешний адрес; комиссия сети списывается с того же кошелька.
+func Payout(reference, wallet, dest string, amount, fee decimal.Decimal, currency string, at time.Time) Entry {
+	return withFee(Entry{
+		Kind:      KindPayout,
+		Reference: reference,
+		Time:      at,
+		Lines:     []Line{Debit(External(dest), currency, amount)},
+	}, wallet, amount, fee, currency)
+}
+
+// Refund — возврат покупателю: Дт возвраты мерчанта, Кт кошелёк.
+func Refund(reference, wallet string, amount, fee decimal.Decimal, currency string, at time.Time) Entry {
+	return withFee(Entry{
+		Kind:      KindRefund,
+		Reference: reference,
+		Time:      at,
+		Lines:     []Line{Debit(Refunds(wallet), currency, amount)},
+	}, wallet, amount, fee, currency)
+}
+
+// Opening — входящий остаток: Дт кошелёк, Кт собственные средства. С этой проводки
+// сальдо кошелька по журналу можно сравнивать с балансом в сети.
+func Opening(reference, wallet string, amount decimal.Decimal, currency string, at time.Time) Entry {
+	return Entry{
+		Kind:      KindOpening,
+		Reference: reference,
+		Time:      at,
+		Lines: []Line{
+			Debit(Wallet(wallet), currency, amount),
+			Credit(Equity(wallet), currency, amount),
+		},
+	}
+}
+
+func withFee(e Entry, wallet string, amount, fee decimal.Decimal, currency string) Entry {
+	if fee.IsPositive() {
+		e.Lines = append(e.Lines, Debit(Fees(wallet), currency, fee))
+	}
+	e.Lines = append(e.Lines, Credit(Wallet(wallet), currency, amount.Add(fee)))
+	return e
+}
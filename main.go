@@ -1,45 +1,85 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
-	"payment-service/config"
-	"payment-service/handlers"
-	"payment-service/middleware"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/gin-gonic/gin"
+	"payment-service/config"
+	"payment-service/storage"
+	"payment-service/tracing"
 )
 
 func main() {
-	// Загрузка конфигурации
-	cfg := config.LoadConfig()
+	// Загрузка конфигурации: файл (--config или CONFIG_FILE) под переменными окружения
+	configPath := flag.String("config", "", "YAML/TOML config file (default: $CONFIG_FILE)")
+	printOnly := flag.Bool("print-config", false, "print effective configuration with secrets redacted and exit")
+	flag.Parse()
+	cfg, err := config.Load(*configPath)
+	if *printOnly {
+		os.Exit(printConfig(os.Stdout, cfg, err))
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
-	// Инициализация обработчиков
-	paymentHandler, err := handlers.NewPaymentHandler(cfg)
+	// Снимок конфигурации, перезагружаемый по SIGHUP и при изменении файла
+	live := config.NewLive(*configPath, cfg)
+
+	// Трассировка: экспортёр OTEL_TRACES_EXPORTER, спаны выгружаются при остановке
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg, buildInfo().Version)
 	if err != nil {
-		log.Fatalf("Failed to create payment handler: %v", err)
+		log.Fatalf("%v", err)
 	}
 
-	// Настройка роутера
-	router := gin.Default()
+	// Хранилище
+	store, err := storage.Open(cfg.StoragePath)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
 
-	// Middleware
-	router.Use(middleware.CORS())
-	router.Use(middleware.Logger())
+	// Сервисы, обработчики и маршруты
+	a, err := newApp(live, store)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
-	// Маршруты
-	api := router.Group("/api")
-	{
-		api.POST("/check-payment", paymentHandler.CheckPayment)
-		api.POST("/validate-payment", paymentHandler.ValidatePayment)
-		api.GET("/account-info/:account", paymentHandler.GetAccountInfo)
-		api.GET("/transactions/:account", paymentHandler.GetTransactionHistory)
-		api.GET("/balance/:account", paymentHandler.GetBalance)
-		api.GET("/health", paymentHandler.HealthCheck)
+	// Сверка с блокчейном: `payment-service reconcile` — разовый отчёт, иначе — периодически
+	if flag.Arg(0) == "reconcile" {
+		os.Exit(runReconcile(a.reconciler, a.wallets()))
 	}
+	bg := newBackground()
+	a.runBackground(bg)
 
-	// Запуск сервера
-	log.Printf("Server starting on port %s", cfg.ServerPort)
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
+	// Запуск сервера; SIGINT/SIGTERM — плавная остановка
+	srv := newHTTPServer(cfg, a.router)
+	srv.RegisterOnShutdown(a.hub.Close) // WebSocket-клиенты получают close "going away"
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	if a.grpc != nil {
+		gln, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+		log.Printf("gRPC server starting on port %s", cfg.GRPCPort)
+		bg.Go(func(ctx context.Context) { serveGRPC(ctx, a.grpc, gln, cfg.ShutdownTimeout) })
+	}
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	log.Printf("Server starting on port %s", cfg.ServerPort)
+	err = serve(ctx, srv, ln, cfg.ShutdownTimeout, func(ctx context.Context) error {
+		return errors.Join(bg.Stop(ctx), a.exports.Wait(ctx), store.Flush(), shutdownTracing(ctx))
+	})
+	if err != nil {
+		log.Fatalf("Server stopped with error: %v", err)
+	}
+	log.Printf("Server stopped")
 }
@@ -1,9 +1,16 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"log"
+	"net/http"
+	"strings"
 	"time"
 
+	"payment-service/config"
+	"payment-service/models"
+	"payment-service/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,8 +18,8 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, Idempotency-Key, traceparent, tracestate, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -31,3 +38,212 @@ func Logger() gin.HandlerFunc {
 		log.Printf("Request: %s %s %d %v", c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
 	}
 }
+
+// TokenAllowed — проверка клиентского ключа (сравнение за постоянное время).
+// Пустой список пропускает любой токен: вызывающий сам проверяет, заданы ли ключи.
+func TokenAllowed(keys []string, token string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	ok := false
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(token)) == 1 {
+			ok = true
+		}
+	}
+	return ok
+}
+
+// RequestToken — ключ клиента из "Authorization: Bearer ...", "X-API-Key" или ?token=.
+func RequestToken(c *gin.Context) string {
+	if h := c.GetHeader("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	}
+	if k := c.GetHeader("X-API-Key"); k != "" {
+		return k
+	}
+	return c.Query("token")
+}
+
+func APIKeyAuth(keys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !TokenAllowed(keys, RequestToken(c)) {
+			Abort(c, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Next()
+	}
+}
+
+// Кто выполняет запрос.
+const (
+	PrincipalAnonymous = ""
+	PrincipalClient    = "client"   // общий ключ CLIENT_API_KEYS — без ограничений по кошелькам
+	PrincipalMerchant  = "merchant" // ключ мерчанта — только кошельки этого мерчанта
+	PrincipalSession   = "session"  // сессия ton_proof — только подтверждённый кошелёк
+	PrincipalAdmin     = "admin"    // ключ ADMIN_API_KEYS — без ограничений по кошелькам
+)
+
+const (
+	principalKey     = "principal"
+	authenticatorKey = "authenticator"
+)
+
+type Principal struct {
+	Kind     string
+	Merchant *models.Merchant
+	Wallet   string
+}
+
+func (p Principal) CanAccessWallet(wallet string) bool {
+	switch p.Kind {
+	case PrincipalClient, PrincipalAdmin:
+		return true
+	case PrincipalMerchant:
+		return services.OwnsWallet(p.Merchant, wallet)
+	case PrincipalSession:
+		return services.SameAddress(p.Wallet, wallet)
+	}
+	return false
+}
+
+// Authenticator — определяет Principal по токену запроса и проверяет доступ к кошелькам.
+// CLIENT_API_KEYS и AUTH_REQUIRED читаются из текущего снимка конфигурации, поэтому
+// ротация ключей применяется без перезапуска. Анонимные запросы проходят, только пока
+// аутентификация не настроена вовсе (см. AnonymousAllowed).
+type Authenticator struct {
+	config    *config.Live
+	merchants *services.MerchantService
+	proofs    *services.TonProofService
+}
+
+func NewAuthenticator(cfg *config.Live, merchants *services.MerchantService, proofs *services.TonProofService) *Authenticator {
+	return &Authenticator{config: cfg, merchants: merchants, proofs: proofs}
+}
+
+func (a *Authenticator) Resolve(token string) Principal {
+	if token == "" {
+		return Principal{}
+	}
+	if keys := a.config.Get().ClientAPIKeys; len(keys) > 0 && TokenAllowed(keys, token) {
+		return Principal{Kind: PrincipalClient}
+	}
+	if keys := a.config.Get().AdminAPIKeys; len(keys) > 0 && TokenAllowed(keys, token) {
+		return Principal{Kind: PrincipalAdmin}
+	}
+	if a.merchants != nil {
+		if m := a.merchants.Authenticate(token); m != nil {
+			return Principal{Kind: PrincipalMerchant, Merchant: m}
+		}
+	}
+	if a.proofs != nil {
+		if wallet, err := a.proofs.SessionWallet(token); err == nil {
+			return Principal{Kind: PrincipalSession, Wallet: wallet}
+		}
+	}
+	return Principal{}
+}
+
+// AnonymousAllowed — анонимный доступ открыт, только если AUTH_REQUIRED=false, CLIENT_API_KEYS
+// пуст и ни одному мерчанту не выпущен ключ. Как только ключи появились, доступ к кошелькам —
+// только по ключу или сессии.
+func (a *Authenticator) AnonymousAllowed() bool {
+	cfg := a.config.Get()
+	if cfg.AuthRequired || len(cfg.ClientAPIKeys) > 0 {
+		return false
+	}
+	return a.merchants == nil || !a.merchants.HasAPIKeys()
+}
+
+// Allow — может ли principal работать с кошельком.
+func (a *Authenticator) Allow(p Principal, wallet string) bool {
+	if p.Kind == PrincipalAnonymous {
+		return a.AnonymousAllowed()
+	}
+	return p.CanAccessWallet(wallet)
+}
+
+// Middleware — кладёт Principal в контекст; сам по себе запрос не отклоняет.
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(principalKey, a.Resolve(RequestToken(c)))
+		c.Set(authenticatorKey, a)
+		c.Next()
+	}
+}
+
+// RequireOperator — действия оператора (разбор платежей, проводки): нужен ключ клиента,
+// мерчанта или администратора; анонимный вызов и сессия ton_proof не проходят.
+func (a *Authenticator) RequireOperator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch GetPrincipal(c).Kind {
+		case PrincipalClient, PrincipalMerchant, PrincipalAdmin:
+			c.Next()
+		case PrincipalAnonymous:
+			Abort(c, http.StatusUnauthorized, "Unauthorized")
+		default:
+			Abort(c, http.StatusForbidden, "Operator API key required")
+		}
+	}
+}
+
+// WalletParam — доступ к кошельку из параметра пути (например, :account).
+func (a *Authenticator) WalletParam(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !AllowWallet(c, c.Param(param)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+func GetPrincipal(c *gin.Context) Principal {
+	if v, ok := c.Get(principalKey); ok {
+		return v.(Principal)
+	}
+	return Principal{}
+}
+
+// AllowWallet — проверка доступа к кошельку внутри хендлера; при отказе пишет 401/403.
+func AllowWallet(c *gin.Context, wallet string) bool {
+	v, ok := c.Get(authenticatorKey)
+	if !ok {
+		return true
+	}
+	p := GetPrincipal(c)
+	if v.(*Authenticator).Allow(p, wallet) {
+		return true
+	}
+	if p.Kind == PrincipalAnonymous {
+		Abort(c, http.StatusUnauthorized, "Unauthorized")
+	} else {
+		Abort(c, http.StatusForbidden, "Access to wallet "+wallet+" is not allowed")
+	}
+	return false
+}
+
+// AdminAuth — доступ к админским эндпоинтам. Без ADMIN_API_KEYS они выключены;
+// ключи берутся из текущего снимка конфигурации.
+func AdminAuth(cfg *config.Live) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminKeys := cfg.Get().AdminAPIKeys
+		if len(adminKeys) == 0 {
+			Abort(c, http.StatusForbidden, "Admin API is disabled")
+			return
+		}
+		if !TokenAllowed(adminKeys, RequestToken(c)) {
+			Abort(c, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Next()
+	}
+}
+
+// WalletVisible — как AllowWallet, но без ответа клиенту: для фильтрации списков.
+func WalletVisible(c *gin.Context, wallet string) bool {
+	v, ok := c.Get(authenticatorKey)
+	if !ok {
+		return true
+	}
+	return v.(*Authenticator).Allow(GetPrincipal(c), wallet)
+}
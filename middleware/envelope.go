@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"payment-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+	requestIDKey    = "request_id"
+	apiV2Key        = "api_v2"
+)
+
+// APIv2 — для группы /api/v2: X-Request-ID (от клиента или новый) в контексте и ответе,
+// ошибки middleware (auth, Idempotency-Key) — в формате models.V2ErrorResponse.
+// Должна стоять первой в группе.
+func APIv2() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			b := make([]byte, 16)
+			_, _ = rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		c.Set(requestIDKey, id)
+		c.Set(apiV2Key, true)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Abort — ответ middleware об ошибке: models.Response в v1, models.V2ErrorResponse в /api/v2.
+func Abort(c *gin.Context, status int, message string) {
+	if c.GetBool(apiV2Key) {
+		c.AbortWithStatusJSON(status, V2Error(c, status, message))
+		return
+	}
+	c.AbortWithStatusJSON(status, models.Response{Success: false, Message: message})
+}
+
+// V2Error — тело ошибки API v2; код ошибки определяется HTTP-статусом.
+func V2Error(c *gin.Context, status int, message string, details ...models.V2ErrorDetail) models.V2ErrorResponse {
+	return models.V2ErrorResponse{
+		Error: models.V2Error{Code: ErrorCode(status), Message: message, Details: details},
+		Meta:  models.V2Meta{RequestID: RequestID(c)},
+	}
+}
+
+// ErrorCode — машиночитаемый код ошибки API v2 по HTTP-статусу.
+func ErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "payload_too_large"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable"
+	case http.StatusNotImplemented:
+		return "not_implemented"
+	case http.StatusBadGateway:
+		return "upstream_error"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	}
+	return "internal"
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"payment-service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	maxIdempotentBody    = 1 << 20
+)
+
+// Idempotency — для POST/PUT/PATCH/DELETE с заголовком Idempotency-Key первый ответ
+// сохраняется и отдаётся на повторы (с заголовком Idempotent-Replayed: true).
+// Тот же ключ с другим телом — 422, пока первый запрос выполняется — 409.
+// Ключи разделены по мерчанту (или кошельку сессии / токену), поэтому должна стоять после auth.
+// Анонимные запросы не кешируются: общий для всех анонимов ключ отдал бы чужой ответ.
+func Idempotency(svc *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		scope := idempotencyScope(c)
+		if scope == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			Abort(c, http.StatusBadRequest, "Invalid request: Idempotency-Key is too long")
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBody+1))
+		if err != nil || len(body) > maxIdempotentBody {
+			Abort(c, http.StatusRequestEntityTooLarge, "Invalid request: body is too large for an idempotent request")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"\n"), body...))
+		rec, err := svc.Begin(scope, key, hex.EncodeToString(sum[:]))
+		switch {
+		case errors.Is(err, services.ErrIdempotencyMismatch):
+			Abort(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, services.ErrIdempotencyInFlight):
+			Abort(c, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			Abort(c, http.StatusInternalServerError, "Idempotency check failed: "+err.Error())
+			return
+		case rec != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(rec.Status, rec.ContentType, rec.Body)
+			c.Abort()
+			return
+		}
+
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			if !completed {
+				svc.Release(scope, key)
+			}
+		}()
+		c.Next()
+
+		// ошибки сервера не запоминаем: повтор с тем же ключом выполнится заново
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		if err := svc.Complete(scope, key, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+			log.Printf("idempotency: save %s: %v", key, err)
+		}
+		completed = true
+	}
+}
+
+// idempotencyScope — чьи ключи: мерчанта, кошелька сессии, общего клиента или владельца токена (admin);
+// "" — анонимный запрос.
+func idempotencyScope(c *gin.Context) string {
+	p := GetPrincipal(c)
+	switch p.Kind {
+	case PrincipalMerchant:
+		return "merchant:" + p.Merchant.ID
+	case PrincipalSession:
+		return "wallet:" + services.LedgerWallet(p.Wallet)
+	case PrincipalClient:
+		return "client"
+	case PrincipalAdmin:
+		return "admin"
+	}
+	if token := RequestToken(c); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:8])
+	}
+	return ""
+}
+
+// capturingWriter — копия тела ответа для сохранения.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
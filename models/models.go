@@ -3,7 +3,7 @@ package models
 import "time"
 
 type Response = APIResponse
-type PaymentValidationRequest = ValidateTxRequest
+type PaymentValidationRequest = PaymentCheckByTxRequest
 
 type TransactionInfo struct {
 	Hash      string    `json:"hash"`
@@ -14,20 +14,98 @@ type TransactionInfo struct {
 	Timestamp time.Time `json:"timestamp"`
 	Comment   string    `json:"comment,omitempty"`
 	Currency  string    `json:"currency,omitempty"`
+	Direction string    `json:"direction,omitempty"` // "in" | "out" относительно запрошенного аккаунта
+	Kind      string    `json:"kind"`               // TxKind*
+	Details   *TransactionDetails `json:"details,omitempty"`
 }
 
-// Если где-то нужна проверка по txHash (не для нашего сервиса)
+// Типы операций в истории.
+const (
+	TxKindTonTransfer    = "ton_transfer"
+	TxKindJettonTransfer = "jetton_transfer"
+	TxKindNftTransfer    = "nft_transfer"
+	TxKindSwap           = "swap"
+	TxKindContractDeploy = "contract_deploy"
+	TxKindContractCall   = "contract_call"
+	TxKindOther          = "other"
+)
+
+// Статусы операций: failed — транзакция прервана, bounced — перевод вернулся отправителю.
+const (
+	TxStatusOK      = "ok"
+	TxStatusFailed  = "failed"
+	TxStatusBounced = "bounced"
+)
+
+// Результат проверки транзакции по hash (POST /api/validate-payment).
+type TransactionValidation struct {
+	Valid      bool             `json:"valid"`
+	Status     string           `json:"status,omitempty"` // TxStatus*; пусто — событие не найдено
+	Transfer   *TransactionInfo `json:"transfer,omitempty"`
+	Mismatches []FieldMismatch  `json:"mismatches,omitempty"`
+}
+
+// Расхождение ожидаемого значения с фактическим в транзакции.
+type FieldMismatch struct {
+	Field    string `json:"field"` // sender_address | amount | currency | comment | wallet_address
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// TxKinds — все допустимые значения ?types=.
+var TxKinds = []string{TxKindTonTransfer, TxKindJettonTransfer, TxKindNftTransfer, TxKindSwap, TxKindContractDeploy, TxKindContractCall, TxKindOther}
+
+// Детали операции по типу; суммы джеттонов — с учётом decimals.
+type TransactionDetails struct {
+	Jetton      *JettonInfo `json:"jetton,omitempty"`
+	NFT         string      `json:"nft,omitempty"`
+	Dex         string      `json:"dex,omitempty"`
+	AssetIn     string      `json:"asset_in,omitempty"` // символ джеттона или "TON"
+	AmountIn    string      `json:"amount_in,omitempty"`
+	AssetOut    string      `json:"asset_out,omitempty"`
+	AmountOut   string      `json:"amount_out,omitempty"`
+	Contract    string      `json:"contract,omitempty"`
+	Interfaces  []string    `json:"interfaces,omitempty"`
+	Operation   string      `json:"operation,omitempty"`
+	ActionType  string      `json:"action_type,omitempty"` // исходный тип TonAPI для kind=other
+	Description string      `json:"description,omitempty"`
+}
+
+type JettonInfo struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Decimals int    `json:"decimals"`
+}
+
+// Фильтры истории переводов; пустые поля не ограничивают выборку.
+// Границы суммы заданы в TON: строки в джеттонах и других валютах с ними не сравниваются и отбрасываются.
+type TransactionFilter struct {
+	From         time.Time // включительно
+	To           time.Time // не включительно
+	Direction    string    // "in" | "out"
+	MinAmount    string    // TON
+	MaxAmount    string    // TON
+	Counterparty string    // отправитель для входящих, получатель для исходящих
+	Comment      string
+	CommentMatch string // "exact" (по умолчанию) | "prefix"
+	Types        []string // TxKind*; пусто — все типы
+}
+
+// Проверка платежа по txHash; ожидаемые sender/amount/currency/comment необязательны
+// и сверяются, только если заданы.
 type PaymentCheckByTxRequest struct {
 	WalletAddress string `json:"wallet_address" binding:"required"`
 	TxHash        string `json:"tx_hash" binding:"required"`
-	SenderAddress string `json:"sender_address" binding:"required"`
-	Amount        string `json:"amount" binding:"required"`
-	Currency      string `json:"currency,omitempty"`
+	SenderAddress string `json:"sender_address,omitempty"`
+	Amount        string `json:"amount,omitempty" binding:"omitempty,numeric"` // в единицах currency, например "1.5"
+	Currency      string `json:"currency,omitempty"`                           // "TON" или символ/адрес джеттона
+	Comment       string `json:"comment,omitempty"`
 }
 
 type TransferRequest struct {
 	TargetWallet string `json:"target_wallet" binding:"required"`
-	Amount       string `json:"amount" binding:"required"` // "X.YYYYYYYYY"
+	Amount       string `json:"amount" binding:"required,numeric"` // "X.YYYYYYYYY"
 	Comment      string `json:"comment,omitempty"`
 }
 
@@ -53,6 +131,46 @@ type WalletTxInfo struct {
 	Currency  string    `json:"currency,omitempty"`
 }
 
+// Баланс кошелька в сети (/api/balance/:account).
+type WalletBalance struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"` // "X.YYYYYYYYY"
+	Unit    string `json:"unit"`    // TON
+}
+
+// Ответ /api/health: готовность и состояние каждой зависимости.
+type HealthStatus struct {
+	Status    string             `json:"status"` // ok | degraded (есть сбои, но сервис готов) | down
+	Ready     bool               `json:"ready"`
+	Timestamp time.Time          `json:"timestamp"`
+	Version   string             `json:"version"` // версия HTTP API
+	Build     BuildInfo          `json:"build"`
+	Checks    []DependencyHealth `json:"checks"`
+}
+
+// Версия и коммит сборки (go build -ldflags "-X main.version=... -X main.commit=...").
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+type DependencyHealth struct {
+	Name        string     `json:"name"`
+	Group       string     `json:"group,omitempty"` // storage | ton | watcher; пусто — не влияет на готовность
+	OK          bool       `json:"ok"`
+	LatencyMs   int64      `json:"latency_ms"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Статусы HealthStatus.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
 type AccountInfo struct {
 	Address      string          `json:"address"`
 	Balance      string          `json:"balance"` // TON "X.YYYYYYYYY"
@@ -79,8 +197,414 @@ type NFTItem struct {
 
 // Это то, что использует TONService для поиска платежа:
 type CheckPaymentRequest struct {
-	MerchantAddress string
-	Comment         string
-	MinAmountTon    string // "3.000000000"
-	Limit           int
-}
\ No newline at end of file
+	MerchantAddress string `json:"merchant_address" binding:"required"`
+	Comment         string `json:"comment,omitempty"`
+	MinAmountTon    string `json:"min_amount_ton" binding:"required,numeric"` // "3.000000000"
+	Limit           int    `json:"limit,omitempty" binding:"min=0,max=200"`   // событий для просмотра, по умолчанию 50
+}
+// Параметры для ссылки на оплату (JSON-тело или query-параметры).
+type PaymentLinkRequest struct {
+	MerchantAddress string `json:"merchant_address" form:"merchant_address" binding:"required"`
+	AmountTon       string `json:"amount_ton" form:"amount_ton" binding:"required,numeric"` // "3.000000000"
+	Comment         string `json:"comment,omitempty" form:"comment"`
+}
+
+type PaymentLink struct {
+	Address      string `json:"address"`
+	AmountTon    string `json:"amount_ton"`   // "X.YYYYYYYYY"
+	AmountNanos  string `json:"amount_nanos"` // "3000000000"
+	Comment      string `json:"comment,omitempty"`
+	TonURI       string `json:"ton_uri"`
+	TonkeeperURL string `json:"tonkeeper_url"`
+	TonhubURL    string `json:"tonhub_url"`
+	QRPNG        string `json:"qr_png,omitempty"` // data:image/png;base64,...
+}
+
+// Запрос на формирование TON Connect sendTransaction.
+type TonConnectTxRequest struct {
+	MerchantAddress string `json:"merchant_address" binding:"required"`
+	AmountTon       string `json:"amount_ton" binding:"required,numeric"`
+	Comment         string `json:"comment,omitempty"`
+	ValidForSec     int    `json:"valid_for_sec,omitempty" binding:"min=0,max=86400"` // по умолчанию 300
+}
+
+// Формат запроса sendTransaction из спецификации TON Connect.
+type TonConnectTransaction struct {
+	ValidUntil int64               `json:"valid_until"`
+	Messages   []TonConnectMessage `json:"messages"`
+}
+
+type TonConnectMessage struct {
+	Address   string `json:"address"`
+	Amount    string `json:"amount"`            // нанотоны
+	Payload   string `json:"payload,omitempty"` // base64 BOC
+	StateInit string `json:"stateInit,omitempty"`
+}
+
+// Проверка отправленной через TON Connect транзакции: BOC из ответа кошелька или hash сообщения.
+type TonConnectVerifyRequest struct {
+	MerchantAddress string `json:"merchant_address" binding:"required"`
+	BOC             string `json:"boc,omitempty"` // нужен boc или message_hash
+	MessageHash     string `json:"message_hash,omitempty"`
+	AmountTon       string `json:"amount_ton,omitempty" binding:"omitempty,numeric"`
+	Comment         string `json:"comment,omitempty"`
+}
+
+type TonConnectVerification struct {
+	MessageHash string    `json:"message_hash"`
+	TxHash      string    `json:"tx_hash,omitempty"`
+	Found       bool      `json:"found"`   // событие найдено на кошельке мерчанта
+	Matched     bool      `json:"matched"` // сумма и комментарий совпали с ожидаемыми
+	Sender      string    `json:"sender,omitempty"`
+	Amount      string    `json:"amount,omitempty"` // TON "X.YYYYYYYYY"
+	Comment     string    `json:"comment,omitempty"`
+	Timestamp   time.Time `json:"timestamp,omitempty"`
+}
+
+// Запрос на проверку ton_proof (формат TonProofItemReplySuccess из TON Connect).
+type TonProofRequest struct {
+	Address   string   `json:"address" binding:"required"`
+	Network   string   `json:"network,omitempty"`
+	PublicKey string   `json:"public_key,omitempty"` // hex; если указан — должен совпасть с ключом из state_init
+	Proof     TonProof `json:"proof" binding:"required"`
+}
+
+type TonProof struct {
+	Timestamp int64          `json:"timestamp" binding:"required"`
+	Domain    TonProofDomain `json:"domain"`
+	Signature string         `json:"signature" binding:"required"` // base64
+	Payload   string         `json:"payload" binding:"required"`
+	StateInit string         `json:"state_init" binding:"required"` // base64 BOC
+}
+
+type TonProofDomain struct {
+	LengthBytes int    `json:"lengthBytes"`
+	Value       string `json:"value"`
+}
+
+type TonProofPayload struct {
+	Payload   string    `json:"payload"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TonProofSession struct {
+	Token     string    `json:"token"`
+	Address   string    `json:"address"` // raw "0:hex"
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Способ сопоставления оплаты с инвойсом.
+const (
+	InvoiceModeComment = "comment" // общий кошелёк мерчанта + уникальный комментарий
+	InvoiceModeDeposit = "deposit" // отдельный депозитный кошелёк, комментарий не нужен
+)
+
+// Статусы инвойса.
+const (
+	InvoicePending = "pending"
+	InvoicePaid    = "paid"
+	InvoiceExpired = "expired"
+)
+
+type CreateInvoiceRequest struct {
+	MerchantID      string `json:"-"`                                                  // заполняется по ключу вызывающего мерчанта
+	MerchantAddress string `json:"merchant_address,omitempty"`                         // по умолчанию APP_WALLET
+	AmountTon       string `json:"amount_ton,omitempty" binding:"omitempty,numeric"`   // либо сумма в TON...
+	FiatAmount      string `json:"fiat_amount,omitempty" binding:"omitempty,numeric"`  // ...либо в фиате
+	FiatCurrency    string `json:"fiat_currency,omitempty"`                            // USD / EUR / RUB
+	Comment         string `json:"comment,omitempty"`                                  // по умолчанию генерируется
+	SlippagePct     string `json:"slippage_pct,omitempty" binding:"omitempty,numeric"` // допуск недоплаты, % (по умолчанию из конфига)
+	TTLSec          int    `json:"ttl_sec,omitempty" binding:"min=0"`
+	Mode            string `json:"mode,omitempty"`        // comment (по умолчанию) | deposit
+	CustomerID      string `json:"customer_id,omitempty"` // в режиме deposit — один адрес на покупателя
+}
+
+// Курс, зафиксированный при создании инвойса (для учёта).
+type LockedRate struct {
+	Currency string    `json:"currency"`
+	TonPrice string    `json:"ton_price"` // цена 1 TON в валюте
+	Source   string    `json:"source"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+type Invoice struct {
+	ID              string      `json:"id"`
+	MerchantID      string      `json:"merchant_id,omitempty"`
+	MerchantAddress string      `json:"merchant_address"`
+	Mode            string      `json:"mode"`
+	DepositAddress  string      `json:"deposit_address,omitempty"`
+	CustomerID      string      `json:"customer_id,omitempty"`
+	Comment         string      `json:"comment"`
+	AmountTon       string      `json:"amount_ton"`     // "X.YYYYYYYYY"
+	MinAmountTon    string      `json:"min_amount_ton"` // AmountTon с учётом допуска
+	FiatAmount      string      `json:"fiat_amount,omitempty"`
+	FiatCurrency    string      `json:"fiat_currency,omitempty"`
+	Rate            *LockedRate `json:"rate,omitempty"`
+	SlippagePct     string      `json:"slippage_pct,omitempty"`
+	Status          string      `json:"status"`
+	CreatedAt       time.Time   `json:"created_at"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+	PaidAt          *time.Time  `json:"paid_at,omitempty"`
+	PaidAmountTon   string      `json:"paid_amount_ton,omitempty"`
+	TxHash          string      `json:"tx_hash,omitempty"`
+	Sender          string      `json:"sender,omitempty"`
+}
+
+type Rate struct {
+	Currency string    `json:"currency"`
+	TonPrice string    `json:"ton_price"`
+	Source   string    `json:"source"`
+	At       time.Time `json:"at"`
+}
+
+type Merchant struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	Wallets         []string         `json:"wallets"`
+	WebhookURL      string           `json:"webhook_url,omitempty"`
+	DefaultCurrency string           `json:"default_currency,omitempty"`
+	APIKeys         []MerchantAPIKey `json:"api_keys,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// Ключ хранится только как SHA-256; сам ключ отдаётся один раз при выпуске.
+type MerchantAPIKey struct {
+	ID        string    `json:"id"`
+	Prefix    string    `json:"prefix"`
+	Hash      string    `json:"hash,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MerchantRequest struct {
+	Name            string   `json:"name" binding:"required"`
+	Wallets         []string `json:"wallets" binding:"required,min=1,dive,required"`
+	WebhookURL      string   `json:"webhook_url,omitempty" binding:"omitempty,url"`
+	DefaultCurrency string   `json:"default_currency,omitempty"`
+}
+
+// Выпущенный ключ мерчанта (Key виден только в этом ответе).
+type IssuedAPIKey struct {
+	MerchantID string `json:"merchant_id"`
+	KeyID      string `json:"key_id"`
+	Key        string `json:"key"`
+}
+
+// Созданный мерчант и его первый ключ.
+type MerchantCreated struct {
+	Merchant Merchant      `json:"merchant"`
+	APIKey   *IssuedAPIKey `json:"api_key"`
+}
+
+// Депозитный кошелёк: subwallet мастер-ключа, средства с него сметаются на MerchantAddress.
+type DepositAddress struct {
+	Address         string     `json:"address"`
+	SubwalletID     uint32     `json:"subwallet_id"`
+	MerchantAddress string     `json:"merchant_address"`
+	CustomerID      string     `json:"customer_id,omitempty"`
+	InvoiceID       string     `json:"invoice_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastSweepAt     *time.Time `json:"last_sweep_at,omitempty"`
+	LastSweepTx     string     `json:"last_sweep_tx,omitempty"`
+	LastSweepPosted bool       `json:"last_sweep_posted,omitempty"` // сметание проведено в журнал
+	SweptTotalTon   string     `json:"swept_total_ton,omitempty"`
+}
+
+// Курсор опроса кошелька: последнее обработанное событие. Пустой курсор — кошелёк
+// новый, все его события ещё не обработаны.
+type WatchCursor struct {
+	Wallet    string    `json:"wallet"`
+	EventID   string    `json:"event_id,omitempty"`
+	Lt        int64     `json:"lt,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Статусы «потерянного» платежа.
+const (
+	OrphanUnmatched = "unmatched" // ждёт разбора
+	OrphanAttached  = "attached"  // вручную привязан к инвойсу
+	OrphanRefund    = "refund"    // помечен к возврату отправителю
+)
+
+// Входящий перевод, не сопоставленный ни с одним открытым инвойсом
+// (нет комментария, опечатка, недоплата, оплата после истечения).
+type OrphanPayment struct {
+	ID              string             `json:"id"`       // id события TonAPI (":номер действия" — для второго и следующих переводов события)
+	EventID         string             `json:"event_id"` // id события TonAPI
+	Wallet          string             `json:"wallet"`   // получатель: кошелёк мерчанта или депозитный адрес
+	MerchantAddress string             `json:"merchant_address"`
+	Sender          string             `json:"sender,omitempty"`
+	AmountTon       string             `json:"amount_ton"`
+	Comment         string             `json:"comment,omitempty"`
+	Timestamp       time.Time          `json:"timestamp"`
+	Status          string             `json:"status"`
+	InvoiceID       string             `json:"invoice_id,omitempty"`
+	Note            string             `json:"note,omitempty"`
+	ResolvedAt      *time.Time         `json:"resolved_at,omitempty"`
+	Suggestions     []OrphanSuggestion `json:"suggestions,omitempty"`
+}
+
+// Инвойс, к которому платёж, вероятно, относится.
+// Reasons: "amount" — сумма подходит, "comment" — комментарий похож, "deposit_address" — тот же депозитный адрес.
+type OrphanSuggestion struct {
+	InvoiceID       string   `json:"invoice_id"`
+	Comment         string   `json:"comment,omitempty"`
+	AmountTon       string   `json:"amount_ton"`
+	Status          string   `json:"status"`
+	CommentDistance int      `json:"comment_distance"`
+	Reasons         []string `json:"reasons"`
+}
+
+// Результат привязки платежа к инвойсу.
+type OrphanAttachment struct {
+	Orphan  *OrphanPayment `json:"orphan"`
+	Invoice *Invoice       `json:"invoice"`
+}
+
+type AttachOrphanRequest struct {
+	InvoiceID string `json:"invoice_id" binding:"required"`
+}
+
+type RefundOrphanRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// Выплата или возврат, выполненные вне сервиса, — для проводки в журнал.
+type LedgerOperationRequest struct {
+	Reference   string `json:"reference" binding:"required"` // hash транзакции или id операции
+	Wallet      string `json:"wallet" binding:"required"`    // кошелёк, с которого ушли средства (для остатка — сам кошелёк)
+	Destination string `json:"destination,omitempty"`        // получатель выплаты
+	Amount      string `json:"amount" binding:"required,numeric"`
+	Fee         string `json:"fee,omitempty" binding:"omitempty,numeric"`
+	Currency    string `json:"currency,omitempty"` // по умолчанию TON
+	Memo        string `json:"memo,omitempty"`
+}
+
+// Сальдо счёта журнала по валютам.
+type LedgerBalance struct {
+	Account  string            `json:"account"`
+	Balances map[string]string `json:"balances"` // валюта -> сумма
+}
+
+// Виды расхождений при сверке с блокчейном.
+const (
+	DiscrepancyUnrecordedTransfer = "unrecorded_transfer" // входящий перевод есть в сети, записи нет
+	DiscrepancyUnrecordedOutgoing = "unrecorded_outgoing" // исходящий перевод без проводки
+	DiscrepancyMissingOnChain     = "missing_onchain"     // запись есть, в сети перевода нет
+	DiscrepancyAmountMismatch     = "amount_mismatch"
+	DiscrepancyMissingLedger      = "missing_ledger_entry" // оплаченный инвойс без проводки
+	DiscrepancyBalanceDrift       = "balance_drift"        // баланс кошелька != сальдо по журналу
+)
+
+type Discrepancy struct {
+	Kind      string `json:"kind"`
+	Wallet    string `json:"wallet"`
+	EventID   string `json:"event_id,omitempty"`
+	InvoiceID string `json:"invoice_id,omitempty"`
+	Expected  string `json:"expected,omitempty"` // по нашим записям
+	Actual    string `json:"actual,omitempty"`   // в сети
+	Details   string `json:"details,omitempty"`
+}
+
+type WalletReconciliation struct {
+	Wallet           string `json:"wallet"`
+	Events           int    `json:"events"`
+	Incoming         int    `json:"incoming"`
+	Outgoing         int    `json:"outgoing"`
+	Complete         bool   `json:"complete"` // пройдена вся история (иначе missing_onchain не проверяется)
+	ChainBalanceTon  string `json:"chain_balance_ton"`
+	LedgerBalanceTon string `json:"ledger_balance_ton,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+type ReconciliationReport struct {
+	StartedAt     time.Time              `json:"started_at"`
+	FinishedAt    time.Time              `json:"finished_at"`
+	Wallets       []WalletReconciliation `json:"wallets"`
+	Discrepancies []Discrepancy          `json:"discrepancies"`
+}
+
+// Форматы выгрузки и статусы фоновых выгрузок.
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl"
+	ExportXLSX  = "xlsx"
+
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportDone    = "done"
+	ExportFailed  = "failed"
+)
+
+// Выгрузка переводов аккаунта за период [From, To); нулевые границы — без ограничения.
+type ExportRequest struct {
+	Account  string    `json:"account"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Format   string    `json:"format"`
+	Currency string    `json:"currency,omitempty"` // фиатная оценка по курсу на момент перевода
+}
+
+// Фоновая выгрузка; файл скачивается по DownloadURL, когда Status = done.
+type ExportJob struct {
+	ID          string        `json:"id"`
+	Request     ExportRequest `json:"request"`
+	Status      string        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	Rows        int           `json:"rows"`
+	SizeBytes   int64         `json:"size_bytes,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	FinishedAt  *time.Time    `json:"finished_at,omitempty"`
+	DownloadURL string        `json:"download_url,omitempty"`
+}
+
+// Сохранённый ответ на запрос с Idempotency-Key (ключ хранилища — hash scope и ключа).
+type IdempotencyRecord struct {
+	Scope       string    `json:"scope"` // мерчант / кошелёк сессии / хеш токена
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"` // sha256 метода, пути и тела
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Ответы API v2 (/api/v2): при успехе — data нужного эндпоинту типа, при ошибке — error; meta есть всегда.
+// В отличие от v1 (Response), исход бизнес-проверки (например, платёж не найден) — часть data, а не Success.
+type V2Response[T any] struct {
+	Data T      `json:"data"`
+	Meta V2Meta `json:"meta"`
+}
+
+type V2ErrorResponse struct {
+	Error V2Error `json:"error"`
+	Meta  V2Meta  `json:"meta"`
+}
+
+type V2Error struct {
+	Code    string          `json:"code"` // invalid_request | unauthorized | forbidden | not_found | conflict | ...
+	Message string          `json:"message"`
+	Details []V2ErrorDetail `json:"details,omitempty"`
+}
+
+// Ошибка в конкретном поле запроса.
+type V2ErrorDetail struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"` // правило валидации: required, numeric, max=200, ...
+}
+
+type V2Meta struct {
+	RequestID  string        `json:"request_id"`
+	Pagination *V2Pagination `json:"pagination,omitempty"`
+}
+
+type V2Pagination struct {
+	Limit int `json:"limit"`
+	Count int `json:"count"` // сколько элементов в data
+}
+
+// Результат POST /api/v2/check-payment.
+type PaymentCheckResult struct {
+	Paid bool `json:"paid"`
+}
@@ -0,0 +1,302 @@
+// Package openapi — описание HTTP API в формате OpenAPI 3.0. Схемы строятся по
+// моделям (теги json и binding), поэтому спецификация не расходится с валидацией запросов.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	ops        map[string]*Operation // "GET /api/x/{id}" -> операция
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem — операции пути по методу в нижнем регистре ("get", "post", ...).
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path | query | header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`             // http | apiKey
+	Scheme string `json:"scheme,omitempty"` // bearer
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Доступ к операции.
+const (
+	AuthClient   = ""         // ключ клиента, мерчанта или сессия ton_proof; пока ключей нет — необязательно
+	AuthOperator = "operator" // обязательный ключ клиента, мерчанта или администратора
+	AuthAdmin    = "admin"
+	AuthNone     = "none"
+)
+
+// Op — описание маршрута для New: пути в нотации gin (/api/invoices/:id).
+type Op struct {
+	Method      string
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Tag         string
+	Auth        string
+	Params      []Parameter // query- и header-параметры; параметры пути добавляются сами
+	Body        any         // модель JSON-тела запроса
+	Status      int         // код успешного ответа, по умолчанию 200
+	Data        any         // тип поля data в ответе; nil — ответ без data
+	Raw         []string    // вместо JSON: типы содержимого успешного ответа (файлы); пустой — без тела
+	Also        map[int]any // дополнительные успешные ответы: код -> тип data
+	Errors      []int
+	V2          bool // ответы в формате API v2: {data, meta} и {error, meta}
+}
+
+// Query — параметр строки запроса; typ: string | integer | boolean.
+func Query(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+// QueryParams — параметры строки запроса по тегам form модели (как у ShouldBindQuery).
+func QueryParams(model any) []Parameter {
+	g := newGenerator()
+	obj := g.inline(model, modeRequest)
+	var out []Parameter
+	for _, name := range obj.order {
+		out = append(out, Parameter{Name: name, In: "query", Required: slices.Contains(obj.Required, name), Schema: obj.Properties[name]})
+	}
+	return out
+}
+
+// New — документ по списку маршрутов.
+func New(info Info, tags []Tag, ops []Op) *Document {
+	g := newGenerator()
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Tags:    tags,
+		Paths:   map[string]PathItem{},
+		ops:     map[string]*Operation{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer"},
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+	}
+	g.schemas["Error"] = &Schema{
+		Type:     "object",
+		Required: []string{"success", "message"},
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+	}
+	g.schemas["V2Meta"] = &Schema{
+		Type:     "object",
+		Required: []string{"request_id"},
+		Properties: map[string]*Schema{
+			"request_id": {Type: "string"},
+			"pagination": {
+				Type:       "object",
+				Required:   []string{"limit", "count"},
+				Properties: map[string]*Schema{"limit": {Type: "integer"}, "count": {Type: "integer"}},
+			},
+		},
+	}
+	g.schemas["V2ErrorResponse"] = &Schema{
+		Type:     "object",
+		Required: []string{"error", "meta"},
+		Properties: map[string]*Schema{
+			"error": {
+				Type:     "object",
+				Required: []string{"code", "message"},
+				Properties: map[string]*Schema{
+					"code":    {Type: "string"},
+					"message": {Type: "string"},
+					"details": {Type: "array", Items: &Schema{
+						Type:       "object",
+						Required:   []string{"field", "reason"},
+						Properties: map[string]*Schema{"field": {Type: "string"}, "reason": {Type: "string"}},
+					}},
+				},
+			},
+			"meta": ref("V2Meta"),
+		},
+	}
+	for _, op := range ops {
+		path, params := ginPath(op.Path)
+		o := &Operation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Description: op.Description,
+			Parameters:  append(params, op.Params...),
+			Responses:   map[string]*Response{},
+		}
+		if op.Tag != "" {
+			o.Tags = []string{op.Tag}
+		}
+		switch op.Auth {
+		case AuthAdmin, AuthOperator:
+			o.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+		case AuthClient:
+			o.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}, {}}
+		}
+		if op.Body != nil {
+			o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: g.schema(op.Body, modeRequest)}}}
+		}
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if op.Raw != nil {
+			content := map[string]MediaType{}
+			for _, ct := range op.Raw {
+				schema := &Schema{Type: "string", Format: "binary"}
+				if ct == "application/json" {
+					schema = &Schema{Type: "object"}
+				}
+				content[ct] = MediaType{Schema: schema}
+			}
+			o.Responses[strconv.Itoa(status)] = &Response{Description: http.StatusText(status), Content: content}
+		} else {
+			o.Responses[strconv.Itoa(status)] = g.envelope(status, op.Data, op.V2)
+		}
+		for code, data := range op.Also {
+			o.Responses[strconv.Itoa(code)] = g.envelope(code, data, op.V2)
+		}
+		errorSchema := ref("Error")
+		if op.V2 {
+			errorSchema = ref("V2ErrorResponse")
+		}
+		for _, code := range op.Errors {
+			o.Responses[strconv.Itoa(code)] = &Response{
+				Description: http.StatusText(code),
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+		item := d.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			d.Paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = o
+		d.ops[op.Method+" "+path] = o
+	}
+	return d
+}
+
+// envelope — ответ models.Response с данными типа data; v2 — models.V2Response.
+func (g *generator) envelope(status int, data any, v2 bool) *Response {
+	if v2 {
+		s := &Schema{
+			Type:       "object",
+			Required:   []string{"data", "meta"},
+			Properties: map[string]*Schema{"data": g.schema(data, modeResponse), "meta": ref("V2Meta")},
+		}
+		return &Response{Description: http.StatusText(status), Content: map[string]MediaType{"application/json": {Schema: s}}}
+	}
+	s := &Schema{
+		Type:     "object",
+		Required: []string{"success", "message"},
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+	}
+	if data != nil {
+		s.Required = append(s.Required, "data")
+		s.Properties["data"] = g.schema(data, modeResponse)
+		if nullable(reflect.TypeOf(data)) {
+			s.Properties["data"] = orNull(s.Properties["data"]) // пустой срез из хранилища — null
+		}
+	}
+	return &Response{Description: http.StatusText(status), Content: map[string]MediaType{"application/json": {Schema: s}}}
+}
+
+// ginPath — /api/invoices/:id -> /api/invoices/{id} и параметры пути.
+func ginPath(p string) (string, []Parameter) {
+	var params []Parameter
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			name := part[1:]
+			parts[i] = "{" + name + "}"
+			params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+// Operation — операция по методу и пути в нотации gin; nil, если не описана.
+func (d *Document) Operation(method, ginRoute string) *Operation {
+	path, _ := ginPath(ginRoute)
+	return d.ops[method+" "+path]
+}
+
+// Routes — "METHOD /path" всех описанных операций, по порядку.
+func (d *Document) Routes() []string {
+	out := make([]string, 0, len(d.ops))
+	for k := range d.ops {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID    string    `json:"id"`
+	Tags  []string  `json:"tags"`
+	At    time.Time `json:"at"`
+	Note  string    `json:"note,omitempty"`
+	Child *item     `json:"child,omitempty"`
+}
+
+type createItem struct {
+	Name   string   `json:"name" binding:"required"`
+	Amount string   `json:"amount,omitempty" binding:"omitempty,numeric"`
+	Kind   string   `json:"kind,omitempty" binding:"omitempty,oneof=a b"`
+	Count  int      `json:"count" binding:"min=1,max=10"`
+	Wallet []string `json:"wallets" binding:"required,min=1,dive,required"`
+}
+
+func TestNew_SchemasFollowJSONAndBindingTags(t *testing.T) {
+	d := New(Info{Title: "t", Version: "1"}, nil, []Op{{Method: "POST", Path: "/items/:id", ID: "create", Body: createItem{}, Status: http.StatusCreated, Data: item{}, Errors: []int{400}}})
+	op := d.Operation("POST", "/items/:id")
+	if op == nil || len(op.Parameters) != 1 || op.Parameters[0].In != "path" || op.Parameters[0].Name != "id" { t.Fatalf("operation: %+v", op) }
+
+	req := d.Components.Schemas["createItem"]
+	if strings.Join(req.Required, ",") != "name,wallets" { t.Fatalf("request required: %v", req.Required) }
+	if p := req.Properties; p["amount"].Pattern == "" || len(p["kind"].Enum) != 2 || *p["count"].Minimum != 1 || *p["count"].Maximum != 10 || *p["wallets"].MinItems != 1 { t.Fatalf("binding rules not applied: %+v", p) }
+	if resp := d.Components.Schemas["item"]; strings.Join(resp.Required, ",") != "id,tags,at" || !resp.Properties["tags"].Nullable { t.Fatalf("response schema: %+v", resp) }
+}
+
+func TestValidateResponse_RejectsNonConformingBodies(t *testing.T) {
+	d := New(Info{Title: "t", Version: "1"}, nil, []Op{{Method: "GET", Path: "/items/:id", ID: "get", Data: item{}, Errors: []int{404}}})
+	ok := `{"success":true,"message":"m","data":{"id":"1","tags":null,"at":"2024-01-02T03:04:05Z","child":{"id":"2","tags":["x"],"at":"2024-01-02T03:04:05Z"}}}`
+	if err := d.ValidateResponse("GET", "/items/:id", 200, "application/json; charset=utf-8", []byte(ok)); err != nil { t.Fatalf("valid body rejected: %v", err) }
+
+	for name, body := range map[string]string{
+		"missing field": `{"success":true,"message":"m","data":{"tags":[],"at":"2024-01-02T03:04:05Z"}}`,
+		"wrong type":    `{"success":true,"message":"m","data":{"id":1,"tags":[],"at":"2024-01-02T03:04:05Z"}}`,
+		"bad time":      `{"success":true,"message":"m","data":{"id":"1","tags":[],"at":"yesterday"}}`,
+		"nested":        `{"success":true,"message":"m","data":{"id":"1","tags":[],"at":"2024-01-02T03:04:05Z","child":{"id":"2"}}}`,
+		"unknown field": `{"success":true,"message":"m","data":{"id":"1","tags":[],"at":"2024-01-02T03:04:05Z","extra":1}}`,
+	} {
+		if err := d.ValidateResponse("GET", "/items/:id", 200, "application/json", []byte(body)); err == nil { t.Errorf("%s: accepted", name) }
+	}
+	if err := d.ValidateResponse("GET", "/items/:id", 500, "application/json", []byte(`{}`)); err == nil { t.Error("undocumented status accepted") }
+	if err := d.ValidateResponse("GET", "/items/:id", 404, "application/json", []byte(`{"success":false,"message":"not found"}`)); err != nil { t.Errorf("error body: %v", err) }
+}
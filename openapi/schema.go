@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Schema — подмножество JSON Schema из OpenAPI 3.0, которое нужно для наших моделей.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+
+	order []string // порядок полей модели (для QueryParams)
+}
+
+// Числовая строка, как проверяет binding:"numeric".
+const numericPattern = `^[-+]?[0-9]+(\.[0-9]+)?$`
+
+// Схема для запроса: обязательность и ограничения — из тегов binding.
+// Для ответа: обязательны поля без omitempty, nil-срезы и указатели допускают null.
+type mode int
+
+const (
+	modeRequest mode = iota
+	modeResponse
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	rawType     = reflect.TypeOf(json.RawMessage{})
+)
+
+type generator struct {
+	schemas map[string]*Schema
+	modes   map[string]mode
+	types   map[string]reflect.Type
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: map[string]*Schema{}, modes: map[string]mode{}, types: map[string]reflect.Type{}}
+}
+
+func ref(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+
+func (g *generator) schema(v any, m mode) *Schema {
+	return g.typeSchema(reflect.TypeOf(v), m)
+}
+
+// inline — схема структуры без регистрации в components.
+func (g *generator) inline(v any, m mode) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return g.object(t, m)
+}
+
+func (g *generator) typeSchema(t reflect.Type, m mode) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case decimalType:
+		return &Schema{Type: "string", Pattern: numericPattern}
+	case rawType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.typeSchema(t.Elem(), m)
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := &Schema{Type: "integer"}
+		if t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64 {
+			s.Format = "int64"
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem(), m)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem(), m)}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, m)
+		}
+		return g.named(t, m)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// named — именованная структура регистрируется в components и подставляется ссылкой.
+func (g *generator) named(t reflect.Type, m mode) *Schema {
+	name := t.Name()
+	if prev, ok := g.types[name]; ok && prev != t {
+		pkg := path.Base(t.PkgPath()) // одноимённые типы разных пакетов: LedgerEntry
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	if prev, ok := g.modes[name]; ok {
+		if prev != m {
+			panic(fmt.Sprintf("openapi: %s is used both as request and response model", t))
+		}
+		return ref(name)
+	}
+	g.types[name], g.modes[name] = t, m
+	g.schemas[name] = &Schema{} // заглушка для рекурсивных типов
+	*g.schemas[name] = *g.object(t, m)
+	return ref(name)
+}
+
+func (g *generator) object(t reflect.Type, m mode) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, m, s)
+	return s
+}
+
+func (g *generator) fields(t reflect.Type, m mode, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if m == modeRequest && tag == "" && f.Tag.Get("form") != "" {
+			tag = f.Tag.Get("form")
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.fields(f.Type, m, s)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		prop := g.typeSchema(f.Type, m)
+		required := false
+		switch m {
+		case modeRequest:
+			required = applyBinding(prop, f.Tag.Get("binding"))
+		case modeResponse:
+			required = !omitempty
+			if required && nullable(f.Type) {
+				prop = orNull(prop)
+			}
+		}
+		s.Properties[name] = prop
+		s.order = append(s.order, name)
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// nullable — значение, которое encoding/json может записать как null.
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Slice:
+		return t != rawType
+	}
+	return false
+}
+
+func orNull(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	c := *s
+	c.Nullable = true
+	return &c
+}
+
+// applyBinding — правила go-playground/validator из тега binding в ограничения схемы;
+// возвращает, обязательно ли поле. Правила после dive относятся к элементам.
+func applyBinding(s *Schema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "numeric":
+			s.Pattern = numericPattern
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			s.Enum = strings.Fields(arg)
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			setBound(s, name == "min", n)
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, lower bool, n int) {
+	switch s.Type {
+	case "integer", "number":
+		f := float64(n)
+		if lower {
+			s.Minimum = &f
+		} else {
+			s.Maximum = &f
+		}
+	case "array":
+		if lower {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	case "string":
+		if lower {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateResponse — соответствует ли ответ операции (метод и путь в нотации gin) описанию:
+// код ответа, тип содержимого и, для JSON, схема тела.
+func (d *Document) ValidateResponse(method, ginRoute string, status int, contentType string, body []byte) error {
+	op := d.Operation(method, ginRoute)
+	if op == nil {
+		return fmt.Errorf("%s %s is not described", method, ginRoute)
+	}
+	resp := op.Responses[strconv.Itoa(status)]
+	if resp == nil {
+		return fmt.Errorf("%s %s: status %d is not described", method, ginRoute, status)
+	}
+	if len(resp.Content) == 0 {
+		return nil
+	}
+	mt, _, _ := mime.ParseMediaType(contentType)
+	media, ok := resp.Content[mt]
+	if !ok {
+		return fmt.Errorf("%s %s: content type %q is not described for %d", method, ginRoute, contentType, status)
+	}
+	if mt != "application/json" || media.Schema == nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%s %s: body is not JSON: %w", method, ginRoute, err)
+	}
+	if errs := d.validate(media.Schema, v, "body"); len(errs) > 0 {
+		return fmt.Errorf("%s %s (%d): %w", method, ginRoute, status, errors.Join(errs...))
+	}
+	return nil
+}
+
+func (d *Document) validate(s *Schema, v any, at string) []error {
+	if s.Ref != "" {
+		target := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if target == nil {
+			return []error{fmt.Errorf("%s: unresolved %s", at, s.Ref)}
+		}
+		return d.validate(target, v, at)
+	}
+	if v == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: null is not allowed", at)}
+	}
+	var errs []error
+	fail := func(format string, args ...any) []error {
+		return append(errs, fmt.Errorf("%s: "+format, append([]any{at}, args...)...))
+	}
+	for _, sub := range s.AllOf {
+		errs = append(errs, d.validate(sub, v, at)...)
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("expected object, got %T", v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = fail("missing required property %q", name)
+			}
+		}
+		for name, val := range obj {
+			prop := s.Properties[name]
+			if prop == nil {
+				prop = s.AdditionalProperties
+			}
+			if prop == nil {
+				if s.Properties != nil {
+					errs = fail("unexpected property %q", name)
+				}
+				continue
+			}
+			errs = append(errs, d.validate(prop, val, at+"."+name)...)
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fail("expected array, got %T", v)
+		}
+		for i, item := range arr {
+			if s.Items != nil {
+				errs = append(errs, d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fail("expected string, got %T", v)
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			errs = fail("%q is not one of %v", str, s.Enum)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			errs = fail("%q does not match %s", str, s.Pattern)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				errs = fail("%q is not a date-time", str)
+			}
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			return fail("expected integer, got %v", v)
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return fail("expected number, got %T", v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("expected boolean, got %T", v)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"payment-service/config"
+	"payment-service/handlers"
+	"payment-service/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testWallet = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+	testTxHash = "9f3c8a0b7e6d5c4b3a29180706f5e4d3c2b1a09f8e7d6c5b4a3928170605f4e3"
+	adminKey   = "admin-key"
+)
+
+// fakeTonAPI — ответы TonAPI, которых хватает всем маршрутам.
+func fakeTonAPI(t *testing.T) *httptest.Server {
+	event := `{"event_id":"` + testTxHash + `","timestamp":1700000000,"actions":[
+	 {"type":"TonTransfer","status":"ok","TonTransfer":{"sender":{"address":"0:a"},"recipient":{"address":"` + testWallet + `"},"amount":1500000000,"comment":"hi"}}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.Path; {
+		case strings.HasSuffix(p, "/events"):
+			_, _ = io.WriteString(w, `{"events":[`+event+`]}`)
+		case strings.HasPrefix(p, "/v2/events/"):
+			_, _ = io.WriteString(w, event)
+		case strings.HasPrefix(p, "/v2/accounts/"):
+			_, _ = io.WriteString(w, `{"balance":2500000000,"status":"active"}`)
+		case p == "/v2/status":
+			_, _ = io.WriteString(w, `{"rest_online":true,"indexing_latency":1}`)
+		case p == "/v2/rates":
+			_, _ = io.WriteString(w, `{"rates":{"TON":{"prices":{"USD":5.12}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testApp(t *testing.T) *app {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TON_API_URL", fakeTonAPI(t).URL)
+	t.Setenv("APP_WALLET", testWallet)
+	t.Setenv("ADMIN_API_KEYS", adminKey)
+	t.Setenv("TON_PROOF_DOMAINS", "pay.example.com")
+	t.Setenv("EXPORT_DIR", t.TempDir())
+	cfg, err := config.Load("")
+	if err != nil { t.Fatalf("config: %v", err) }
+	a, err := newApp(config.Static(cfg), storage.NewMemory())
+	if err != nil { t.Fatalf("app: %v", err) }
+	return a
+}
+
+func TestOpenAPI_DescribesEveryRoute(t *testing.T) {
+	a := testApp(t)
+	spec := handlers.OpenAPISpec()
+	routes := a.router.Routes()
+	for _, r := range routes {
+		if spec.Operation(r.Method, r.Path) == nil { t.Errorf("%s %s is not in the OpenAPI spec", r.Method, r.Path) }
+	}
+	if len(spec.Routes()) != len(routes) { t.Errorf("spec describes %d operations, router has %d routes:\n%v", len(spec.Routes()), len(routes), spec.Routes()) }
+
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	var doc map[string]any
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &doc) != nil || doc["openapi"] != "3.0.3" { t.Fatalf("GET /api/openapi.json: %d %.200s", rec.Code, rec.Body) }
+}
+
+func TestOpenAPI_ResponsesConformToSpec(t *testing.T) {
+	a := testApp(t)
+	spec := handlers.OpenAPISpec()
+	ids := map[string]string{}
+	call := func(method, route, path, body string, admin bool, want int) map[string]any {
+		t.Helper()
+		for k, v := range ids {
+			path = strings.ReplaceAll(path, "{"+k+"}", v)
+		}
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if admin {
+			req.Header.Set("X-API-Key", adminKey)
+		}
+		rec := httptest.NewRecorder()
+		a.router.ServeHTTP(rec, req)
+		if rec.Code != want { t.Errorf("%s %s: status %d, want %d: %.300s", method, path, rec.Code, want, rec.Body) }
+		if err := spec.ValidateResponse(method, route, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()); err != nil { t.Error(err) }
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return out
+	}
+	id := func(resp map[string]any, key string) string {
+		data, _ := resp["data"].(map[string]any)
+		s, _ := data[key].(string)
+		return s
+	}
+
+	call("GET", "/api/openapi.json", "/api/openapi.json", "", false, 200)
+	call("GET", "/livez", "/livez", "", false, 200)
+	call("GET", "/readyz", "/readyz", "", false, 200)
+	call("GET", "/api/health", "/api/health", "", false, 200)
+	call("POST", "/api/check-payment", "/api/check-payment", `{"merchant_address":"`+testWallet+`","comment":"hi","min_amount_ton":"1"}`, false, 200)
+	call("POST", "/api/check-payment", "/api/check-payment", `{"merchant_address":"`+testWallet+`","min_amount_ton":"lots"}`, false, 400)
+	call("POST", "/api/validate-payment", "/api/validate-payment", `{"wallet_address":"`+testWallet+`","tx_hash":"`+testTxHash+`","amount":"1.5"}`, false, 200)
+	call("GET", "/api/account-info/:account", "/api/account-info/"+testWallet, "", false, 200)
+	call("GET", "/api/transactions/:account", "/api/transactions/"+testWallet+"?limit=5", "", false, 200)
+	call("GET", "/api/transactions/:account", "/api/transactions/"+testWallet+"?from=yesterday", "", false, 400)
+	call("GET", "/api/balance/:account", "/api/balance/"+testWallet, "", false, 200)
+	call("POST", "/api/payment-link", "/api/payment-link", `{"merchant_address":"`+testWallet+`","amount_ton":"1.5","comment":"order-1"}`, false, 200)
+	call("GET", "/api/payment-link/qr", "/api/payment-link/qr?merchant_address="+testWallet+"&amount_ton=1.5", "", false, 200)
+	call("POST", "/api/payment-link", "/api/payment-link", `{"merchant_address":"EQ_MERCHANT","amount_ton":"1.5"}`, false, 400)
+	call("GET", "/api/payment-link/qr", "/api/payment-link/qr?merchant_address=EQ_MERCHANT&amount_ton=1.5", "", false, 400)
+	call("POST", "/api/tonconnect/transaction", "/api/tonconnect/transaction", `{"merchant_address":"`+testWallet+`","amount_ton":"1.5"}`, false, 200)
+	call("POST", "/api/tonconnect/verify", "/api/tonconnect/verify", `{"merchant_address":"`+testWallet+`"}`, false, 400)
+	call("POST", "/api/auth/ton-proof/payload", "/api/auth/ton-proof/payload", "", false, 200)
+	call("POST", "/api/auth/ton-proof", "/api/auth/ton-proof", `{"address":"x"}`, false, 400)
+
+	ids["invoice"] = id(call("POST", "/api/invoices", "/api/invoices", `{"merchant_address":"`+testWallet+`","amount_ton":"1.5"}`, false, 201), "id")
+	call("GET", "/api/invoices/:id", "/api/invoices/{invoice}", "", false, 200)
+	call("GET", "/api/invoices/:id/payment-link", "/api/invoices/{invoice}/payment-link", "", false, 200)
+	call("GET", "/api/invoices/:id", "/api/invoices/missing", "", false, 404)
+	call("GET", "/api/rates/:currency", "/api/rates/usd", "", false, 200)
+	call("GET", "/api/orphans", "/api/orphans", "", false, 200)
+	call("GET", "/api/orphans/:id", "/api/orphans/missing", "", false, 404)
+	call("GET", "/api/ledger/wallets/:account/balance", "/api/ledger/wallets/"+testWallet+"/balance", "", false, 200)
+	ids["job"] = id(call("GET", "/api/exports/transactions", "/api/exports/transactions?account="+testWallet+"&async=true", "", false, 202), "id")
+	call("GET", "/api/exports/jobs/:id", "/api/exports/jobs/{job}", "", false, 200)
+	call("GET", "/api/exports/transactions", "/api/exports/transactions", "", false, 400)
+
+	v2 := call("POST", "/api/v2/check-payment", "/api/v2/check-payment", `{"merchant_address":"`+testWallet+`","comment":"hi","min_amount_ton":"100"}`, false, 200)
+	if data, _ := v2["data"].(map[string]any); data["paid"] != false { t.Errorf("v2 check-payment: %v", v2) }
+	v2 = call("POST", "/api/v2/check-payment", "/api/v2/check-payment", `{"merchant_address":"`+testWallet+`","min_amount_ton":"lots","limit":500}`, false, 400)
+	if e, _ := v2["error"].(map[string]any); e["code"] != "invalid_request" || len(e["details"].([]any)) != 2 { t.Errorf("v2 validation error: %v", v2) }
+	call("POST", "/api/v2/validate-payment", "/api/v2/validate-payment", `{"wallet_address":"`+testWallet+`","tx_hash":"`+testTxHash+`","amount":"1.5"}`, false, 200)
+	call("GET", "/api/v2/account-info/:account", "/api/v2/account-info/"+testWallet, "", false, 200)
+	v2 = call("GET", "/api/v2/transactions/:account", "/api/v2/transactions/"+testWallet+"?limit=5", "", false, 200)
+	if meta, _ := v2["meta"].(map[string]any); meta["pagination"] == nil || meta["request_id"] == "" { t.Errorf("v2 meta: %v", v2) }
+	call("GET", "/api/v2/transactions/:account", "/api/v2/transactions/"+testWallet+"?direction=sideways", "", false, 400)
+	call("GET", "/api/v2/balance/:account", "/api/v2/balance/"+testWallet, "", false, 200)
+	call("GET", "/api/v2/health", "/api/v2/health", "", false, 200)
+	call("POST", "/api/v2/payment-link", "/api/v2/payment-link", `{"merchant_address":"`+testWallet+`","amount_ton":"1.5"}`, false, 200)
+	ids["invoice2"] = id(call("POST", "/api/v2/invoices", "/api/v2/invoices", `{"merchant_address":"`+testWallet+`","amount_ton":"2.5"}`, false, 201), "id")
+	call("GET", "/api/v2/invoices/:id", "/api/v2/invoices/{invoice2}", "", false, 200)
+	call("GET", "/api/v2/invoices/:id", "/api/v2/invoices/missing", "", false, 404)
+	call("GET", "/api/v2/rates/:currency", "/api/v2/rates/usd", "", false, 200)
+
+	call("GET", "/api/admin/merchants", "/api/admin/merchants", "", false, 401)
+	created := call("POST", "/api/admin/merchants", "/api/admin/merchants", `{"name":"Shop","wallets":["`+testWallet+`"]}`, true, 201)
+	merchant, _ := created["data"].(map[string]any)["merchant"].(map[string]any)
+	ids["merchant"], _ = merchant["id"].(string)
+	call("POST", "/api/admin/merchants", "/api/admin/merchants", `{"name":"Shop","wallets":[],"webhook_url":"not a url"}`, true, 400)
+	call("GET", "/api/admin/merchants", "/api/admin/merchants", "", true, 200)
+	call("GET", "/api/admin/merchants/:id", "/api/admin/merchants/{merchant}", "", true, 200)
+	call("PUT", "/api/admin/merchants/:id", "/api/admin/merchants/{merchant}", `{"name":"Shop 2","wallets":["`+testWallet+`"]}`, true, 200)
+	call("POST", "/api/admin/merchants/:id/api-keys", "/api/admin/merchants/{merchant}/api-keys", "", true, 201)
+	call("GET", "/api/admin/ledger/entries", "/api/admin/ledger/entries", "", true, 200)
+	call("POST", "/api/admin/ledger/payouts", "/api/admin/ledger/payouts", `{"reference":"p1","wallet":"`+testWallet+`","destination":"0:b","amount":"1"}`, true, 201)
+	call("POST", "/api/admin/ledger/openings", "/api/admin/ledger/openings", `{"reference":"start","wallet":"`+testWallet+`","amount":"10"}`, true, 201)
+	call("GET", "/api/admin/ledger/balances", "/api/admin/ledger/balances?wallet="+testWallet, "", true, 200)
+	call("GET", "/api/admin/ledger/check", "/api/admin/ledger/check", "", true, 200)
+	call("GET", "/api/admin/reconcile/last", "/api/admin/reconcile/last", "", true, 404)
+	call("GET", "/api/admin/config", "/api/admin/config", "", true, 200)
+	call("POST", "/api/admin/config/reload", "/api/admin/config/reload", "", true, 200)
+	call("DELETE", "/api/admin/merchants/:id", "/api/admin/merchants/{merchant}", "", true, 200)
+}
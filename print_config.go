@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"payment-service/config"
+)
+
+// printConfig — итоговая конфигурация с источниками значений (секреты скрыты)
+// и ошибки валидации; код выхода 1, если конфигурация невалидна.
+func printConfig(w io.Writer, cfg *config.Config, loadErr error) int {
+	if cfg != nil {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, s := range cfg.Settings() {
+			fmt.Fprintf(tw, "%s=%s\t# %s\n", s.Key, s.Value, s.Source)
+		}
+		_ = tw.Flush()
+	}
+	if loadErr != nil {
+		fmt.Fprintf(w, "\nInvalid configuration:\n%v\n", loadErr)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+
+	"payment-service/services"
+)
+
+// runReconcile — разовая сверка: отчёт в stdout (JSON); код выхода 1, если есть расхождения.
+func runReconcile(r *services.Reconciler, wallets []string) int {
+	rep, err := r.Run(context.Background(), wallets)
+	if err != nil {
+		log.Printf("Reconciliation failed: %v", err)
+		return 2
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		log.Printf("Failed to write report: %v", err)
+		return 2
+	}
+	if len(rep.Discrepancies) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"payment-service/config"
+
+	"google.golang.org/grpc"
+)
+
+// newHTTPServer — http.Server с таймаутами и пределом заголовков из конфигурации.
+func newHTTPServer(cfg *config.Config, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.ServerPort,
+		Handler:           h,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// background — фоновые циклы (watcher, sweeper, сверка) с общим контекстом:
+// Stop отменяет его и ждёт завершения циклов.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+func (b *background) Go(run func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		run(b.ctx)
+	}()
+}
+
+func (b *background) Stop(ctx context.Context) error {
+	b.cancel()
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background tasks: %w", ctx.Err())
+	}
+}
+
+// serve — обслуживает ln до отмены ctx (SIGINT/SIGTERM), затем останавливается не дольше timeout:
+// перестаёт принимать соединения, дожидается активных запросов и вызывает stop
+// (фоновые задачи, сброс хранилища) с оставшимся временем.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration, stop func(context.Context) error) error {
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		// сервер упал сам — фоновые задачи всё равно останавливаем
+		sctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return errors.Join(err, stop(sctx))
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down (timeout %s)", timeout)
+	sctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var errs []error
+	if err := srv.Shutdown(sctx); err != nil {
+		// не успели дождаться запросов — рвём оставшиеся соединения
+		errs = append(errs, fmt.Errorf("http: %w", err), srv.Close())
+	}
+	if err := <-errc; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+	if err := stop(sctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// serveGRPC — обслуживает ln до отмены ctx, затем GracefulStop: активные вызовы (и потоки
+// WaitPayment) дожидаются не дольше timeout, оставшиеся обрываются.
+func serveGRPC(ctx context.Context, srv *grpc.Server, ln net.Listener, timeout time.Duration) {
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		log.Printf("gRPC server stopped with error: %v", err)
+		return
+	case <-ctx.Done():
+	}
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		srv.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func startServe(t *testing.T, h http.Handler, timeout time.Duration, stop func(context.Context) error) (string, context.CancelFunc, chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil { t.Fatalf("listen: %v", err) }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, &http.Server{Handler: h}, ln, timeout, stop) }()
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+func TestServe_DrainsInFlightRequestsAndStopsBackground(t *testing.T) {
+	started := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+	bg := newBackground()
+	bgStopped := make(chan struct{})
+	bg.Go(func(ctx context.Context) { <-ctx.Done(); close(bgStopped) })
+	url, cancel, done := startServe(t, h, 2*time.Second, bg.Stop)
+
+	resp := make(chan string, 1)
+	go func() {
+		r, err := http.Get(url)
+		if err != nil { resp <- "error: " + err.Error(); return }
+		b, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		resp <- string(b)
+	}()
+	<-started
+	begin := time.Now()
+	cancel()
+
+	if got := <-resp; got != "done" { t.Fatalf("in-flight request: %q", got) }
+	select {
+	case err := <-done:
+		if err != nil { t.Fatalf("serve: %v", err) }
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown did not complete within the deadline")
+	}
+	if d := time.Since(begin); d > time.Second { t.Fatalf("shutdown took %s", d) }
+	select {
+	case <-bgStopped:
+	default:
+		t.Fatal("background task still running")
+	}
+	if _, err := http.Get(url); err == nil { t.Fatal("server still accepts connections") }
+}
+
+func TestServe_GivesUpAfterDeadline(t *testing.T) {
+	release, started := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { close(started); <-release })
+	stopped := false
+	url, cancel, done := startServe(t, h, 100*time.Millisecond, func(context.Context) error { stopped = true; return nil })
+
+	go func() { _, _ = http.Get(url) }()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) || !stopped { t.Fatalf("want deadline error and stop called, got %v (stopped %v)", err, stopped) }
+	case <-time.After(time.Second):
+		t.Fatal("shutdown hung past the deadline")
+	}
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	depositsCollection   = "deposits"     // адрес -> DepositAddress
+	depositKeyCollection = "deposit_keys" // ключ выделения (инвойс/покупатель) -> адрес
+	depositMetaKey       = "next_index"   // счётчик выделенных subwallet
+	depositMetaColl      = "deposit_meta"
+)
+
+var ErrDepositsDisabled = errors.New("deposit mode is not configured")
+
+// SweepSender — перевод всего баланса депозитного кошелька на основной кошелёк мерчанта.
+type SweepSender interface {
+	SweepAll(ctx context.Context, subwalletID uint32, dest string) (txHash string, err error)
+}
+
+// DepositService — детерминированные депозитные кошельки (subwallet мастер-ключа)
+// и периодическое «сметание» средств с них на кошелёк мерчанта.
+type DepositService struct {
+	store    *storage.Store
+	client   TonAPI
+	pub      ed25519.PublicKey
+	version  wallet.VersionConfig
+	base     uint32
+	maxIndex uint32
+	sender   SweepSender
+	minSweep decimal.Decimal
+	watcher  *Watcher
+	ledger   *ledger.Ledger
+	now      func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewDepositService — masterSeed: 32 байта ed25519 seed в hex; version: v4r2 | v5r1.
+func NewDepositService(store *storage.Store, svc *TONService, masterSeed, version string, sender SweepSender, minSweepTon float64) (*DepositService, error) {
+	seed, err := hex.DecodeString(strings.TrimSpace(masterSeed))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("deposit master seed must be %d bytes in hex", ed25519.SeedSize)
+	}
+	s := &DepositService{
+		store:    store,
+		client:   svc.client,
+		pub:      ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey),
+		sender:   sender,
+		minSweep: decimal.NewFromFloat(minSweepTon),
+		now:      time.Now,
+	}
+	if s.version, s.base, s.maxIndex, err = depositWalletVersion(version); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// depositWalletVersion — версия кошелька и диапазон subwallet id под депозиты.
+// v4: id после стандартного 698983191; v5: номер subwallet 16-битный, 0 — основной кошелёк.
+func depositWalletVersion(v string) (wallet.VersionConfig, uint32, uint32, error) {
+	switch strings.ToLower(v) {
+	case "", "v4", "v4r2":
+		return wallet.V4R2, wallet.DefaultSubwallet + 1, 1<<32 - 1 - (wallet.DefaultSubwallet + 1), nil
+	case "v5", "v5r1":
+		return wallet.ConfigV5R1Final{NetworkGlobalID: wallet.MainnetGlobalID, Workchain: 0}, 1, 1<<16 - 2, nil
+	}
+	return nil, 0, 0, fmt.Errorf("unsupported deposit wallet version %q", v)
+}
+
+// SetWatcher — watcher отслеживает все выделенные депозитные адреса.
+func (s *DepositService) SetWatcher(w *Watcher) {
+	s.watcher = w
+	all, _ := s.List()
+	for _, d := range all {
+		w.AddWallet(d.Address)
+	}
+}
+
+// SetLedger — сметания проводятся в журнал как перевод депозит -> кошелёк мерчанта
+// с комиссией сети из транзакции сметания.
+func (s *DepositService) SetLedger(l *ledger.Ledger) { s.ledger = l }
+
+// Allocate — депозитный адрес для инвойса или покупателя. Для одного и того же
+// покупателя мерчанта возвращается один и тот же адрес.
+func (s *DepositService) Allocate(merchantAddress, customerID, invoiceID string) (*models.DepositAddress, error) {
+	key := "inv:" + invoiceID
+	if customerID != "" {
+		key = "cus:" + normKey(merchantAddress) + ":" + customerID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var addr string
+	if ok, err := s.store.Get(depositKeyCollection, key, &addr); err != nil {
+		return nil, err
+	} else if ok {
+		return s.ByAddress(addr)
+	}
+
+	var next uint32
+	if _, err := s.store.Get(depositMetaColl, depositMetaKey, &next); err != nil {
+		return nil, err
+	}
+	if next > s.maxIndex {
+		return nil, fmt.Errorf("deposit subwallet range exhausted")
+	}
+	id := s.base + next
+	a, err := wallet.AddressFromPubKey(s.pub, s.version, id)
+	if err != nil {
+		return nil, fmt.Errorf("derive deposit address: %w", err)
+	}
+	// неинициализированный кошелёк: non-bounceable, чтобы первый перевод не вернулся
+	a.SetBounce(false)
+
+	d := &models.DepositAddress{
+		Address:         a.String(),
+		SubwalletID:     id,
+		MerchantAddress: merchantAddress,
+		CustomerID:      customerID,
+		CreatedAt:       s.now().UTC(),
+	}
+	if customerID == "" {
+		d.InvoiceID = invoiceID
+	}
+	if err := s.store.Put(depositMetaColl, depositMetaKey, next+1); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(depositsCollection, d.Address, d); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(depositKeyCollection, key, d.Address); err != nil {
+		return nil, err
+	}
+	if s.watcher != nil {
+		// адрес только что выведен — у него нет истории, первый же перевод новый
+		s.watcher.AddFreshWallet(d.Address)
+	}
+	return d, nil
+}
+
+func (s *DepositService) ByAddress(addr string) (*models.DepositAddress, error) {
+	var d models.DepositAddress
+	if ok, err := s.store.Get(depositsCollection, addr, &d); err == nil && ok {
+		return &d, nil
+	}
+	// адрес мог прийти в другом формате (raw / bounceable)
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if SameAddress(all[i].Address, addr) {
+			return &all[i], nil
+		}
+	}
+	return nil, fmt.Errorf("deposit address %s not found", addr)
+}
+
+// IsSweep — перевод пришёл с депозитного адреса или это записанное сметание:
+// такой перевод — движение собственных средств, а не платёж покупателя.
+func (s *DepositService) IsSweep(t IncomingTransfer) bool {
+	all, err := s.List()
+	if err != nil {
+		return false
+	}
+	for i := range all {
+		d := &all[i]
+		if t.Sender != "" && SameAddress(d.Address, t.Sender) {
+			return true
+		}
+		if d.LastSweepTx != "" && strings.EqualFold(d.LastSweepTx, t.EventID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DepositService) List() ([]models.DepositAddress, error) {
+	return storage.List[models.DepositAddress](s.store, depositsCollection)
+}
+
+// RunSweeper — периодическое сметание до отмены контекста.
+func (s *DepositService) RunSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.SweepOnce(ctx)
+		}
+	}
+}
+
+// SweepOnce — переводит баланс каждого депозита не ниже порога на кошелёк мерчанта.
+func (s *DepositService) SweepOnce(ctx context.Context) {
+	if s.sender == nil {
+		return
+	}
+	all, err := s.List()
+	if err != nil {
+		log.Printf("sweeper: list deposits: %v", err)
+		return
+	}
+	for i := range all {
+		d := &all[i]
+		if d.LastSweepTx != "" && !d.LastSweepPosted && !s.postSweep(ctx, d) {
+			continue // прошлое сметание ещё не проведено: его транзакция пока не видна в TonAPI
+		}
+		nanos, _, err := s.client.GetAccount(ctx, d.Address)
+		if err != nil {
+			log.Printf("sweeper: %s: %v", d.Address, err)
+			continue
+		}
+		bal := decimal.RequireFromString(nanosIntToTonString(nanos))
+		if bal.IsZero() || bal.LessThan(s.minSweep) {
+			continue
+		}
+		tx, err := s.sender.SweepAll(ctx, d.SubwalletID, d.MerchantAddress)
+		if err != nil {
+			log.Printf("sweeper: %s -> %s: %v", d.Address, d.MerchantAddress, err)
+			continue
+		}
+		s.recordSweep(d, bal, tx)
+		s.postSweep(ctx, d)
+		log.Printf("sweeper: swept %s TON from %s to %s (tx %s)", bal.StringFixed(9), d.Address, d.MerchantAddress, tx)
+	}
+}
+
+func (s *DepositService) recordSweep(d *models.DepositAddress, amount decimal.Decimal, tx string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at := s.now().UTC()
+	total := amount
+	if d.SweptTotalTon != "" {
+		total = total.Add(decimal.RequireFromString(d.SweptTotalTon))
+	}
+	d.LastSweepAt = &at
+	d.LastSweepTx = tx
+	d.LastSweepPosted = false
+	d.SweptTotalTon = total.StringFixed(9)
+	if err := s.store.Put(depositsCollection, d.Address, d); err != nil {
+		log.Printf("sweeper: save %s: %v", d.Address, err)
+	}
+}
+
+// postSweep — проводка последнего сметания по его транзакции: мерчанту зачисляется сумма
+// исходящего сообщения, остальное (total_fees и пересылка) — комиссия сети.
+// false — транзакция ещё не проиндексирована, проводка будет на следующем проходе.
+func (s *DepositService) postSweep(ctx context.Context, d *models.DepositAddress) bool {
+	if s.ledger == nil {
+		return true
+	}
+	tx, err := s.client.GetTransaction(ctx, d.LastSweepTx)
+	if err != nil {
+		if !errors.Is(err, ErrTonAPINotFound) {
+			log.Printf("sweeper: %s: sweep tx %s: %v", d.Address, d.LastSweepTx, err)
+		}
+		return false
+	}
+	sent, fee := int64(0), tx.TotalFees
+	for _, m := range tx.OutMsgs {
+		sent += m.Value
+		fee += m.FwdFee
+	}
+	amount := decimal.RequireFromString(nanosIntToTonString(sent))
+	feeTon := decimal.RequireFromString(nanosIntToTonString(fee))
+	postLedger(s.ledger, ledger.Transfer(d.LastSweepTx, LedgerWallet(d.Address), LedgerWallet(d.MerchantAddress), amount, feeTon, ledger.CurrencyTON, s.now()))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.LastSweepPosted = true
+	if err := s.store.Put(depositsCollection, d.Address, d); err != nil {
+		log.Printf("sweeper: save %s: %v", d.Address, err)
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const testDepositSeed = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+type fakeSweeper struct {
+	calls []uint32
+	dest  string
+}
+
+func (f *fakeSweeper) SweepAll(_ context.Context, subwalletID uint32, dest string) (string, error) {
+	f.calls = append(f.calls, subwalletID)
+	f.dest = dest
+	return "abcd", nil
+}
+
+func newTestDeposits(t *testing.T, mock *mockTonAPI, sender SweepSender) *DepositService {
+	t.Helper()
+	d, err := NewDepositService(storage.NewMemory(), NewTONServiceWithClient(mock), testDepositSeed, "v4r2", sender, 0.1)
+	if err != nil { t.Fatalf("deposits: %v", err) }
+	return d
+}
+
+func TestDeposit_AllocateIsDeterministicAndReusedPerCustomer(t *testing.T) {
+	merchant := testWallet(1)
+	a := newTestDeposits(t, &mockTonAPI{}, nil)
+	b := newTestDeposits(t, &mockTonAPI{}, nil)
+
+	d1, err := a.Allocate(merchant, "cust-1", "inv_1")
+	if err != nil { t.Fatalf("allocate: %v", err) }
+	d2, _ := b.Allocate(merchant, "cust-1", "inv_1")
+	if d1.Address != d2.Address || d1.SubwalletID != d2.SubwalletID { t.Fatalf("not deterministic: %+v vs %+v", d1, d2) }
+	if !strings.HasPrefix(d1.Address, "UQ") { t.Fatalf("deposit address must be non-bounceable: %s", d1.Address) }
+
+	again, _ := a.Allocate(merchant, "cust-1", "inv_2")
+	if again.Address != d1.Address { t.Fatalf("customer got a new address: %s", again.Address) }
+
+	other, _ := a.Allocate(merchant, "", "inv_3")
+	if other.Address == d1.Address || other.SubwalletID != d1.SubwalletID+1 { t.Fatalf("unexpected: %+v", other) }
+}
+
+func TestDeposit_InvoicePaidByTransferToDepositAddress(t *testing.T) {
+	svc := newTestInvoices(t)
+	if _, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Mode: models.InvoiceModeDeposit}); err != ErrDepositsDisabled {
+		t.Fatalf("want ErrDepositsDisabled, got %v", err)
+	}
+	svc.SetDeposits(newTestDeposits(t, &mockTonAPI{}, nil))
+
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{MerchantAddress: testWallet(1), AmountTon: "2", Mode: models.InvoiceModeDeposit, CustomerID: "c1"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if inv.DepositAddress == "" || inv.Comment != "" { t.Fatalf("unexpected invoice: %+v", inv) }
+	if req := svc.CheckRequest(inv); req.MerchantAddress != inv.DepositAddress || req.Comment != "" { t.Fatalf("check request: %+v", req) }
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: inv.DepositAddress, Amount: "2", Comment: "anything"})
+	got, _ := svc.Get(inv.ID)
+	if got.Status != models.InvoicePaid || got.TxHash != "E1" { t.Fatalf("unexpected: %+v", got) }
+}
+
+func TestDeposit_SweepOnceRespectsThreshold(t *testing.T) {
+	balances := map[string]int64{}
+	mock := &mockTonAPI{accountFn: func(_ context.Context, id string) (int64, string, error) { return balances[id], "active", nil }}
+	sender := &fakeSweeper{}
+	d := newTestDeposits(t, mock, sender)
+	d.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	merchant := testWallet(1)
+	small, _ := d.Allocate(merchant, "", "inv_1")
+	big, _ := d.Allocate(merchant, "", "inv_2")
+	balances[small.Address] = 50_000_000
+	balances[big.Address] = 1_500_000_000
+
+	d.SweepOnce(context.Background())
+	if len(sender.calls) != 1 || sender.calls[0] != big.SubwalletID || sender.dest != merchant { t.Fatalf("calls: %v -> %s", sender.calls, sender.dest) }
+
+	got, _ := d.ByAddress(big.Address)
+	if got.SweptTotalTon != "1.500000000" || got.LastSweepTx != "abcd" || got.LastSweepAt == nil { t.Fatalf("not recorded: %+v", got) }
+}
+
+// Сметание проводится по транзакции: мерчант получает сумму исходящего сообщения, остаток — комиссия сети.
+// Пока TonAPI не видит транзакцию, проводки нет; она появляется на следующем проходе.
+func TestDeposit_SweepPostsReceivedAmountAndFee(t *testing.T) {
+	balances := map[string]int64{}
+	indexed := false
+	mock := &mockTonAPI{
+		accountFn: func(_ context.Context, id string) (int64, string, error) { return balances[id], "active", nil },
+		txFn: func(_ context.Context, h string) (Transaction, error) {
+			if !indexed || h != "abcd" { return Transaction{}, ErrTonAPINotFound }
+			return Transaction{Hash: h, TotalFees: 3_000_000, OutMsgs: []TxMessage{{Value: 1_496_000_000, FwdFee: 1_000_000}}}, nil
+		},
+	}
+	d := newTestDeposits(t, mock, &fakeSweeper{})
+	journal := ledger.New(storage.NewMemory())
+	d.SetLedger(journal)
+
+	merchant := testWallet(1)
+	dep, _ := d.Allocate(merchant, "", "inv_1")
+	balances[dep.Address] = 1_500_000_000
+	d.SweepOnce(context.Background())
+	if entries, _ := journal.Entries(""); len(entries) != 0 { t.Fatalf("posted before tx is indexed: %+v", entries) }
+
+	balances[dep.Address] = 0
+	indexed = true
+	d.SweepOnce(context.Background())
+	received, _ := journal.Balance(ledger.Wallet(LedgerWallet(merchant)), "TON")
+	fees, _ := journal.Balance(ledger.Fees(LedgerWallet(dep.Address)), "TON")
+	left, _ := journal.Balance(ledger.Wallet(LedgerWallet(dep.Address)), "TON")
+	if received.String() != "1.496" || fees.String() != "0.004" || left.String() != "-1.5" { t.Fatalf("received %s, fees %s, deposit %s", received, fees, left) }
+	if got, _ := d.ByAddress(dep.Address); !got.LastSweepPosted { t.Fatalf("not marked posted: %+v", got) }
+	d.SweepOnce(context.Background())
+	if entries, _ := journal.Entries(""); len(entries) != 1 { t.Fatalf("entries: %+v", entries) }
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	exportJobsCollection = "export_jobs"
+	exportPurgeEvery     = time.Hour
+)
+
+var (
+	ErrExportFormat   = errors.New("format must be csv, jsonl or xlsx")
+	ErrExportNotFound = errors.New("export job not found")
+	ErrExportNotReady = errors.New("export is not ready")
+)
+
+var exportColumns = []string{"timestamp", "hash", "direction", "from", "to", "amount_ton", "comment", "fiat_currency", "fiat_rate", "fiat_amount"}
+
+// ExportService — выгрузка истории переводов для бухгалтерии: потоком в ответ
+// или фоновой задачей в файл (для больших периодов).
+type ExportService struct {
+	ton   *TONService
+	rates RateProvider
+	store *storage.Store
+	dir   string
+	ttl   time.Duration
+	now   func() time.Time
+	jobs  sync.WaitGroup
+
+	mu  sync.Mutex
+	ctx context.Context // контекст фоновых выгрузок: отменяется при остановке сервера
+}
+
+func NewExportService(ton *TONService, rates RateProvider, store *storage.Store, dir string) (*ExportService, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("export dir: %w", err)
+	}
+	s := &ExportService{ton: ton, rates: rates, store: store, dir: dir, ttl: 24 * time.Hour, now: time.Now, ctx: context.Background()}
+	// задачи, прерванные перезапуском, уже не завершатся
+	jobs, _ := storage.List[models.ExportJob](store, exportJobsCollection)
+	for _, j := range jobs {
+		if j.Status == models.ExportPending || j.Status == models.ExportRunning {
+			s.finish(&j, 0, errors.New("interrupted by restart"))
+		}
+	}
+	return s, nil
+}
+
+// SetTTL — сколько хранятся завершённые выгрузки (запись и файл).
+func (s *ExportService) SetTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+}
+
+// Run — фоновые выгрузки выполняются в контексте ctx (отмена прерывает их при остановке
+// сервера); раз в час удаляются выгрузки старше TTL. Работает до отмены ctx.
+func (s *ExportService) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	t := time.NewTicker(exportPurgeEvery)
+	defer t.Stop()
+	for {
+		s.Purge()
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Purge — удаляет завершённые выгрузки старше TTL; возвращает их число.
+func (s *ExportService) Purge() int {
+	jobs, err := storage.List[models.ExportJob](s.store, exportJobsCollection)
+	if err != nil {
+		log.Printf("export purge: %v", err)
+		return 0
+	}
+	n := 0
+	for i := range jobs {
+		j := &jobs[i]
+		if j.FinishedAt == nil || s.now().Sub(*j.FinishedAt) < s.ttl {
+			continue
+		}
+		if err := os.Remove(s.path(j)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("export %s: remove file: %v", j.ID, err)
+			continue
+		}
+		if err := s.store.Delete(exportJobsCollection, j.ID); err != nil {
+			log.Printf("export %s: %v", j.ID, err)
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// ExportContentType — MIME-тип файла выгрузки.
+func ExportContentType(format string) string {
+	switch format {
+	case models.ExportJSONL:
+		return "application/x-ndjson"
+	case models.ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportFileName — имя файла для Content-Disposition.
+func ExportFileName(req models.ExportRequest) string {
+	name := "transactions-" + LedgerWallet(req.Account)
+	if !req.From.IsZero() {
+		name += "-" + req.From.UTC().Format("20060102")
+	}
+	if !req.To.IsZero() {
+		name += "-" + req.To.UTC().Format("20060102")
+	}
+	return strings.ReplaceAll(name, ":", "_") + "." + req.Format
+}
+
+// Write — выгрузка в w; возвращает число строк.
+func (s *ExportService) Write(ctx context.Context, req models.ExportRequest, w io.Writer) (int, error) {
+	rw, err := newExportRowWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	pricer := newFiatPricer(s.rates, req.Currency)
+	rows := 0
+	err = s.ton.WalkTransactions(ctx, req.Account, req.From, req.To, func(t models.TransactionInfo) error {
+		if t.Kind != models.TxKindTonTransfer {
+			return nil // выгрузка — в TON и фиате; джеттоны и NFT сюда не попадают
+		}
+		if t.Status != models.TxStatusOK {
+			return nil // прерванные и отскочившие переводы деньги не переместили
+		}
+		rows++
+		return rw.Write(exportRecord(ctx, req.Account, t, pricer))
+	})
+	if cerr := rw.Close(); err == nil {
+		err = cerr
+	}
+	return rows, err
+}
+
+// StartJob — фоновая выгрузка в файл.
+func (s *ExportService) StartJob(req models.ExportRequest) (*models.ExportJob, error) {
+	if _, err := newExportRowWriter(req.Format, io.Discard); err != nil {
+		return nil, err
+	}
+	j := &models.ExportJob{
+		ID:        "exp_" + randomHex(8),
+		Request:   req,
+		Status:    models.ExportPending,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.store.Put(exportJobsCollection, j.ID, j); err != nil {
+		return nil, err
+	}
+	ctx := s.jobContext()
+	s.jobs.Add(1)
+	go func(j models.ExportJob) {
+		defer s.jobs.Done()
+		s.run(ctx, j)
+	}(*j)
+	return j, nil
+}
+
+// Wait — ожидание фоновых выгрузок при остановке сервера; незавершённые
+// к отмене ctx задачи после перезапуска помечаются failed.
+func (s *ExportService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ExportService) Job(id string) (*models.ExportJob, error) {
+	var j models.ExportJob
+	ok, err := s.store.Get(exportJobsCollection, id, &j)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+	return &j, nil
+}
+
+// JobFile — путь к готовому файлу выгрузки.
+func (s *ExportService) JobFile(j *models.ExportJob) (string, error) {
+	if j.Status != models.ExportDone {
+		return "", fmt.Errorf("%w: %s", ErrExportNotReady, j.Status)
+	}
+	return s.path(j), nil
+}
+
+func (s *ExportService) jobContext() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+func (s *ExportService) run(ctx context.Context, j models.ExportJob) {
+	j.Status = models.ExportRunning
+	if err := s.store.Put(exportJobsCollection, j.ID, j); err != nil {
+		log.Printf("export %s: %v", j.ID, err)
+	}
+
+	tmp := s.path(&j) + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		s.finish(&j, 0, err)
+		return
+	}
+	bw := bufio.NewWriter(f)
+	rows, err := s.Write(ctx, j.Request, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path(&j))
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	s.finish(&j, rows, err)
+}
+
+func (s *ExportService) finish(j *models.ExportJob, rows int, err error) {
+	at := s.now().UTC()
+	j.FinishedAt = &at
+	j.Rows = rows
+	if err != nil {
+		j.Status = models.ExportFailed
+		j.Error = err.Error()
+	} else {
+		j.Status = models.ExportDone
+		j.DownloadURL = "/api/exports/jobs/" + j.ID + "/download"
+		if st, serr := os.Stat(s.path(j)); serr == nil {
+			j.SizeBytes = st.Size()
+		}
+	}
+	if perr := s.store.Put(exportJobsCollection, j.ID, j); perr != nil {
+		log.Printf("export %s: %v", j.ID, perr)
+	}
+}
+
+func (s *ExportService) path(j *models.ExportJob) string {
+	return filepath.Join(s.dir, j.ID+"."+j.Request.Format)
+}
+
+// exportRecord — строка выгрузки в порядке exportColumns.
+func exportRecord(ctx context.Context, account string, t models.TransactionInfo, pricer *fiatPricer) []any {
+	direction := "out"
+	if SameAddress(t.To, account) {
+		direction = "in"
+	}
+	amount := decimal.RequireFromString(t.Amount)
+	rec := []any{t.Timestamp.Format(time.RFC3339), t.Hash, direction, t.From, t.To, amount, t.Comment, "", "", ""}
+	if price, ok := pricer.price(ctx, t.Timestamp); ok {
+		rec[7], rec[8], rec[9] = pricer.currency, price, amount.Mul(price).Round(2)
+	}
+	return rec
+}
+
+// ---------------- форматы ----------------
+
+type exportRowWriter interface {
+	Write(rec []any) error
+	Close() error
+}
+
+func newExportRowWriter(format string, w io.Writer) (exportRowWriter, error) {
+	header := make([]any, len(exportColumns))
+	for i, c := range exportColumns {
+		header[i] = c
+	}
+	var rw exportRowWriter
+	switch format {
+	case models.ExportCSV:
+		rw = &csvRowWriter{w: csv.NewWriter(w)}
+	case models.ExportJSONL:
+		return &jsonlRowWriter{enc: json.NewEncoder(w)}, nil
+	case models.ExportXLSX:
+		x, err := newXLSXWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		rw = x
+	default:
+		return nil, ErrExportFormat
+	}
+	return rw, rw.Write(header)
+}
+
+type csvRowWriter struct{ w *csv.Writer }
+
+func (c *csvRowWriter) Write(rec []any) error {
+	row := make([]string, len(rec))
+	for i, v := range rec {
+		if s, ok := v.(string); ok {
+			row[i] = csvText(s)
+		} else {
+			row[i] = fmt.Sprint(v) // суммы и курсы (decimal) — числа как есть
+		}
+	}
+	return c.w.Write(row)
+}
+
+// csvText — текстовая ячейка (комментарий задаёт плательщик): значение, которое табличный
+// редактор принял бы за формулу (=, +, -, @, TAB, CR в начале), экранируется апострофом.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlRowWriter — объект на строку, пустые поля опускаются.
+type jsonlRowWriter struct{ enc *json.Encoder }
+
+func (j *jsonlRowWriter) Write(rec []any) error {
+	obj := make(map[string]any, len(rec))
+	for i, v := range rec {
+		if s, ok := v.(string); ok && s == "" {
+			continue
+		}
+		obj[exportColumns[i]] = v
+	}
+	return j.enc.Encode(obj)
+}
+
+func (j *jsonlRowWriter) Close() error { return nil }
+
+// ---------------- фиатная оценка ----------------
+
+// fiatPricer — курс на момент перевода; ряды цен запрашиваются помесячно и кешируются.
+// Без исторических курсов (или без currency) фиатные колонки остаются пустыми.
+type fiatPricer struct {
+	hist     HistoricalRateProvider
+	currency string
+	months   map[time.Time]RateSeries
+}
+
+func newFiatPricer(rates RateProvider, currency string) *fiatPricer {
+	p := &fiatPricer{currency: strings.ToUpper(strings.TrimSpace(currency)), months: make(map[time.Time]RateSeries)}
+	if h, ok := rates.(HistoricalRateProvider); ok && p.currency != "" {
+		p.hist = h
+	}
+	return p
+}
+
+func (p *fiatPricer) price(ctx context.Context, at time.Time) (decimal.Decimal, bool) {
+	if p.hist == nil || at.IsZero() {
+		return decimal.Zero, false
+	}
+	at = at.UTC()
+	month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	series, ok := p.months[month]
+	if !ok {
+		var err error
+		series, err = p.hist.TonPriceSeries(ctx, p.currency, month, month.AddDate(0, 1, 0))
+		if err != nil {
+			log.Printf("export: TON/%s rates for %s: %v", p.currency, month.Format("2006-01"), err)
+		}
+		p.months[month] = series
+	}
+	return series.At(at)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+type fakeHistoricalRates struct{ calls int }
+
+func (f *fakeHistoricalRates) Name() string { return "fake" }
+func (f *fakeHistoricalRates) TonPrice(context.Context, string) (decimal.Decimal, error) { return decimal.NewFromInt(9), nil }
+func (f *fakeHistoricalRates) TonPriceSeries(_ context.Context, _ string, from, to time.Time) (RateSeries, error) {
+	f.calls++
+	return RateSeries{{At: from, Price: decimal.NewFromInt(2)}, {At: from.Add(24 * time.Hour), Price: decimal.NewFromInt(3)}}, nil
+}
+
+// три перевода на двух страницах: 1 мар (вход), 2 мар (выход), 5 мар (вход)
+func exportMock(account string) *mockTonAPI {
+	ts := func(day int) *int64 { v := time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC).Unix(); return &v }
+	return &mockTonAPI{pageFn: func(_ context.Context, _ string, _ int, before int64) (Events, error) {
+		if before == 0 {
+			return Events{NextFrom: 7, Events: []Event{
+				{EventID: "E3", Timestamp: ts(5), Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_A", Recipient: account, Payload: &EventPayload{Type: "comment", Text: `say "hi", <b>`}}}},
+				{EventID: "E2", Timestamp: ts(2), Actions: []EventAction{{Type: "TonTransfer", Amount: "500000000", Sender: account, Recipient: "EQ_B"}}},
+			}}, nil
+		}
+		return Events{Events: []Event{
+			{EventID: "E1", Timestamp: ts(1), Actions: []EventAction{{Type: "TonTransfer", Amount: "2000000000", Sender: "EQ_A", Recipient: account}}},
+		}}, nil
+	}}
+}
+
+func TestExport_CSVPaginatesAndPricesInFiat(t *testing.T) {
+	rates := &fakeHistoricalRates{}
+	svc, err := NewExportService(NewTONServiceWithClient(exportMock("EQ_M")), rates, storage.NewMemory(), t.TempDir())
+	if err != nil { t.Fatalf("new: %v", err) }
+
+	var buf bytes.Buffer
+	rows, err := svc.Write(context.Background(), models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV, Currency: "usd",
+		From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)}, &buf)
+	if err != nil || rows != 2 { t.Fatalf("rows %d, err %v", rows, err) }
+	want := "timestamp,hash,direction,from,to,amount_ton,comment,fiat_currency,fiat_rate,fiat_amount\n" +
+		"2024-03-02T12:00:00Z,E2,out,EQ_M,EQ_B,0.5,,USD,3,1.5\n" +
+		"2024-03-01T12:00:00Z,E1,in,EQ_A,EQ_M,2,,USD,2,4\n"
+	if buf.String() != want { t.Fatalf("csv:\n%s", buf.String()) }
+	if rates.calls != 1 { t.Fatalf("rates fetched %d times, want once per month", rates.calls) }
+}
+
+func TestExport_CSVEscapesFormulasInTextCells(t *testing.T) {
+	api := &mockTonAPI{pageFn: func(context.Context, string, int, int64) (Events, error) {
+		ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Unix()
+		return Events{Events: []Event{{EventID: "E1", Timestamp: &ts, Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_A", Recipient: "EQ_M",
+			Payload: &EventPayload{Type: "comment", Text: `=HYPERLINK("http://evil","pay")`}}}}}}, nil
+	}}
+	svc, err := NewExportService(NewTONServiceWithClient(api), nil, storage.NewMemory(), t.TempDir())
+	if err != nil { t.Fatalf("new: %v", err) }
+
+	var buf bytes.Buffer
+	if _, err := svc.Write(context.Background(), models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV}, &buf); err != nil { t.Fatalf("write: %v", err) }
+	if !strings.Contains(buf.String(), `,1,"'=HYPERLINK(""http://evil"",""pay"")",`) { t.Fatalf("formula not escaped:\n%s", buf.String()) }
+	for _, v := range []string{"+1", "-1", "@SUM(A1)", "\tx", "\rx"} {
+		if got := csvText(v); got != "'"+v { t.Errorf("csvText(%q) = %q", v, got) }
+	}
+	if got := csvText("order 42"); got != "order 42" { t.Errorf("plain text changed: %q", got) }
+}
+
+func TestExport_XLSXAndJSONL(t *testing.T) {
+	svc, _ := NewExportService(NewTONServiceWithClient(exportMock("EQ_M")), nil, storage.NewMemory(), t.TempDir())
+
+	var buf bytes.Buffer
+	if _, err := svc.Write(context.Background(), models.ExportRequest{Account: "EQ_M", Format: models.ExportXLSX}, &buf); err != nil { t.Fatalf("xlsx: %v", err) }
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil { t.Fatalf("zip: %v", err) }
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			sheet = string(b)
+		}
+	}
+	if !strings.Contains(sheet, `<c r="F2"><v>1</v></c>`) || !strings.Contains(sheet, `say &#34;hi&#34;, &lt;b&gt;`) || !strings.Contains(sheet, `<row r="4">`) {
+		t.Fatalf("sheet: %s", sheet)
+	}
+
+	buf.Reset()
+	if _, err := svc.Write(context.Background(), models.ExportRequest{Account: "EQ_M", Format: models.ExportJSONL}, &buf); err != nil { t.Fatalf("jsonl: %v", err) }
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || strings.Contains(lines[1], "fiat") || !strings.Contains(lines[1], `"direction":"out"`) { t.Fatalf("jsonl: %s", buf.String()) }
+}
+
+func TestExport_AsyncJob(t *testing.T) {
+	svc, _ := NewExportService(NewTONServiceWithClient(exportMock("EQ_M")), nil, storage.NewMemory(), t.TempDir())
+	if _, err := svc.StartJob(models.ExportRequest{Account: "EQ_M", Format: "pdf"}); err != ErrExportFormat { t.Fatalf("want ErrExportFormat, got %v", err) }
+
+	job, err := svc.StartJob(models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV})
+	if err != nil { t.Fatalf("start: %v", err) }
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != models.ExportDone && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		job, _ = svc.Job(job.ID)
+	}
+	if job.Status != models.ExportDone || job.Rows != 3 || job.DownloadURL == "" || job.SizeBytes == 0 { t.Fatalf("job: %+v", job) }
+	if _, err := svc.JobFile(job); err != nil { t.Fatalf("file: %v", err) }
+}
+
+func TestExport_SkipsFailedAndBouncedTransfers(t *testing.T) {
+	api := &mockTonAPI{pageFn: func(context.Context, string, int, int64) (Events, error) {
+		ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Unix()
+		return Events{Events: []Event{
+			{EventID: "E3", Timestamp: &ts, Actions: []EventAction{{Type: "TonTransfer", Amount: "3000000000", Sender: "EQ_M", Recipient: "EQ_B", Bounced: true}}},
+			{EventID: "E2", Timestamp: &ts, Actions: []EventAction{{Type: "TonTransfer", Amount: "2000000000", Sender: "EQ_A", Recipient: "EQ_M", Status: models.TxStatusFailed}}},
+			{EventID: "E1", Timestamp: &ts, Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_A", Recipient: "EQ_M"}}},
+		}}, nil
+	}}
+	svc, err := NewExportService(NewTONServiceWithClient(api), nil, storage.NewMemory(), t.TempDir())
+	if err != nil { t.Fatalf("new: %v", err) }
+
+	var buf bytes.Buffer
+	rows, err := svc.Write(context.Background(), models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV}, &buf)
+	if err != nil || rows != 1 || strings.Contains(buf.String(), "E2") || strings.Contains(buf.String(), "E3") { t.Fatalf("rows %d, err %v:\n%s", rows, err, buf.String()) }
+}
+
+// Остановка сервера прерывает фоновую выгрузку; завершённые выгрузки удаляются по истечении TTL.
+func TestExport_JobsStopWithServerAndExpire(t *testing.T) {
+	release := make(chan struct{})
+	api := &mockTonAPI{pageFn: func(ctx context.Context, _ string, _ int, _ int64) (Events, error) {
+		select {
+		case <-release:
+			return Events{}, nil
+		case <-ctx.Done():
+			return Events{}, ctx.Err()
+		}
+	}}
+	svc, _ := NewExportService(NewTONServiceWithClient(api), nil, storage.NewMemory(), t.TempDir())
+	svc.SetTTL(time.Hour)
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { svc.Run(ctx); close(done) }()
+	for svc.jobContext() != ctx { time.Sleep(time.Millisecond) }
+
+	job, _ := svc.StartJob(models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV})
+	stop()
+	<-done
+	if err := svc.Wait(context.Background()); err != nil { t.Fatalf("wait: %v", err) }
+	if job, _ = svc.Job(job.ID); job.Status != models.ExportFailed { t.Fatalf("job after shutdown: %+v", job) }
+
+	close(release)
+	svc.ctx = context.Background()
+	finished, _ := svc.StartJob(models.ExportRequest{Account: "EQ_M", Format: models.ExportCSV})
+	svc.Wait(context.Background())
+	path, err := svc.JobFile(mustJob(t, svc, finished.ID))
+	if err != nil { t.Fatalf("file: %v", err) }
+
+	if n := svc.Purge(); n != 0 { t.Fatalf("fresh jobs purged: %d", n) }
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if n := svc.Purge(); n != 2 { t.Fatalf("purged %d jobs", n) }
+	if _, err := svc.Job(finished.ID); err != ErrExportNotFound { t.Fatalf("job kept: %v", err) }
+	if _, err := os.Stat(path); !os.IsNotExist(err) { t.Fatalf("file kept: %v", err) }
+}
+
+func mustJob(t *testing.T, svc *ExportService, id string) *models.ExportJob {
+	t.Helper()
+	j, err := svc.Job(id)
+	if err != nil { t.Fatalf("job %s: %v", id, err) }
+	return j
+}
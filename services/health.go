@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"payment-service/models"
+)
+
+// Группы проверок готовности.
+const (
+	HealthGroupStorage = "storage"
+	HealthGroupTON     = "ton" // провайдеры TON: достаточно одного рабочего
+	HealthGroupWatcher = "watcher"
+)
+
+// HealthCheck — проверка одной зависимости. Group пусто — проверка только для отчёта.
+type HealthCheck struct {
+	Name  string
+	Group string
+	Check func(ctx context.Context) error
+}
+
+// Health — проверки зависимостей для /readyz и /api/health. Сервис готов, если в каждой
+// группе прошла хотя бы одна проверка. Проверки выполняются параллельно, каждая — не дольше timeout.
+type Health struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	build   models.BuildInfo
+
+	mu          sync.Mutex
+	lastSuccess map[string]time.Time
+}
+
+func NewHealth(build models.BuildInfo, timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &Health{timeout: timeout, build: build, lastSuccess: map[string]time.Time{}}
+}
+
+func (h *Health) Add(c HealthCheck) {
+	h.checks = append(h.checks, c)
+}
+
+// Check — выполнить все проверки; Version (версию API) заполняет вызывающий.
+func (h *Health) Check(ctx context.Context) models.HealthStatus {
+	results := make([]models.DependencyHealth, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	st := models.HealthStatus{Timestamp: time.Now().UTC(), Build: h.build, Checks: results}
+	groups := map[string]bool{}
+	failed := false
+	for _, r := range results {
+		if r.Group != "" {
+			groups[r.Group] = groups[r.Group] || r.OK
+		}
+		failed = failed || !r.OK
+	}
+	st.Ready = true
+	for _, ok := range groups {
+		st.Ready = st.Ready && ok
+	}
+	switch {
+	case !st.Ready:
+		st.Status = models.HealthDown
+	case failed:
+		st.Status = models.HealthDegraded
+	default:
+		st.Status = models.HealthOK
+	}
+	return st
+}
+
+func (h *Health) run(ctx context.Context, c HealthCheck) models.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.Check(ctx)
+	r := models.DependencyHealth{Name: c.Name, Group: c.Group, OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+
+	h.mu.Lock()
+	if err == nil {
+		h.lastSuccess[c.Name] = start.UTC()
+	} else {
+		r.Error = err.Error()
+	}
+	if t, ok := h.lastSuccess[c.Name]; ok {
+		r.LastSuccess = &t
+	}
+	h.mu.Unlock()
+	return r
+}
+
+// WatcherLagCheck — watcher проходит по кошелькам не реже maxLag.
+func WatcherLagCheck(w *Watcher, maxLag time.Duration) HealthCheck {
+	return HealthCheck{Name: "watcher", Group: HealthGroupWatcher, Check: func(context.Context) error {
+		if lag := w.Lag(); lag > maxLag {
+			return fmt.Errorf("watcher lag %s exceeds %s", lag.Round(time.Second), maxLag)
+		}
+		return nil
+	}}
+}
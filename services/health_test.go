@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment-service/models"
+)
+
+func TestHealth_ReadyNeedsOneCheckPerGroup(t *testing.T) {
+	storageErr := error(nil)
+	h := NewHealth(models.BuildInfo{Version: "1.2.3", Commit: "abc"}, time.Second)
+	h.Add(HealthCheck{Name: "storage", Group: HealthGroupStorage, Check: func(context.Context) error { return storageErr }})
+	h.Add(HealthCheck{Name: "tonapi", Group: HealthGroupTON, Check: func(context.Context) error { return errors.New("timeout") }})
+	h.Add(HealthCheck{Name: "liteserver", Group: HealthGroupTON, Check: func(context.Context) error { return nil }})
+	h.Add(HealthCheck{Name: "slow", Check: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }})
+
+	st := h.Check(context.Background())
+	if !st.Ready || st.Status != models.HealthDegraded || st.Build.Commit != "abc" || len(st.Checks) != 4 { t.Fatalf("one TON provider down: %+v", st) }
+	byName := map[string]models.DependencyHealth{}
+	for _, c := range st.Checks {
+		byName[c.Name] = c
+	}
+	if c := byName["tonapi"]; c.OK || c.Error != "timeout" || c.LastSuccess != nil { t.Fatalf("tonapi: %+v", c) }
+	if c := byName["storage"]; !c.OK || c.LastSuccess == nil { t.Fatalf("storage: %+v", c) }
+	if c := byName["slow"]; c.OK || c.LatencyMs < 1000 { t.Fatalf("slow check is not bounded by timeout: %+v", c) }
+
+	storageErr = errors.New("read-only file system")
+	st = h.Check(context.Background())
+	if c := st.Checks[0]; st.Ready || st.Status != models.HealthDown || c.OK || c.LastSuccess == nil { t.Fatalf("storage down keeps last success: %+v", st) }
+}
+
+func TestWatcher_LagGrowsOnlyWhenNoWalletIsPolled(t *testing.T) {
+	fail := false
+	mock := &mockTonAPI{eventsFn: func(_ context.Context, accountID string, _ int) (Events, error) {
+		if fail || accountID == "EQ_BROKEN" {
+			return Events{}, errors.New("tonapi down")
+		}
+		return Events{}, nil
+	}}
+	w := NewWatcher(NewTONServiceWithClient(mock), []string{"EQ_MERCHANT", "EQ_BROKEN"}, time.Second)
+	check := WatcherLagCheck(w, 20*time.Millisecond)
+	if w.Lag() != 0 { t.Fatalf("lag before start: %s", w.Lag()) }
+
+	w.Poll(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	w.Poll(context.Background()) // один кошелёк с ошибкой не считается отставанием
+	if err := check.Check(context.Background()); err != nil { t.Fatalf("lag after partial poll: %v", err) }
+
+	fail = true
+	w.Poll(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	if err := check.Check(context.Background()); err == nil { t.Fatalf("lag %s not reported", w.Lag()) }
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"payment-service/models"
+)
+
+const (
+	historyPageSize = 100
+	// historyMaxPages — предел страниц на один запрос истории с фильтрами,
+	// чтобы редкий фильтр без from не обходил всю историю кошелька.
+	historyMaxPages = 50
+)
+
+var (
+	// errStopWalk — досрочное завершение обхода истории из колбэка.
+	errStopWalk = errors.New("stop walk")
+
+	ErrBadFilter = errors.New("bad transaction filter")
+)
+
+// eventTransactions — действия события в виде TransactionInfo с типом (Kind) и деталями.
+func eventTransactions(ev Event) []models.TransactionInfo {
+	var ts time.Time
+	if ev.Timestamp != nil && *ev.Timestamp > 0 {
+		ts = time.Unix(*ev.Timestamp, 0).UTC()
+	}
+	var out []models.TransactionInfo
+	for _, a := range ev.Actions {
+		t := models.TransactionInfo{
+			Hash:      ev.EventID,
+			From:      a.Sender,
+			To:        a.Recipient,
+			Status:    actionStatus(a),
+			Timestamp: ts,
+			Kind:      actionKind(a.Type),
+		}
+		if a.Payload != nil && equalsFold(a.Payload.Type, "comment") {
+			t.Comment = a.Payload.Text
+		}
+		if t.Kind == models.TxKindTonTransfer {
+			amt, err := nanosStrToTon(a.Amount)
+			if err != nil {
+				continue
+			}
+			t.Amount, t.Currency = amt.StringFixed(9), "TON"
+			out = append(out, t)
+			continue
+		}
+		t.Details = &models.TransactionDetails{}
+		if d := a.Details; d != nil {
+			t.Details.Description = d.Description
+			switch t.Kind {
+			case models.TxKindJettonTransfer:
+				t.Details.Jetton = jettonInfo(d.Jetton)
+				t.Amount, t.Currency = jettonAmount(d.JettonAmount, d.Jetton), jettonSymbol(d.Jetton)
+			case models.TxKindNftTransfer:
+				t.Details.NFT = d.Nft
+			case models.TxKindSwap:
+				t.Details.Dex = d.Dex
+				t.Details.AssetIn, t.Details.AmountIn = jettonSymbol(d.JettonIn), jettonAmount(d.AmountIn, d.JettonIn)
+				t.Details.AssetOut, t.Details.AmountOut = jettonSymbol(d.JettonOut), jettonAmount(d.AmountOut, d.JettonOut)
+				t.Amount, t.Currency = t.Details.AmountIn, t.Details.AssetIn
+			case models.TxKindContractDeploy, models.TxKindContractCall:
+				t.Details.Contract, t.Details.Interfaces, t.Details.Operation = d.Contract, d.Interfaces, d.Operation
+			}
+		}
+		if t.Kind == models.TxKindOther {
+			t.Details.ActionType = a.Type
+		}
+		if t.Amount == "" && a.Amount != "" {
+			// TON, приложенные к вызову контракта (и к неизвестным действиям)
+			if amt, err := nanosStrToTon(a.Amount); err == nil {
+				t.Amount, t.Currency = amt.StringFixed(9), "TON"
+			}
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// actionStatus — models.TxStatus* действия: отскок важнее статуса TonAPI.
+func actionStatus(a EventAction) string {
+	switch {
+	case a.Bounced:
+		return models.TxStatusBounced
+	case a.Status != "" && !equalsFold(a.Status, models.TxStatusOK):
+		return models.TxStatusFailed
+	}
+	return models.TxStatusOK
+}
+
+// settled — действие дошло до получателя; только такие переводы считаются оплатой.
+func settled(a EventAction) bool { return actionStatus(a) == models.TxStatusOK }
+
+// actionKind — тип действия TonAPI -> models.TxKind*.
+func actionKind(actionType string) string {
+	switch actionType {
+	case "TonTransfer":
+		return models.TxKindTonTransfer
+	case "JettonTransfer":
+		return models.TxKindJettonTransfer
+	case "NftItemTransfer":
+		return models.TxKindNftTransfer
+	case "JettonSwap":
+		return models.TxKindSwap
+	case "ContractDeploy":
+		return models.TxKindContractDeploy
+	case "SmartContractExec":
+		return models.TxKindContractCall
+	}
+	return models.TxKindOther
+}
+
+func jettonInfo(j *JettonRef) *models.JettonInfo {
+	if j == nil {
+		return nil
+	}
+	return &models.JettonInfo{Address: j.Address, Symbol: j.Symbol, Name: j.Name, Decimals: j.Decimals}
+}
+
+// jettonSymbol — символ джеттона (адрес мастера, если символа нет); nil — TON.
+func jettonSymbol(j *JettonRef) string {
+	switch {
+	case j == nil:
+		return "TON"
+	case j.Symbol != "":
+		return j.Symbol
+	}
+	return j.Address
+}
+
+// jettonAmount — минимальные единицы -> сумма с учётом decimals (nil — TON, 9 знаков).
+func jettonAmount(units string, j *JettonRef) string {
+	d, err := decimal.NewFromString(units)
+	if err != nil {
+		return ""
+	}
+	decimals := int32(9)
+	if j != nil {
+		decimals = int32(j.Decimals)
+	}
+	return d.Shift(-decimals).String()
+}
+
+// WalkTransactions — все операции аккаунта за [from, to) от новых к старым,
+// постранично через TonAPI. Нулевые from/to — без ограничения.
+// Обход останавливается на первом событии старше from или по ошибке из fn.
+func (s *TONService) WalkTransactions(ctx context.Context, accountID string, from, to time.Time, fn func(models.TransactionInfo) error) (err error) {
+	ctx, span := startSpan(ctx, "TONService.WalkTransactions", attribute.String("ton.account", accountID))
+	defer func() { endSpan(span, err) }()
+	return s.walkTransactions(ctx, accountID, from, to, 0, fn)
+}
+
+// walkTransactions — maxPages > 0 ограничивает число запрошенных страниц;
+// fn может вернуть errStopWalk, чтобы закончить обход без ошибки.
+func (s *TONService) walkTransactions(ctx context.Context, accountID string, from, to time.Time, maxPages int, fn func(models.TransactionInfo) error) error {
+	var before int64
+	for page := 1; ; page++ {
+		evs, err := s.client.GetAccountEventsPage(ctx, accountID, historyPageSize, before)
+		if err != nil {
+			return fmt.Errorf("GetAccountEvents: %w", err)
+		}
+		for _, ev := range evs.Events {
+			if ev.Timestamp != nil && *ev.Timestamp > 0 {
+				ts := time.Unix(*ev.Timestamp, 0)
+				if !to.IsZero() && !ts.Before(to) {
+					continue
+				}
+				if !from.IsZero() && ts.Before(from) {
+					return nil
+				}
+			}
+			for _, t := range eventTransactions(ev) {
+				if err := fn(t); err != nil {
+					if errors.Is(err, errStopWalk) {
+						return nil
+					}
+					return err
+				}
+			}
+		}
+		if evs.NextFrom == 0 || len(evs.Events) == 0 || (maxPages > 0 && page >= maxPages) {
+			return nil
+		}
+		before = evs.NextFrom
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// transferMatcher — проверка перевода по фильтру; заодно проставляет Direction.
+func transferMatcher(accountID string, f models.TransactionFilter) (func(*models.TransactionInfo) bool, error) {
+	var minAmt, maxAmt *decimal.Decimal
+	for _, p := range []struct {
+		name, val string
+		dst       **decimal.Decimal
+	}{{"min_amount", f.MinAmount, &minAmt}, {"max_amount", f.MaxAmount, &maxAmt}} {
+		if p.val == "" {
+			continue
+		}
+		d, err := decimal.NewFromString(p.val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q", ErrBadFilter, p.name, p.val)
+		}
+		*p.dst = &d
+	}
+	direction := strings.ToLower(f.Direction)
+	if direction != "" && direction != "in" && direction != "out" {
+		return nil, fmt.Errorf("%w: direction %q, use in or out", ErrBadFilter, f.Direction)
+	}
+	var kinds map[string]bool
+	for _, k := range f.Types {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !slices.Contains(models.TxKinds, k) {
+			return nil, fmt.Errorf("%w: type %q, use %s", ErrBadFilter, k, strings.Join(models.TxKinds, ", "))
+		}
+		if kinds == nil {
+			kinds = make(map[string]bool)
+		}
+		kinds[k] = true
+	}
+	prefix := false
+	switch strings.ToLower(f.CommentMatch) {
+	case "", "exact":
+	case "prefix":
+		prefix = true
+	default:
+		return nil, fmt.Errorf("%w: comment_match %q, use exact or prefix", ErrBadFilter, f.CommentMatch)
+	}
+
+	return func(t *models.TransactionInfo) bool {
+		if kinds != nil && !kinds[t.Kind] {
+			return false
+		}
+		t.Direction = "out"
+		counterparty := t.To
+		if SameAddress(t.To, accountID) {
+			t.Direction, counterparty = "in", t.From
+		}
+		if direction != "" && t.Direction != direction {
+			return false
+		}
+		if f.Counterparty != "" && !SameAddress(counterparty, f.Counterparty) {
+			return false
+		}
+		if f.Comment != "" {
+			if prefix && !strings.HasPrefix(t.Comment, f.Comment) || !prefix && t.Comment != f.Comment {
+				return false
+			}
+		}
+		if minAmt != nil || maxAmt != nil {
+			// границы в TON: 5 USDT не проходит min_amount=1
+			if t.Currency != "TON" {
+				return false
+			}
+			amt, err := decimal.NewFromString(t.Amount)
+			if err != nil || minAmt != nil && amt.LessThan(*minAmt) || maxAmt != nil && amt.GreaterThan(*maxAmt) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Типы событий, которые рассылает Hub.
+const (
+	HubEventTransfer = "transfer" // входящий перевод на отслеживаемый кошелёк
+	HubEventInvoice  = "invoice"  // смена статуса инвойса
+	HubEventPayout   = "payout"   // выплата или возврат записаны
+)
+
+type HubEvent struct {
+	Type      string    `json:"type"`
+	Wallet    string    `json:"wallet,omitempty"`
+	InvoiceID string    `json:"invoice_id,omitempty"`
+	Data      any       `json:"data,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Hub — широковещательная рассылка событий подписчикам (WebSocket-клиентам).
+// Publish никогда не блокируется: если буфер подписчика переполнен,
+// подписчик считается медленным и отключается.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[*Subscriber]struct{}
+	closed bool
+}
+
+func NewHub() *Hub { return &Hub{subs: make(map[*Subscriber]struct{})} }
+
+type Subscriber struct {
+	ch   chan HubEvent
+	done chan struct{}
+	once sync.Once
+
+	mu       sync.RWMutex
+	wallets  map[string]struct{}
+	invoices map[string]struct{}
+	// allowed — ограничение на кошельки, на которые вообще можно подписаться (nil — без ограничений)
+	allowed map[string]struct{}
+}
+
+func (h *Hub) Subscribe(buffer int) *Subscriber {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	s := &Subscriber{
+		ch:       make(chan HubEvent, buffer),
+		done:     make(chan struct{}),
+		wallets:  make(map[string]struct{}),
+		invoices: make(map[string]struct{}),
+	}
+	h.mu.Lock()
+	if h.closed {
+		s.close()
+	} else {
+		h.subs[s] = struct{}{}
+	}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+	s.close()
+}
+
+// Publish — доставляет событие всем подписчикам, чьи фильтры его пропускают.
+func (h *Hub) Publish(ev HubEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	h.mu.RLock()
+	var slow []*Subscriber
+	for s := range h.subs {
+		if !s.matches(ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.Unsubscribe(s)
+	}
+}
+
+// PublishTransfer — хук для Watcher: входящий перевод уходит подписчикам кошелька.
+func (h *Hub) PublishTransfer(t IncomingTransfer) {
+	h.Publish(HubEvent{Type: HubEventTransfer, Wallet: t.Wallet, Data: t, Time: t.Timestamp})
+}
+
+// PublishPayout — выплата или возврат с кошелька: проводка журнала или потерянный платёж, помеченный к возврату.
+func (h *Hub) PublishPayout(wallet string, data any) {
+	h.Publish(HubEvent{Type: HubEventPayout, Wallet: wallet, Data: data})
+}
+
+// Close — отключает всех подписчиков при остановке сервера; новые подписки сразу закрыты.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	subs := h.subs
+	h.subs, h.closed = make(map[*Subscriber]struct{}), true
+	h.mu.Unlock()
+	for s := range subs {
+		s.close()
+	}
+}
+
+func (h *Hub) Closed() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.closed
+}
+
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// C — канал событий подписчика.
+func (s *Subscriber) C() <-chan HubEvent { return s.ch }
+
+// Done закрывается, когда подписчик отписан (в т.ч. принудительно как медленный).
+func (s *Subscriber) Done() <-chan struct{} { return s.done }
+
+func (s *Subscriber) close() { s.once.Do(func() { close(s.done) }) }
+
+// Кошельки подписчика хранятся по каноническому адресу (LedgerWallet): EQ…, UQ… и raw "0:hex"
+// одного кошелька совпадают.
+
+// Restrict — ограничивает набор кошельков, на которые можно подписаться (nil — без ограничений).
+// Уже оформленные подписки на кошельки вне набора снимаются.
+func (s *Subscriber) Restrict(wallets []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if wallets == nil {
+		s.allowed = nil
+		return
+	}
+	s.allowed = make(map[string]struct{}, len(wallets))
+	for _, w := range wallets {
+		s.allowed[LedgerWallet(w)] = struct{}{}
+	}
+	for k := range s.wallets {
+		if _, ok := s.allowed[k]; !ok {
+			delete(s.wallets, k)
+		}
+	}
+}
+
+// Watch — добавляет кошельки/инвойсы в подписку. Возвращает кошельки, в которых отказано.
+func (s *Subscriber) Watch(wallets, invoiceIDs []string) (denied []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range wallets {
+		k := LedgerWallet(w)
+		if s.allowed != nil {
+			if _, ok := s.allowed[k]; !ok {
+				denied = append(denied, w)
+				continue
+			}
+		}
+		s.wallets[k] = struct{}{}
+	}
+	for _, id := range invoiceIDs {
+		s.invoices[normKey(id)] = struct{}{}
+	}
+	return denied
+}
+
+func (s *Subscriber) Unwatch(wallets, invoiceIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range wallets {
+		delete(s.wallets, LedgerWallet(w))
+	}
+	for _, id := range invoiceIDs {
+		delete(s.invoices, normKey(id))
+	}
+}
+
+func (s *Subscriber) matches(ev HubEvent) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.allowed != nil && ev.Wallet != "" {
+		// подписка по id инвойса не даёт видеть чужие кошельки
+		if _, ok := s.allowed[LedgerWallet(ev.Wallet)]; !ok {
+			return false
+		}
+	}
+	if ev.InvoiceID != "" {
+		if _, ok := s.invoices[normKey(ev.InvoiceID)]; ok {
+			return true
+		}
+	}
+	if ev.Wallet != "" {
+		if _, ok := s.wallets[LedgerWallet(ev.Wallet)]; ok {
+			return true
+		}
+	}
+	return false
+}
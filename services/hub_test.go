@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+func TestHub_FiltersByWalletAndInvoice(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(4)
+	defer hub.Unsubscribe(sub)
+	sub.Watch([]string{"EQ_MERCHANT"}, []string{"INV-1"})
+
+	hub.Publish(HubEvent{Type: HubEventTransfer, Wallet: "EQ_OTHER"})
+	hub.Publish(HubEvent{Type: HubEventTransfer, Wallet: "eq_merchant"})
+	hub.Publish(HubEvent{Type: HubEventInvoice, InvoiceID: "INV-1"})
+
+	if got := len(sub.C()); got != 2 {
+		t.Fatalf("want 2 delivered events, got %d", got)
+	}
+}
+
+func TestHub_RestrictDeniesForeignWallets(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(4)
+	defer hub.Unsubscribe(sub)
+	sub.Restrict([]string{"EQ_MINE"})
+
+	denied := sub.Watch([]string{"EQ_MINE", "EQ_FOREIGN"}, nil)
+	if len(denied) != 1 || denied[0] != "EQ_FOREIGN" {
+		t.Fatalf("unexpected denied: %v", denied)
+	}
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(1)
+	sub.Watch([]string{"EQ_MERCHANT"}, nil)
+
+	hub.Publish(HubEvent{Wallet: "EQ_MERCHANT"})
+	hub.Publish(HubEvent{Wallet: "EQ_MERCHANT"}) // буфер полон
+
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("slow subscriber was not dropped")
+	}
+	if hub.Len() != 0 {
+		t.Fatalf("hub still has %d subscribers", hub.Len())
+	}
+}
+
+func TestHub_RestrictedSubscriberIgnoresForeignInvoices(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(4)
+	defer hub.Unsubscribe(sub)
+	sub.Restrict([]string{"EQ_MINE"})
+	sub.Watch(nil, []string{"inv_foreign"})
+
+	hub.Publish(HubEvent{Type: HubEventInvoice, Wallet: "EQ_OTHER", InvoiceID: "inv_foreign"})
+	if len(sub.C()) != 0 { t.Fatalf("foreign invoice event delivered") }
+}
+
+func TestHub_MatchesAddressFormsOfOneWallet(t *testing.T) {
+	const friendly = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+	const raw = "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8"
+	const nonBounceable = "UQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqEBI"
+	hub := NewHub()
+	sub := hub.Subscribe(4)
+	defer hub.Unsubscribe(sub)
+	sub.Restrict([]string{raw}) // сессия ton_proof: raw-адрес
+
+	if denied := sub.Watch([]string{friendly, nonBounceable}, nil); len(denied) != 0 { t.Fatalf("own wallet denied: %v", denied) }
+	hub.Publish(HubEvent{Type: HubEventTransfer, Wallet: friendly})
+	if got := len(sub.C()); got != 1 { t.Fatalf("want 1 delivered event, got %d", got) }
+}
+
+// Выплата, записанная в журнал, и потерянный платёж, помеченный к возврату, доходят до подписчика кошелька.
+func TestHub_PayoutEventsReachWalletSubscribers(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(4)
+	defer hub.Unsubscribe(sub)
+	sub.Watch([]string{"EQ_MERCHANT"}, nil)
+
+	hub.PublishPayout(LedgerWallet("EQ_MERCHANT"), ledger.Payout("p1", LedgerWallet("EQ_MERCHANT"), "0:b", decimal.NewFromInt(1), decimal.Zero, ledger.CurrencyTON, time.Now()))
+	hub.PublishPayout("EQ_OTHER", nil)
+
+	rates, _ := NewStaticRateProvider(map[string]string{"USD": "5"})
+	invoices := NewInvoiceService(storage.NewMemory(), rates, hub, "EQ_MERCHANT", 1, time.Hour)
+	orphans := NewOrphanService(storage.NewMemory(), invoices)
+	orphans.Record(IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "1"}, "EQ_MERCHANT")
+	if _, err := orphans.MarkRefund("E1", "wrong shop"); err != nil { t.Fatalf("refund: %v", err) }
+
+	if got := len(sub.C()); got != 2 { t.Fatalf("want 2 payout events, got %d", got) }
+	if ev := <-sub.C(); ev.Type != HubEventPayout { t.Fatalf("ledger payout: %+v", ev) }
+	if ev := <-sub.C(); ev.Type != HubEventPayout || ev.Data.(models.OrphanPayment).Status != models.OrphanRefund { t.Fatalf("orphan refund: %+v", ev) }
+}
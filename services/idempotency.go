@@ -0,0 +1,121 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	idempotencyCollection = "idempotency"
+	idempotencyPurgeEvery = time.Minute
+)
+
+var (
+	ErrIdempotencyMismatch = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInFlight = errors.New("request with this idempotency key is still in progress")
+)
+
+// IdempotencyService — первый ответ на каждый (scope, Idempotency-Key) хранится ttl
+// и отдаётся повторно, чтобы ретраи клиента не создавали дублей счетов и выплат.
+type IdempotencyService struct {
+	store *storage.Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	inflight  map[string]string // id -> fingerprint выполняющегося запроса
+	lastPurge time.Time
+}
+
+func NewIdempotencyService(store *storage.Store, ttl time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &IdempotencyService{store: store, ttl: ttl, now: time.Now, inflight: make(map[string]string)}
+}
+
+func idempotencyID(scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin — сохранённый ответ для повтора или nil, если запрос нужно выполнить;
+// во втором случае ключ занят до Complete/Release.
+func (s *IdempotencyService) Begin(scope, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	id := idempotencyID(scope, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeLocked()
+
+	if fp, ok := s.inflight[id]; ok {
+		if fp != fingerprint {
+			return nil, ErrIdempotencyMismatch
+		}
+		return nil, ErrIdempotencyInFlight
+	}
+	var rec models.IdempotencyRecord
+	ok, err := s.store.Get(idempotencyCollection, id, &rec)
+	if err != nil {
+		return nil, err
+	}
+	if ok && s.now().Before(rec.ExpiresAt) {
+		if rec.Fingerprint != fingerprint {
+			return nil, ErrIdempotencyMismatch
+		}
+		return &rec, nil
+	}
+	s.inflight[id] = fingerprint
+	return nil, nil
+}
+
+// Complete — сохраняет ответ и освобождает ключ.
+func (s *IdempotencyService) Complete(scope, key string, status int, contentType string, body []byte) error {
+	id := idempotencyID(scope, key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp, ok := s.inflight[id]
+	if !ok {
+		return nil
+	}
+	delete(s.inflight, id)
+	now := s.now().UTC()
+	return s.store.Put(idempotencyCollection, id, models.IdempotencyRecord{
+		Scope: scope, Key: key, Fingerprint: fp,
+		Status: status, ContentType: contentType, Body: body,
+		CreatedAt: now, ExpiresAt: now.Add(s.ttl),
+	})
+}
+
+// Release — освобождает ключ без сохранения ответа (ошибка сервера, паника):
+// повтор с тем же ключом выполнится заново.
+func (s *IdempotencyService) Release(scope, key string) {
+	s.mu.Lock()
+	delete(s.inflight, idempotencyID(scope, key))
+	s.mu.Unlock()
+}
+
+// purgeLocked — удаление просроченных записей, не чаще раза в idempotencyPurgeEvery.
+func (s *IdempotencyService) purgeLocked() {
+	now := s.now()
+	if now.Sub(s.lastPurge) < idempotencyPurgeEvery {
+		return
+	}
+	s.lastPurge = now
+	for _, id := range s.store.Keys(idempotencyCollection) {
+		var rec models.IdempotencyRecord
+		if ok, err := s.store.Get(idempotencyCollection, id, &rec); ok && err == nil && now.Before(rec.ExpiresAt) {
+			continue
+		}
+		if err := s.store.Delete(idempotencyCollection, id); err != nil {
+			log.Printf("idempotency purge: %v", err)
+			return
+		}
+	}
+}
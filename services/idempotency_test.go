@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"payment-service/storage"
+)
+
+func TestIdempotency_ReplayMismatchAndExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := storage.NewMemory()
+	svc := NewIdempotencyService(store, time.Hour)
+	svc.now = func() time.Time { return now }
+
+	if rec, err := svc.Begin("merchant:m1", "k1", "fp1"); rec != nil || err != nil { t.Fatalf("first: %v %v", rec, err) }
+	if _, err := svc.Begin("merchant:m1", "k1", "fp1"); err != ErrIdempotencyInFlight { t.Fatalf("in flight: %v", err) }
+	if _, err := svc.Begin("merchant:m1", "k1", "fp2"); err != ErrIdempotencyMismatch { t.Fatalf("in flight mismatch: %v", err) }
+	// тот же ключ у другого мерчанта — независим
+	if rec, err := svc.Begin("merchant:m2", "k1", "fp2"); rec != nil || err != nil { t.Fatalf("other scope: %v %v", rec, err) }
+	svc.Release("merchant:m2", "k1")
+
+	if err := svc.Complete("merchant:m1", "k1", 201, "application/json", []byte(`{"id":"inv_1"}`)); err != nil { t.Fatalf("complete: %v", err) }
+	rec, err := svc.Begin("merchant:m1", "k1", "fp1")
+	if err != nil || rec == nil || rec.Status != 201 || string(rec.Body) != `{"id":"inv_1"}` { t.Fatalf("replay: %+v %v", rec, err) }
+	if _, err := svc.Begin("merchant:m1", "k1", "fp2"); err != ErrIdempotencyMismatch { t.Fatalf("mismatch: %v", err) }
+
+	now = now.Add(2 * time.Hour)
+	if rec, err := svc.Begin("merchant:m1", "k1", "fp2"); rec != nil || err != nil { t.Fatalf("expired: %v %v", rec, err) }
+	if len(store.Keys(idempotencyCollection)) != 0 { t.Fatalf("expired record not purged") }
+}
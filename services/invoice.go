@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/config"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const invoicesCollection = "invoices"
+
+var (
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	ErrCommentInUse    = errors.New("comment is already used by an open invoice")
+	ErrInvoicePaid     = errors.New("invoice is already paid")
+	ErrInvoiceMismatch = errors.New("payment recipient does not belong to the invoice merchant")
+)
+
+// InvoiceService — инвойсы с фиксацией суммы в TON на момент создания.
+// Оплата сопоставляется хуком OnTransfer из Watcher (кошелёк + комментарий + сумма).
+type InvoiceService struct {
+	store         *storage.Store
+	rates         RateProvider
+	hub           *Hub
+	watcher       *Watcher
+	deposits      *DepositService
+	orphans       *OrphanService
+	ledger        *ledger.Ledger
+	live          *config.Live // если задан — кошелёк по умолчанию берётся из APP_WALLET снимка
+	defaultWallet string
+	slippagePct   decimal.Decimal
+	ttl           time.Duration
+	now           func() time.Time
+
+	mu sync.Mutex
+	// индекс открытых инвойсов: сопоставление перевода не обходит всё хранилище.
+	// Строится из хранилища при первом обращении (nil — ещё не построен).
+	byComment map[string]string   // кошелёк + комментарий -> id
+	byDeposit map[string][]string // депозитный адрес -> id
+}
+
+func NewInvoiceService(store *storage.Store, rates RateProvider, hub *Hub, defaultWallet string, slippagePct float64, ttl time.Duration) *InvoiceService {
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	return &InvoiceService{
+		store:         store,
+		rates:         rates,
+		hub:           hub,
+		defaultWallet: defaultWallet,
+		slippagePct:   decimal.NewFromFloat(slippagePct),
+		ttl:           ttl,
+		now:           time.Now,
+	}
+}
+
+// SetWatcher — кошельки новых инвойсов автоматически добавляются в опрос.
+func (s *InvoiceService) SetWatcher(w *Watcher) { s.watcher = w }
+
+// SetDeposits — включает режим оплаты на депозитный адрес (mode=deposit).
+func (s *InvoiceService) SetDeposits(d *DepositService) { s.deposits = d }
+
+// SetOrphans — несопоставленные переводы попадают в «потерянные» платежи.
+func (s *InvoiceService) SetOrphans(o *OrphanService) { s.orphans = o }
+
+// SetLedger — подтверждённые оплаты проводятся в журнал.
+func (s *InvoiceService) SetLedger(l *ledger.Ledger) { s.ledger = l }
+
+// SetConfig — кошелёк по умолчанию следует за перезагрузкой APP_WALLET.
+func (s *InvoiceService) SetConfig(live *config.Live) { s.live = live }
+
+// DefaultWallet — кошелёк инвойса без merchant_address.
+func (s *InvoiceService) DefaultWallet() string {
+	if s.live != nil {
+		return s.live.Get().AppWallet
+	}
+	return s.defaultWallet
+}
+
+func (s *InvoiceService) Create(ctx context.Context, req models.CreateInvoiceRequest) (*models.Invoice, error) {
+	wallet := strings.TrimSpace(req.MerchantAddress)
+	if wallet == "" {
+		wallet = s.DefaultWallet()
+	}
+	if wallet == "" {
+		return nil, fmt.Errorf("merchant_address is required")
+	}
+
+	slippage := s.slippagePct
+	if req.SlippagePct != "" {
+		d, err := decimal.NewFromString(req.SlippagePct)
+		if err != nil || d.IsNegative() || d.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+			return nil, fmt.Errorf("bad slippage_pct %q", req.SlippagePct)
+		}
+		slippage = d
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	switch mode {
+	case "":
+		mode = models.InvoiceModeComment
+	case models.InvoiceModeComment:
+	case models.InvoiceModeDeposit:
+		if s.deposits == nil {
+			return nil, ErrDepositsDisabled
+		}
+	default:
+		return nil, fmt.Errorf("bad mode %q", req.Mode)
+	}
+
+	now := s.now().UTC()
+	inv := &models.Invoice{
+		ID:              "inv_" + randomHex(8),
+		MerchantID:      req.MerchantID,
+		MerchantAddress: wallet,
+		Mode:            mode,
+		CustomerID:      strings.TrimSpace(req.CustomerID),
+		Comment:         strings.TrimSpace(req.Comment),
+		Status:          models.InvoicePending,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.ttl),
+	}
+	if req.TTLSec > 0 {
+		inv.ExpiresAt = now.Add(time.Duration(req.TTLSec) * time.Second)
+	}
+	if inv.Comment == "" && mode == models.InvoiceModeComment {
+		inv.Comment = "INV-" + strings.ToUpper(randomHex(4))
+	}
+
+	var amountTon decimal.Decimal
+	switch {
+	case req.FiatAmount != "" && req.AmountTon != "":
+		return nil, fmt.Errorf("specify either amount_ton or fiat_amount")
+	case req.FiatAmount != "":
+		if req.FiatCurrency == "" {
+			return nil, fmt.Errorf("fiat_currency is required with fiat_amount")
+		}
+		if s.rates == nil {
+			return nil, fmt.Errorf("fiat pricing is not configured")
+		}
+		fiat, err := decimal.NewFromString(req.FiatAmount)
+		if err != nil || !fiat.IsPositive() {
+			return nil, fmt.Errorf("bad fiat_amount %q", req.FiatAmount)
+		}
+		cur := strings.ToUpper(strings.TrimSpace(req.FiatCurrency))
+		price, err := s.rates.TonPrice(ctx, cur)
+		if err != nil {
+			return nil, fmt.Errorf("rate %s: %w", cur, err)
+		}
+		// округляем вверх до нанотона, чтобы не недобрать
+		amountTon = fiat.Div(price).RoundCeil(9)
+		inv.FiatAmount = fiat.String()
+		inv.FiatCurrency = cur
+		inv.Rate = &models.LockedRate{Currency: cur, TonPrice: price.String(), Source: s.rates.Name(), LockedAt: now}
+		inv.SlippagePct = slippage.String()
+	case req.AmountTon != "":
+		d, err := decimal.NewFromString(req.AmountTon)
+		if err != nil {
+			return nil, fmt.Errorf("bad amount_ton: %w", err)
+		}
+		amountTon = d.Truncate(9)
+		// сумма в TON задана явно — курсового риска нет, допуск не применяем
+		slippage = decimal.Zero
+	default:
+		return nil, fmt.Errorf("amount_ton or fiat_amount is required")
+	}
+	if !amountTon.IsPositive() {
+		return nil, fmt.Errorf("invoice amount must be positive")
+	}
+	minTon := amountTon.Mul(decimal.NewFromInt(100).Sub(slippage)).Div(decimal.NewFromInt(100)).Truncate(9)
+	inv.AmountTon = amountTon.StringFixed(9)
+	inv.MinAmountTon = minTon.StringFixed(9)
+
+	if mode == models.InvoiceModeDeposit {
+		d, err := s.deposits.Allocate(wallet, inv.CustomerID, inv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("deposit address: %w", err)
+		}
+		inv.DepositAddress = d.Address
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.indexLocked()
+	if mode == models.InvoiceModeComment && s.openByComment(wallet, inv.Comment) != nil {
+		return nil, ErrCommentInUse
+	}
+	if err := s.store.Put(invoicesCollection, inv.ID, inv); err != nil {
+		return nil, err
+	}
+	if s.byComment != nil {
+		s.trackLocked(inv)
+	}
+	if s.watcher != nil && mode == models.InvoiceModeComment {
+		s.watcher.AddWallet(wallet)
+	}
+	s.publish(inv)
+	return inv, nil
+}
+
+func (s *InvoiceService) Get(id string) (*models.Invoice, error) {
+	var inv models.Invoice
+	ok, err := s.store.Get(invoicesCollection, id, &inv)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(&inv)
+	return &inv, nil
+}
+
+// List — все инвойсы (по id).
+func (s *InvoiceService) List() ([]models.Invoice, error) {
+	return storage.List[models.Invoice](s.store, invoicesCollection)
+}
+
+// CheckRequest — параметры для CheckPayment по инвойсу
+// (для депозитного режима — адрес депозита, перевод без комментария).
+func (s *InvoiceService) CheckRequest(inv *models.Invoice) models.CheckPaymentRequest {
+	if inv.DepositAddress != "" {
+		return models.CheckPaymentRequest{MerchantAddress: inv.DepositAddress, MinAmountTon: inv.MinAmountTon}
+	}
+	return models.CheckPaymentRequest{MerchantAddress: inv.MerchantAddress, Comment: inv.Comment, MinAmountTon: inv.MinAmountTon}
+}
+
+// OnTransfer — хук Watcher: помечает оплаченным открытый инвойс — по депозитному
+// адресу получателя, либо по кошельку мерчанта и комментарию. Перевод, не закрывший
+// ни одного инвойса, записывается в «потерянные» платежи — кроме сметаний с депозитов.
+func (s *InvoiceService) OnTransfer(_ context.Context, t IncomingTransfer) {
+	if s.apply(t) || s.orphans == nil {
+		return
+	}
+	if s.deposits != nil && s.deposits.IsSweep(t) {
+		return
+	}
+	s.orphans.Record(t, s.merchantOf(t.Wallet))
+}
+
+func (s *InvoiceService) apply(t IncomingTransfer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv := s.openByDeposit(t.Wallet)
+	if inv == nil {
+		inv = s.openByComment(t.Wallet, t.Comment)
+	}
+	if inv == nil {
+		return false
+	}
+	paid, err := decimal.NewFromString(t.Amount)
+	if err != nil || paid.LessThan(decimal.RequireFromString(inv.MinAmountTon)) {
+		return false
+	}
+	return s.markPaidLocked(inv, t) == nil
+}
+
+// AttachPayment — ручная привязка перевода к инвойсу (в т.ч. просроченному).
+// Сумма не проверяется: решение принимает оператор.
+func (s *InvoiceService) AttachPayment(id string, t IncomingTransfer) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var inv models.Invoice
+	ok, err := s.store.Get(invoicesCollection, id, &inv)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	if inv.Status == models.InvoicePaid {
+		return nil, ErrInvoicePaid
+	}
+	if !SameAddress(inv.MerchantAddress, s.merchantOf(t.Wallet)) {
+		return nil, fmt.Errorf("%w: %s", ErrInvoiceMismatch, t.Wallet)
+	}
+	if err := s.markPaidLocked(&inv, t); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *InvoiceService) markPaidLocked(inv *models.Invoice, t IncomingTransfer) error {
+	at := t.Timestamp
+	if at.IsZero() {
+		at = s.now().UTC()
+	}
+	inv.Status = models.InvoicePaid
+	inv.PaidAt = &at
+	inv.PaidAmountTon = t.Amount
+	inv.TxHash = t.EventID
+	inv.Sender = t.Sender
+	if err := s.store.Put(invoicesCollection, inv.ID, inv); err != nil {
+		log.Printf("invoice %s: save paid: %v", inv.ID, err)
+		return err
+	}
+	s.untrackLocked(inv)
+	if e, err := invoicePaymentEntry(inv); err != nil {
+		log.Printf("invoice %s: ledger: %v", inv.ID, err)
+	} else {
+		postLedger(s.ledger, e)
+	}
+	s.publish(inv)
+	return nil
+}
+
+// merchantOf — кошелёк мерчанта, на который в итоге приходят средства с адреса
+// (для депозитного адреса — его владелец).
+func (s *InvoiceService) merchantOf(wallet string) string {
+	if s.deposits != nil {
+		if d, err := s.deposits.ByAddress(wallet); err == nil {
+			return d.MerchantAddress
+		}
+	}
+	return wallet
+}
+
+// openByComment — открытый (pending, не просроченный) инвойс кошелька с комментарием.
+func (s *InvoiceService) openByComment(wallet, comment string) *models.Invoice {
+	if comment == "" || !s.indexLocked() {
+		return nil
+	}
+	id, ok := s.byComment[commentKey(wallet, comment)]
+	if !ok {
+		return nil
+	}
+	return s.openLocked(id)
+}
+
+// openByDeposit — самый старый открытый инвойс на депозитный адрес
+// (адрес покупателя может использоваться несколькими инвойсами).
+func (s *InvoiceService) openByDeposit(wallet string) *models.Invoice {
+	if !s.indexLocked() {
+		return nil
+	}
+	var found *models.Invoice
+	for _, id := range append([]string(nil), s.byDeposit[LedgerWallet(wallet)]...) {
+		if inv := s.openLocked(id); inv != nil && (found == nil || inv.CreatedAt.Before(found.CreatedAt)) {
+			found = inv
+		}
+	}
+	return found
+}
+
+// openLocked — инвойс из индекса, если он всё ещё открыт; закрытые из индекса убираются.
+func (s *InvoiceService) openLocked(id string) *models.Invoice {
+	var inv models.Invoice
+	if ok, err := s.store.Get(invoicesCollection, id, &inv); err != nil || !ok {
+		return nil
+	}
+	if s.expireLocked(&inv); inv.Status != models.InvoicePending {
+		s.untrackLocked(&inv)
+		return nil
+	}
+	return &inv
+}
+
+// indexLocked — строит индекс открытых инвойсов из хранилища (один раз).
+func (s *InvoiceService) indexLocked() bool {
+	if s.byComment != nil {
+		return true
+	}
+	all, err := s.List()
+	if err != nil {
+		log.Printf("invoices: build open index: %v", err)
+		return false
+	}
+	s.byComment, s.byDeposit = make(map[string]string), make(map[string][]string)
+	for i := range all {
+		if all[i].Status == models.InvoicePending {
+			s.trackLocked(&all[i])
+		}
+	}
+	return true
+}
+
+func (s *InvoiceService) trackLocked(inv *models.Invoice) {
+	switch {
+	case inv.DepositAddress != "":
+		k := LedgerWallet(inv.DepositAddress)
+		s.byDeposit[k] = append(s.byDeposit[k], inv.ID)
+	case inv.Comment != "":
+		s.byComment[commentKey(inv.MerchantAddress, inv.Comment)] = inv.ID
+	}
+}
+
+func (s *InvoiceService) untrackLocked(inv *models.Invoice) {
+	if s.byComment == nil {
+		return
+	}
+	if inv.DepositAddress == "" {
+		if k := commentKey(inv.MerchantAddress, inv.Comment); s.byComment[k] == inv.ID {
+			delete(s.byComment, k)
+		}
+		return
+	}
+	k := LedgerWallet(inv.DepositAddress)
+	ids := s.byDeposit[k][:0]
+	for _, id := range s.byDeposit[k] {
+		if id != inv.ID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		delete(s.byDeposit, k)
+	} else {
+		s.byDeposit[k] = ids
+	}
+}
+
+func commentKey(wallet, comment string) string { return LedgerWallet(wallet) + "\x00" + comment }
+
+// expireLocked — ленивая смена pending -> expired по времени.
+func (s *InvoiceService) expireLocked(inv *models.Invoice) {
+	if inv.Status != models.InvoicePending || !s.now().After(inv.ExpiresAt) {
+		return
+	}
+	inv.Status = models.InvoiceExpired
+	if err := s.store.Put(invoicesCollection, inv.ID, inv); err != nil {
+		log.Printf("invoice %s: save expired: %v", inv.ID, err)
+		return
+	}
+	s.untrackLocked(inv)
+	s.publish(inv)
+}
+
+func (s *InvoiceService) publish(inv *models.Invoice) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.Publish(HubEvent{Type: HubEventInvoice, Wallet: inv.MerchantAddress, InvoiceID: inv.ID, Data: *inv})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"payment-service/config"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+func newTestInvoices(t *testing.T) *InvoiceService {
+	t.Helper()
+	rates, err := NewStaticRateProvider(map[string]string{"USD": "5", "RUB": "400"})
+	if err != nil { t.Fatalf("rates: %v", err) }
+	return NewInvoiceService(storage.NewMemory(), rates, nil, "EQ_MERCHANT", 1, time.Hour)
+}
+
+func TestInvoice_FiatAmountLocksTonWithSlippage(t *testing.T) {
+	svc := newTestInvoices(t)
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{FiatAmount: "10", FiatCurrency: "usd"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if inv.AmountTon != "2.000000000" || inv.MinAmountTon != "1.980000000" {
+		t.Fatalf("amounts: %s / %s", inv.AmountTon, inv.MinAmountTon)
+	}
+	if inv.Rate == nil || inv.Rate.TonPrice != "5" || inv.Rate.Currency != "USD" || inv.Rate.Source != "static" {
+		t.Fatalf("rate: %+v", inv.Rate)
+	}
+}
+
+func TestInvoice_RoundsUpToNanoton(t *testing.T) {
+	svc := newTestInvoices(t)
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{FiatAmount: "100", FiatCurrency: "RUB", SlippagePct: "0"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if inv.AmountTon != "0.250000000" { t.Fatalf("amount: %s", inv.AmountTon) }
+
+	inv, err = svc.Create(context.Background(), models.CreateInvoiceRequest{FiatAmount: "1", FiatCurrency: "RUB", SlippagePct: "0", Comment: "X"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if inv.AmountTon != "0.002500000" { t.Fatalf("amount: %s", inv.AmountTon) }
+}
+
+func TestInvoice_OnTransferMarksPaid(t *testing.T) {
+	svc := newTestInvoices(t)
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "3", Comment: "ORD-1"})
+	if err != nil { t.Fatalf("create: %v", err) }
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E0", Wallet: "EQ_MERCHANT", Amount: "2.999999999", Comment: "ORD-1"})
+	if got, _ := svc.Get(inv.ID); got.Status != models.InvoicePending { t.Fatalf("underpaid invoice became %s", got.Status) }
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "3.000000000", Comment: "ORD-1"})
+	got, _ := svc.Get(inv.ID)
+	if got.Status != models.InvoicePaid || got.TxHash != "E1" { t.Fatalf("unexpected: %+v", got) }
+
+	if _, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "ORD-1"}); err != nil {
+		t.Fatalf("comment of a paid invoice should be reusable: %v", err)
+	}
+	if _, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "ORD-1"}); err != ErrCommentInUse {
+		t.Fatalf("want ErrCommentInUse, got %v", err)
+	}
+}
+
+func TestInvoice_Expires(t *testing.T) {
+	svc := newTestInvoices(t)
+	inv, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1"})
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	got, _ := svc.Get(inv.ID)
+	if got.Status != models.InvoiceExpired { t.Fatalf("status %s", got.Status) }
+}
+
+func TestInvoice_PaymentIsPostedToLedger(t *testing.T) {
+	svc := newTestInvoices(t)
+	journal := ledger.New(storage.NewMemory())
+	svc.SetLedger(journal)
+	inv, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "3", Comment: "L-1"})
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Sender: "EQ_BUYER", Amount: "3", Comment: "L-1"})
+	e, ok, _ := journal.Get("payment:" + inv.ID)
+	if !ok || e.Memo != "E1" { t.Fatalf("entry: %+v", e) }
+	bal, _ := journal.Balance(ledger.Wallet(LedgerWallet("EQ_MERCHANT")), "TON")
+	if bal.String() != "3" { t.Fatalf("wallet balance %s", bal) }
+	if err := journal.Check(); err != nil { t.Fatalf("check: %v", err) }
+}
+
+func TestInvoice_DefaultWalletFollowsConfigReload(t *testing.T) {
+	const oldWallet, newWallet = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N", "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8"
+	t.Setenv("APP_WALLET", oldWallet)
+	cfg, err := config.Load("")
+	if err != nil { t.Fatalf("config: %v", err) }
+	live := config.NewLive("", cfg)
+	svc := newTestInvoices(t)
+	svc.SetConfig(live)
+
+	t.Setenv("APP_WALLET", newWallet)
+	if err := live.Reload(); err != nil { t.Fatalf("reload: %v", err) }
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1"})
+	if err != nil || inv.MerchantAddress != newWallet || svc.DefaultWallet() != newWallet { t.Fatalf("invoice on %v, err %v", inv, err) }
+}
+
+// Открытые инвойсы сопоставляются по индексу: он восстанавливается из хранилища после перезапуска,
+// а оплаченные и просроченные инвойсы из него уходят.
+func TestInvoice_OpenIndexTracksStatus(t *testing.T) {
+	store := storage.NewMemory()
+	rates, _ := NewStaticRateProvider(map[string]string{"USD": "5"})
+	first := NewInvoiceService(store, rates, nil, "EQ_MERCHANT", 1, time.Hour)
+	paid, _ := first.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "A-1"})
+	stale, _ := first.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "A-2", TTLSec: 60})
+
+	svc := NewInvoiceService(store, rates, nil, "EQ_MERCHANT", 1, time.Hour)
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "1", Comment: "A-1"})
+	if got, _ := svc.Get(paid.ID); got.Status != models.InvoicePaid { t.Fatalf("not matched after restart: %+v", got) }
+	if _, ok := svc.byComment[commentKey("EQ_MERCHANT", "A-1")]; ok { t.Fatal("paid invoice left in index") }
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E2", Wallet: "EQ_MERCHANT", Amount: "1", Comment: "A-2"})
+	if got, _ := svc.Get(stale.ID); got.Status != models.InvoiceExpired || len(svc.byComment) != 0 { t.Fatalf("expired: %+v, index %v", got, svc.byComment) }
+	if _, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "A-2"}); err != nil { t.Fatalf("comment of expired invoice: %v", err) }
+}
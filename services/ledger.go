@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/shopspring/decimal"
+	"payment-service/ledger"
+	"payment-service/models"
+)
+
+// LedgerWallet — адрес в каноническом виде (raw, workchain:hex) для счетов журнала,
+// чтобы один кошелёк в разных форматах не попадал на разные счета.
+func LedgerWallet(addr string) string {
+	a, err := parseTonAddress(addr)
+	if err != nil {
+		return normKey(addr)
+	}
+	return fmt.Sprintf("%d:%x", a.Workchain(), a.Data())
+}
+
+// postLedger — проводка с логированием ошибок; повтор того же события не ошибка.
+func postLedger(l *ledger.Ledger, e ledger.Entry) {
+	if l == nil {
+		return
+	}
+	if _, err := l.Post(e); err != nil && !errors.Is(err, ledger.ErrDuplicate) {
+		log.Printf("ledger %s:%s: %v", e.Kind, e.Reference, err)
+	}
+}
+
+// invoicePaymentEntry — оплата инвойса: деньги пришли на кошелёк мерчанта или депозитный адрес.
+func invoicePaymentEntry(inv *models.Invoice) (ledger.Entry, error) {
+	amount, err := decimal.NewFromString(inv.PaidAmountTon)
+	if err != nil {
+		return ledger.Entry{}, fmt.Errorf("bad paid amount %q", inv.PaidAmountTon)
+	}
+	wallet := inv.MerchantAddress
+	if inv.DepositAddress != "" {
+		wallet = inv.DepositAddress
+	}
+	customer := inv.CustomerID
+	if customer == "" {
+		customer = LedgerWallet(inv.Sender)
+	}
+	if customer == "" {
+		customer = "anonymous"
+	}
+	e := ledger.Payment(inv.ID, LedgerWallet(wallet), customer, amount, ledger.CurrencyTON, *inv.PaidAt)
+	e.Memo = inv.TxHash
+	return e, nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	merchantsCollection = "merchants"
+	merchantKeyPrefix   = "mk_"
+)
+
+var (
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrWalletTaken      = errors.New("wallet already belongs to another merchant")
+	ErrAPIKeyNotFound   = errors.New("api key not found")
+)
+
+// MerchantService — реестр мерчантов: кошельки, webhook, ключи API.
+type MerchantService struct {
+	store   *storage.Store
+	watcher *Watcher
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+func NewMerchantService(store *storage.Store) *MerchantService {
+	return &MerchantService{store: store, now: time.Now}
+}
+
+// SetWatcher — кошельки мерчантов (текущих и новых) добавляются в опрос.
+func (s *MerchantService) SetWatcher(w *Watcher) {
+	s.watcher = w
+	all, _ := s.List()
+	for _, m := range all {
+		s.watch(m.Wallets)
+	}
+}
+
+func (s *MerchantService) Create(req models.MerchantRequest) (*models.Merchant, *models.IssuedAPIKey, error) {
+	wallets, err := cleanWallets(req.Wallets)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := s.now().UTC()
+	m := &models.Merchant{
+		ID:              "mer_" + randomHex(8),
+		Name:            strings.TrimSpace(req.Name),
+		Wallets:         wallets,
+		WebhookURL:      strings.TrimSpace(req.WebhookURL),
+		DefaultCurrency: strings.ToUpper(strings.TrimSpace(req.DefaultCurrency)),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	key := s.newKey(m)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkWalletsFree(m.ID, wallets); err != nil {
+		return nil, nil, err
+	}
+	if err := s.store.Put(merchantsCollection, m.ID, m); err != nil {
+		return nil, nil, err
+	}
+	s.watch(wallets)
+	return m, key, nil
+}
+
+func (s *MerchantService) Update(id string, req models.MerchantRequest) (*models.Merchant, error) {
+	wallets, err := cleanWallets(req.Wallets)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWalletsFree(m.ID, wallets); err != nil {
+		return nil, err
+	}
+	m.Name = strings.TrimSpace(req.Name)
+	m.Wallets = wallets
+	m.WebhookURL = strings.TrimSpace(req.WebhookURL)
+	m.DefaultCurrency = strings.ToUpper(strings.TrimSpace(req.DefaultCurrency))
+	m.UpdatedAt = s.now().UTC()
+	if err := s.store.Put(merchantsCollection, m.ID, m); err != nil {
+		return nil, err
+	}
+	s.watch(wallets)
+	return m, nil
+}
+
+func (s *MerchantService) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return s.store.Delete(merchantsCollection, id)
+}
+
+func (s *MerchantService) Get(id string) (*models.Merchant, error) {
+	var m models.Merchant
+	ok, err := s.store.Get(merchantsCollection, id, &m)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrMerchantNotFound
+	}
+	return &m, nil
+}
+
+func (s *MerchantService) List() ([]models.Merchant, error) {
+	return storage.List[models.Merchant](s.store, merchantsCollection)
+}
+
+// IssueKey — новый ключ API мерчанта; старые продолжают работать до отзыва.
+func (s *MerchantService) IssueKey(id string) (*models.IssuedAPIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	key := s.newKey(m)
+	m.UpdatedAt = s.now().UTC()
+	if err := s.store.Put(merchantsCollection, m.ID, m); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *MerchantService) RevokeKey(id, keyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	for i, k := range m.APIKeys {
+		if k.ID == keyID {
+			m.APIKeys = append(m.APIKeys[:i], m.APIKeys[i+1:]...)
+			m.UpdatedAt = s.now().UTC()
+			return s.store.Put(merchantsCollection, m.ID, m)
+		}
+	}
+	return ErrAPIKeyNotFound
+}
+
+// HasAPIKeys — выпущен ли хотя бы один ключ мерчанта.
+func (s *MerchantService) HasAPIKeys() bool {
+	all, err := s.List()
+	if err != nil {
+		return true // при ошибке хранилища считаем, что ключи есть: анонимный доступ закрыт
+	}
+	for _, m := range all {
+		if len(m.APIKeys) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate — мерчант по ключу API (nil, если ключ не принадлежит ни одному мерчанту).
+func (s *MerchantService) Authenticate(key string) *models.Merchant {
+	if !strings.HasPrefix(key, merchantKeyPrefix) {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	all, err := s.List()
+	if err != nil {
+		return nil
+	}
+	for i := range all {
+		for _, k := range all[i].APIKeys {
+			if subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hash)) == 1 {
+				return &all[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ByWallet — мерчант, которому принадлежит кошелёк.
+func (s *MerchantService) ByWallet(wallet string) *models.Merchant {
+	all, err := s.List()
+	if err != nil {
+		return nil
+	}
+	for i := range all {
+		if OwnsWallet(&all[i], wallet) {
+			return &all[i]
+		}
+	}
+	return nil
+}
+
+// OwnsWallet — принадлежит ли кошелёк мерчанту (с учётом форматов адреса).
+func OwnsWallet(m *models.Merchant, wallet string) bool {
+	for _, w := range m.Wallets {
+		if SameAddress(w, wallet) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicMerchant — копия без хешей ключей, для ответов API.
+func PublicMerchant(m *models.Merchant) models.Merchant {
+	out := *m
+	out.APIKeys = make([]models.MerchantAPIKey, len(m.APIKeys))
+	for i, k := range m.APIKeys {
+		k.Hash = ""
+		out.APIKeys[i] = k
+	}
+	return out
+}
+
+func (s *MerchantService) newKey(m *models.Merchant) *models.IssuedAPIKey {
+	plain := merchantKeyPrefix + randomHex(24)
+	sum := sha256.Sum256([]byte(plain))
+	k := models.MerchantAPIKey{
+		ID:        "key_" + randomHex(4),
+		Prefix:    plain[:len(merchantKeyPrefix)+6],
+		Hash:      hex.EncodeToString(sum[:]),
+		CreatedAt: s.now().UTC(),
+	}
+	m.APIKeys = append(m.APIKeys, k)
+	return &models.IssuedAPIKey{MerchantID: m.ID, KeyID: k.ID, Key: plain}
+}
+
+func (s *MerchantService) checkWalletsFree(id string, wallets []string) error {
+	all, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := range all {
+		if all[i].ID == id {
+			continue
+		}
+		for _, w := range wallets {
+			if OwnsWallet(&all[i], w) {
+				return fmt.Errorf("%w: %s", ErrWalletTaken, w)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MerchantService) watch(wallets []string) {
+	if s.watcher == nil {
+		return
+	}
+	for _, w := range wallets {
+		s.watcher.AddWallet(w)
+	}
+}
+
+func cleanWallets(in []string) ([]string, error) {
+	var out []string
+	for _, w := range in {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		if _, err := parseTonAddress(w); err != nil {
+			return nil, fmt.Errorf("bad wallet %q: %w", w, err)
+		}
+		out = append(out, w)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("at least one wallet is required")
+	}
+	return out, nil
+}
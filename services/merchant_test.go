@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+func testWallet(b byte) string {
+	data := make([]byte, 32)
+	data[0] = b
+	return address.NewAddress(0, 0, data).String()
+}
+
+func TestMerchant_KeysAndOwnership(t *testing.T) {
+	svc := NewMerchantService(storage.NewMemory())
+	w1, w2 := testWallet(1), testWallet(2)
+
+	m, key, err := svc.Create(models.MerchantRequest{Name: "Shop", Wallets: []string{w1}, DefaultCurrency: "usd"})
+	if err != nil { t.Fatalf("create: %v", err) }
+	if m.DefaultCurrency != "USD" { t.Fatalf("currency %s", m.DefaultCurrency) }
+
+	got := svc.Authenticate(key.Key)
+	if got == nil || got.ID != m.ID { t.Fatalf("key does not authenticate merchant") }
+	if !OwnsWallet(got, w1) || OwnsWallet(got, w2) { t.Fatalf("unexpected wallet ownership") }
+	if pub := PublicMerchant(got); pub.APIKeys[0].Hash != "" { t.Fatalf("hash leaked") }
+
+	if _, _, err := svc.Create(models.MerchantRequest{Name: "Other", Wallets: []string{w1}}); !errors.Is(err, ErrWalletTaken) {
+		t.Fatalf("want ErrWalletTaken, got %v", err)
+	}
+
+	if err := svc.RevokeKey(m.ID, key.KeyID); err != nil { t.Fatalf("revoke: %v", err) }
+	if svc.Authenticate(key.Key) != nil { t.Fatalf("revoked key still works") }
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	orphansCollection = "orphans"
+	maxSuggestions    = 5
+)
+
+var (
+	ErrOrphanNotFound = errors.New("orphan payment not found")
+	ErrOrphanResolved = errors.New("orphan payment is already resolved")
+)
+
+// OrphanService — «потерянные» платежи: входящие переводы, которые не закрыли ни один
+// инвойс. Оператор привязывает их к инвойсу вручную или помечает к возврату.
+type OrphanService struct {
+	store    *storage.Store
+	invoices *InvoiceService
+	now      func() time.Time
+
+	mu sync.Mutex
+}
+
+func NewOrphanService(store *storage.Store, invoices *InvoiceService) *OrphanService {
+	return &OrphanService{store: store, invoices: invoices, now: time.Now}
+}
+
+// Record — сохраняет перевод (повторная запись того же перевода игнорируется).
+func (s *OrphanService) Record(t IncomingTransfer, merchantAddress string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := orphanID(t)
+	var existing models.OrphanPayment
+	if ok, _ := s.store.Get(orphansCollection, id, &existing); ok {
+		return
+	}
+	o := models.OrphanPayment{
+		ID:              id,
+		EventID:         t.EventID,
+		Wallet:          t.Wallet,
+		MerchantAddress: merchantAddress,
+		Sender:          t.Sender,
+		AmountTon:       t.Amount,
+		Comment:         t.Comment,
+		Timestamp:       t.Timestamp,
+		Status:          models.OrphanUnmatched,
+	}
+	if err := s.store.Put(orphansCollection, o.ID, o); err != nil {
+		log.Printf("orphan %s: save: %v", o.ID, err)
+	}
+}
+
+// orphanID — ключ перевода: id события, для переводов не из первого действия — с номером действия.
+func orphanID(t IncomingTransfer) string {
+	if t.Action == 0 {
+		return t.EventID
+	}
+	return fmt.Sprintf("%s:%d", t.EventID, t.Action)
+}
+
+// orphanEvent — id события платежа (у записей до появления EventID он совпадает с ID).
+func orphanEvent(o *models.OrphanPayment) string {
+	if o.EventID != "" {
+		return o.EventID
+	}
+	return o.ID
+}
+
+func (s *OrphanService) Get(id string) (*models.OrphanPayment, error) {
+	var o models.OrphanPayment
+	ok, err := s.store.Get(orphansCollection, id, &o)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOrphanNotFound
+	}
+	return &o, nil
+}
+
+// List — платежи с нужным статусом (пустой — все), новые первыми.
+func (s *OrphanService) List(status string) ([]models.OrphanPayment, error) {
+	all, err := storage.List[models.OrphanPayment](s.store, orphansCollection)
+	if err != nil {
+		return nil, err
+	}
+	out := all[:0]
+	for _, o := range all {
+		if status == "" || o.Status == status {
+			out = append(out, o)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.After(out[j].Timestamp) })
+	return out, nil
+}
+
+// Attach — закрывает инвойс этим платежом.
+func (s *OrphanService) Attach(id, invoiceID string) (*models.OrphanPayment, *models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.unresolved(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	inv, err := s.invoices.AttachPayment(invoiceID, IncomingTransfer{
+		EventID:   orphanEvent(o),
+		Wallet:    o.Wallet,
+		Sender:    o.Sender,
+		Amount:    o.AmountTon,
+		Comment:   o.Comment,
+		Timestamp: o.Timestamp,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	o.InvoiceID = inv.ID
+	if err := s.resolve(o, models.OrphanAttached); err != nil {
+		return nil, nil, err
+	}
+	return o, inv, nil
+}
+
+// MarkRefund — платёж нужно вернуть отправителю; сам возврат выполняется вне сервиса.
+func (s *OrphanService) MarkRefund(id, note string) (*models.OrphanPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, err := s.unresolved(id)
+	if err != nil {
+		return nil, err
+	}
+	o.Note = strings.TrimSpace(note)
+	if err := s.resolve(o, models.OrphanRefund); err != nil {
+		return nil, err
+	}
+	if s.invoices.hub != nil {
+		s.invoices.hub.PublishPayout(o.MerchantAddress, *o)
+	}
+	return o, nil
+}
+
+// Suggest — инвойсы того же мерчанта, к которым платёж, вероятно, относится:
+// тот же депозитный адрес, подходящая сумма или комментарий с опечаткой.
+func (s *OrphanService) Suggest(o *models.OrphanPayment) ([]models.OrphanSuggestion, error) {
+	all, err := s.invoices.List()
+	if err != nil {
+		return nil, err
+	}
+	paid, _ := decimal.NewFromString(o.AmountTon)
+	comment := strings.ToUpper(strings.TrimSpace(o.Comment))
+
+	var out []models.OrphanSuggestion
+	for _, inv := range all {
+		if inv.Status == models.InvoicePaid || !SameAddress(inv.MerchantAddress, o.MerchantAddress) {
+			continue
+		}
+		sg := models.OrphanSuggestion{InvoiceID: inv.ID, Comment: inv.Comment, AmountTon: inv.AmountTon, Status: inv.Status, CommentDistance: -1}
+		if inv.DepositAddress != "" && SameAddress(inv.DepositAddress, o.Wallet) {
+			sg.Reasons = append(sg.Reasons, "deposit_address")
+		}
+		if minTon, err := decimal.NewFromString(inv.MinAmountTon); err == nil && !paid.LessThan(minTon) &&
+			!paid.GreaterThan(decimal.RequireFromString(inv.AmountTon)) {
+			sg.Reasons = append(sg.Reasons, "amount")
+		}
+		if inv.Comment != "" && comment != "" {
+			want := strings.ToUpper(inv.Comment)
+			sg.CommentDistance = editDistance(comment, want)
+			if sg.CommentDistance <= commentTolerance(want) {
+				sg.Reasons = append(sg.Reasons, "comment")
+			}
+		}
+		if len(sg.Reasons) > 0 {
+			out = append(out, sg)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if len(out[i].Reasons) != len(out[j].Reasons) {
+			return len(out[i].Reasons) > len(out[j].Reasons)
+		}
+		return distanceRank(out[i].CommentDistance) < distanceRank(out[j].CommentDistance)
+	})
+	if len(out) > maxSuggestions {
+		out = out[:maxSuggestions]
+	}
+	return out, nil
+}
+
+func (s *OrphanService) unresolved(id string) (*models.OrphanPayment, error) {
+	o, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != models.OrphanUnmatched {
+		return nil, fmt.Errorf("%w: %s", ErrOrphanResolved, o.Status)
+	}
+	return o, nil
+}
+
+func (s *OrphanService) resolve(o *models.OrphanPayment, status string) error {
+	at := s.now().UTC()
+	o.Status = status
+	o.ResolvedAt = &at
+	return s.store.Put(orphansCollection, o.ID, o)
+}
+
+// commentTolerance — допустимое число правок: одна на каждые 4 символа, но не меньше одной.
+func commentTolerance(s string) int {
+	if n := len([]rune(s)) / 4; n > 1 {
+		return n
+	}
+	return 1
+}
+
+func distanceRank(d int) int {
+	if d < 0 {
+		return math.MaxInt
+	}
+	return d
+}
+
+// editDistance — расстояние Левенштейна по рунам.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
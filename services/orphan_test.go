@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+func TestOrphan_UnmatchedTransferIsRecordedWithSuggestions(t *testing.T) {
+	svc := newTestInvoices(t)
+	orphans := NewOrphanService(storage.NewMemory(), svc)
+	svc.SetOrphans(orphans)
+
+	typo, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "5", Comment: "ORDER-1234"})
+	sameAmount, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "2", Comment: "ORDER-9"})
+	svc.Create(context.Background(), models.CreateInvoiceRequest{MerchantAddress: "EQ_OTHER", AmountTon: "2", Comment: "ORDER-1243"})
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "2", Comment: "order-1243"})
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "2", Comment: "order-1243"})
+
+	list, _ := orphans.List(models.OrphanUnmatched)
+	if len(list) != 1 || list[0].ID != "E1" || list[0].MerchantAddress != "EQ_MERCHANT" { t.Fatalf("orphans: %+v", list) }
+
+	sg, err := orphans.Suggest(&list[0])
+	if err != nil { t.Fatalf("suggest: %v", err) }
+	if len(sg) != 2 { t.Fatalf("suggestions: %+v", sg) }
+	if sg[0].InvoiceID != typo.ID || sg[0].CommentDistance != 2 || sg[0].Reasons[0] != "comment" { t.Fatalf("first: %+v", sg[0]) }
+	if sg[1].InvoiceID != sameAmount.ID || sg[1].Reasons[0] != "amount" { t.Fatalf("second: %+v", sg[1]) }
+}
+
+func TestOrphan_AttachAndRefund(t *testing.T) {
+	svc := newTestInvoices(t)
+	orphans := NewOrphanService(storage.NewMemory(), svc)
+	svc.SetOrphans(orphans)
+	inv, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "A-1"})
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Amount: "1"})
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E2", Wallet: "EQ_MERCHANT", Amount: "0.5", Comment: "A-1"})
+
+	o, got, err := orphans.Attach("E1", inv.ID)
+	if err != nil { t.Fatalf("attach: %v", err) }
+	if o.Status != models.OrphanAttached || got.Status != models.InvoicePaid || got.TxHash != "E1" { t.Fatalf("unexpected: %+v / %+v", o, got) }
+
+	if _, err := orphans.MarkRefund("E1", ""); err == nil { t.Fatal("resolved payment must not be refundable") }
+	if _, _, err := orphans.Attach("E2", inv.ID); err == nil { t.Fatal("paid invoice must not accept a second payment") }
+
+	r, err := orphans.MarkRefund("E2", "underpaid")
+	if err != nil || r.Status != models.OrphanRefund || r.Note != "underpaid" || r.ResolvedAt == nil { t.Fatalf("refund: %+v %v", r, err) }
+}
+
+// Сметание с депозита на кошелёк мерчанта — не потерянный платёж.
+func TestOrphan_SweepsAreNotRecorded(t *testing.T) {
+	svc := newTestInvoices(t)
+	orphans := NewOrphanService(storage.NewMemory(), svc)
+	svc.SetOrphans(orphans)
+	mock := &mockTonAPI{accountFn: func(context.Context, string) (int64, string, error) { return 2_000_000_000, "active", nil }}
+	deposits := newTestDeposits(t, mock, &fakeSweeper{})
+	svc.SetDeposits(deposits)
+
+	inv, err := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "2", Mode: models.InvoiceModeDeposit})
+	if err != nil { t.Fatalf("create: %v", err) }
+	deposits.SweepOnce(context.Background())
+
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "S1", Wallet: "EQ_MERCHANT", Sender: inv.DepositAddress, Amount: "1.99", Comment: "sweep"})
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "ABCD", Wallet: "EQ_MERCHANT", Amount: "1.99"})
+	svc.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: "EQ_MERCHANT", Sender: "0:a", Amount: "1"})
+
+	list, _ := orphans.List("")
+	if len(list) != 1 || list[0].ID != "E1" { t.Fatalf("orphans: %+v", list) }
+}
+
+// Два перевода в одном событии — два «потерянных» платежа; привязка сохраняет id события.
+func TestOrphan_EventWithSeveralTransfers(t *testing.T) {
+	svc := newTestInvoices(t)
+	orphans := NewOrphanService(storage.NewMemory(), svc)
+	svc.SetOrphans(orphans)
+	inv, _ := svc.Create(context.Background(), models.CreateInvoiceRequest{AmountTon: "1", Comment: "A-1"})
+
+	ev := Event{EventID: "E1", Actions: []EventAction{
+		{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_A", Recipient: "EQ_MERCHANT"},
+		{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_A", Recipient: "EQ_MERCHANT"},
+	}}
+	for _, tr := range incomingTransfers(ev, "EQ_MERCHANT") {
+		svc.OnTransfer(context.Background(), tr)
+		svc.OnTransfer(context.Background(), tr)
+	}
+	list, _ := orphans.List("")
+	if len(list) != 2 { t.Fatalf("orphans: %+v", list) }
+
+	if _, _, err := orphans.Attach("E1:1", inv.ID); err != nil { t.Fatalf("attach: %v", err) }
+	if got, _ := svc.Get(inv.ID); got.TxHash != "E1" { t.Fatalf("invoice tx: %+v", got) }
+}
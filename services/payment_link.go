@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"github.com/skip2/go-qrcode"
+	"payment-service/models"
+)
+
+const (
+	tonkeeperTransferBase = "https://app.tonkeeper.com/transfer/"
+	tonhubTransferBase    = "https://tonhub.com/transfer/"
+)
+
+// BuildPaymentLink — ton:// URI и универсальные ссылки кошельков для перевода.
+// Адрес должен разбираться как адрес TON (user-friendly или raw), иначе ссылка и QR никуда не ведут.
+// Сумма в нанотонах считается через tonToNanos — той же арифметикой, что и nanosStrToTon.
+func BuildPaymentLink(req models.PaymentLinkRequest) (*models.PaymentLink, error) {
+	addr := strings.TrimSpace(req.MerchantAddress)
+	if addr == "" {
+		return nil, fmt.Errorf("empty merchant address")
+	}
+	if _, err := parseTonAddress(addr); err != nil {
+		return nil, fmt.Errorf("bad merchant address: %w", err)
+	}
+	ton, err := decimal.NewFromString(strings.TrimSpace(req.AmountTon))
+	if err != nil {
+		return nil, fmt.Errorf("bad AmountTon: %w", err)
+	}
+	if !ton.IsPositive() {
+		return nil, fmt.Errorf("bad AmountTon: must be positive")
+	}
+	nanos := tonToNanos(ton)
+	if !nanos.IsPositive() {
+		return nil, fmt.Errorf("bad AmountTon: less than 1 nanoton")
+	}
+
+	q := url.Values{}
+	q.Set("amount", nanos.String())
+	if req.Comment != "" {
+		q.Set("text", req.Comment)
+	}
+	// url.Values кодирует пробел как "+", кошельки ожидают %20
+	query := strings.ReplaceAll(q.Encode(), "+", "%20")
+	path := url.PathEscape(addr) + "?" + query
+
+	return &models.PaymentLink{
+		Address:      addr,
+		AmountTon:    ton.Truncate(9).StringFixed(9),
+		AmountNanos:  nanos.String(),
+		Comment:      req.Comment,
+		TonURI:       "ton://transfer/" + path,
+		TonkeeperURL: tonkeeperTransferBase + path,
+		TonhubURL:    tonhubTransferBase + path,
+	}, nil
+}
+
+// RenderQR — QR-код содержимого в PNG или SVG. Возвращает данные и Content-Type.
+func RenderQR(content, format string, size int) ([]byte, string, error) {
+	if size <= 0 || size > 2048 {
+		size = 256
+	}
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, "", err
+	}
+	switch strings.ToLower(format) {
+	case "", "png":
+		png, err := qr.PNG(size)
+		if err != nil {
+			return nil, "", err
+		}
+		return png, "image/png", nil
+	case "svg":
+		return qrSVG(qr.Bitmap(), size), "image/svg+xml", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported QR format %q", format)
+	}
+}
+
+// QRDataURI — PNG-QR в виде data: URI для встраивания в JSON-ответ.
+func QRDataURI(content string, size int) (string, error) {
+	png, _, err := RenderQR(content, "png", size)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// qrSVG — по модулю на <rect>, соседние модули в строке склеиваются.
+func qrSVG(bitmap [][]bool, size int) []byte {
+	n := len(bitmap)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, n, n)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="1" fill="#000"/>`, start, y, x-start)
+		}
+	}
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
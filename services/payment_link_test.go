@@ -0,0 +1,51 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"payment-service/models"
+)
+
+const linkWallet = "EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N"
+
+func TestBuildPaymentLink(t *testing.T) {
+	link, err := BuildPaymentLink(models.PaymentLinkRequest{
+		MerchantAddress: linkWallet, AmountTon: "3.5", Comment: "ORD 1",
+	})
+	if err != nil { t.Fatalf("err: %v", err) }
+	if link.AmountNanos != "3500000000" { t.Fatalf("nanos: %s", link.AmountNanos) }
+	if want := "ton://transfer/" + linkWallet + "?amount=3500000000&text=ORD%201"; link.TonURI != want {
+		t.Fatalf("want %s got %s", want, link.TonURI)
+	}
+	if want := "https://app.tonkeeper.com/transfer/" + linkWallet + "?amount=3500000000&text=ORD%201"; link.TonkeeperURL != want {
+		t.Fatalf("want %s got %s", want, link.TonkeeperURL)
+	}
+}
+
+func TestBuildPaymentLink_RejectsDust(t *testing.T) {
+	if _, err := BuildPaymentLink(models.PaymentLinkRequest{MerchantAddress: linkWallet, AmountTon: "0.0000000001"}); err == nil {
+		t.Fatalf("expected error for sub-nanoton amount")
+	}
+}
+
+func TestBuildPaymentLink_RejectsBadAddress(t *testing.T) {
+	for _, addr := range []string{"EQ_MERCHANT", "not an address", "0:zz"} {
+		if _, err := BuildPaymentLink(models.PaymentLinkRequest{MerchantAddress: addr, AmountTon: "1"}); err == nil { t.Fatalf("%q: expected error", addr) }
+	}
+	if _, err := BuildPaymentLink(models.PaymentLinkRequest{MerchantAddress: "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b68ec7527e1ba403a0f31a8", AmountTon: "1"}); err != nil { t.Fatalf("raw address: %v", err) }
+}
+
+func TestTonToNanos_RoundTrip(t *testing.T) {
+	nanos := tonToNanos(decimal.RequireFromString("1.123456789"))
+	ton, err := nanosStrToTon(nanos.String())
+	if err != nil { t.Fatalf("err: %v", err) }
+	if ton.StringFixed(9) != "1.123456789" { t.Fatalf("got %s", ton.StringFixed(9)) }
+}
+
+func TestRenderQR_SVG(t *testing.T) {
+	img, ct, err := RenderQR("ton://transfer/EQ_MERCHANT?amount=1", "svg", 128)
+	if err != nil { t.Fatalf("err: %v", err) }
+	if ct != "image/svg+xml" || !bytes.HasPrefix(img, []byte("<svg")) { t.Fatalf("bad svg: %s", ct) }
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateProvider — источник цены 1 TON в фиатной валюте.
+type RateProvider interface {
+	TonPrice(ctx context.Context, currency string) (decimal.Decimal, error)
+	Name() string
+}
+
+// HistoricalRateProvider — цены TON за период в прошлом (для выгрузок с фиатной оценкой).
+type HistoricalRateProvider interface {
+	TonPriceSeries(ctx context.Context, currency string, from, to time.Time) (RateSeries, error)
+}
+
+type RatePoint struct {
+	At    time.Time
+	Price decimal.Decimal
+}
+
+// RateSeries — точки по возрастанию времени.
+type RateSeries []RatePoint
+
+// At — цена в ближайшей по времени точке.
+func (s RateSeries) At(t time.Time) (decimal.Decimal, bool) {
+	if len(s) == 0 {
+		return decimal.Zero, false
+	}
+	i := sort.Search(len(s), func(i int) bool { return !s[i].At.Before(t) })
+	switch {
+	case i == 0:
+		return s[0].Price, true
+	case i == len(s):
+		return s[len(s)-1].Price, true
+	case t.Sub(s[i-1].At) <= s[i].At.Sub(t):
+		return s[i-1].Price, true
+	}
+	return s[i].Price, true
+}
+
+// ---------------- TonAPI /v2/rates ----------------
+
+type TonAPIRateProvider struct {
+	api *RestTonAPIAdapter
+	ttl time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+}
+
+type cachedRate struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+func NewTonAPIRateProvider(api *RestTonAPIAdapter, ttl time.Duration) *TonAPIRateProvider {
+	return &TonAPIRateProvider{api: api, ttl: ttl, cache: make(map[string]cachedRate)}
+}
+
+func (p *TonAPIRateProvider) Name() string { return "tonapi" }
+
+func (p *TonAPIRateProvider) TonPrice(ctx context.Context, currency string) (decimal.Decimal, error) {
+	cur := strings.ToUpper(strings.TrimSpace(currency))
+	p.mu.Lock()
+	if c, ok := p.cache[cur]; ok && time.Since(c.at) < p.ttl {
+		p.mu.Unlock()
+		return c.price, nil
+	}
+	p.mu.Unlock()
+
+	// {"rates":{"TON":{"prices":{"USD":5.12}}}} — цены приходят числами
+	var rr struct {
+		Rates map[string]struct {
+			Prices map[string]json.Number `json:"prices"`
+		} `json:"rates"`
+	}
+	path := "/v2/rates?tokens=ton&currencies=" + url.QueryEscape(strings.ToLower(cur))
+	if err := p.api.getJSON(ctx, "/v2/rates", path, "rates", &rr); err != nil {
+		return decimal.Zero, err
+	}
+	for token, r := range rr.Rates {
+		if !strings.EqualFold(token, "ton") {
+			continue
+		}
+		for c, v := range r.Prices {
+			if !strings.EqualFold(c, cur) {
+				continue
+			}
+			price, err := decimal.NewFromString(v.String())
+			if err != nil || !price.IsPositive() {
+				return decimal.Zero, fmt.Errorf("bad %s rate %q", cur, v)
+			}
+			p.mu.Lock()
+			p.cache[cur] = cachedRate{price: price, at: time.Now()}
+			p.mu.Unlock()
+			return price, nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("no TON/%s rate", cur)
+}
+
+// TonPriceSeries — /v2/rates/chart: до 200 точек на период.
+func (p *TonAPIRateProvider) TonPriceSeries(ctx context.Context, currency string, from, to time.Time) (RateSeries, error) {
+	cur := strings.ToLower(strings.TrimSpace(currency))
+	// {"points":[[1700000000, 2.1], ...]}
+	var cr struct {
+		Points [][]json.Number `json:"points"`
+	}
+	path := fmt.Sprintf("/v2/rates/chart?token=ton&currency=%s&start_date=%d&end_date=%d&points_count=200",
+		url.QueryEscape(cur), from.Unix(), to.Unix())
+	if err := p.api.getJSON(ctx, "/v2/rates/chart", path, "rates chart", &cr); err != nil {
+		return nil, err
+	}
+	out := make(RateSeries, 0, len(cr.Points))
+	for _, pt := range cr.Points {
+		if len(pt) < 2 {
+			continue
+		}
+		ts, err1 := pt[0].Int64()
+		price, err2 := decimal.NewFromString(pt[1].String())
+		if err1 != nil || err2 != nil || !price.IsPositive() {
+			continue
+		}
+		out = append(out, RatePoint{At: time.Unix(ts, 0).UTC(), Price: price})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// ---------------- статические курсы (тесты, оффлайн) ----------------
+
+type StaticRateProvider struct {
+	name   string
+	prices map[string]decimal.Decimal
+}
+
+func NewStaticRateProvider(prices map[string]string) (*StaticRateProvider, error) {
+	p := &StaticRateProvider{name: "static", prices: make(map[string]decimal.Decimal, len(prices))}
+	for cur, v := range prices {
+		d, err := decimal.NewFromString(v)
+		if err != nil || !d.IsPositive() {
+			return nil, fmt.Errorf("bad %s rate %q", cur, v)
+		}
+		p.prices[strings.ToUpper(cur)] = d
+	}
+	return p, nil
+}
+
+// NewFileRateProvider — курсы из JSON-файла вида {"USD": "5.12", "EUR": "4.70"}.
+func NewFileRateProvider(path string) (*StaticRateProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rates file: %w", err)
+	}
+	var prices map[string]string
+	if err := json.Unmarshal(raw, &prices); err != nil {
+		return nil, fmt.Errorf("parse rates file: %w", err)
+	}
+	p, err := NewStaticRateProvider(prices)
+	if err != nil {
+		return nil, err
+	}
+	p.name = "file"
+	return p, nil
+}
+
+func (p *StaticRateProvider) Name() string { return p.name }
+
+func (p *StaticRateProvider) TonPrice(_ context.Context, currency string) (decimal.Decimal, error) {
+	if d, ok := p.prices[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return d, nil
+	}
+	return decimal.Zero, fmt.Errorf("no TON/%s rate", currency)
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const (
+	reconcileCollection = "reconciliation"
+	reconcileLastKey    = "last"
+	reconcilePageSize   = 100
+	reconcileMaxPages   = 1000
+)
+
+// Reconciler — сверка с блокчейном: проходит всю историю событий каждого кошелька
+// и сравнивает её с инвойсами, «потерянными» платежами и журналом.
+// Суммы сверяются только для входящих переводов: у исходящих в журнале
+// сумма с комиссией, а в событии — без неё.
+type Reconciler struct {
+	client   TonAPI
+	store    *storage.Store
+	invoices *InvoiceService
+	orphans  *OrphanService
+	deposits *DepositService
+	ledger   *ledger.Ledger
+	pageSize int
+	maxPages int
+	now      func() time.Time
+
+	mu sync.Mutex // один прогон за раз
+}
+
+func NewReconciler(svc *TONService, store *storage.Store, invoices *InvoiceService, orphans *OrphanService, l *ledger.Ledger) *Reconciler {
+	return &Reconciler{
+		client:   svc.client,
+		store:    store,
+		invoices: invoices,
+		orphans:  orphans,
+		ledger:   l,
+		pageSize: reconcilePageSize,
+		maxPages: reconcileMaxPages,
+		now:      time.Now,
+	}
+}
+
+// SetDeposits — депозитные адреса сверяются вместе с кошельками мерчантов.
+func (r *Reconciler) SetDeposits(d *DepositService) { r.deposits = d }
+
+// Run — сверка по кошелькам; отчёт сохраняется и доступен через Last.
+func (r *Reconciler) Run(ctx context.Context, wallets []string) (*models.ReconciliationReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := &models.ReconciliationReport{StartedAt: r.now().UTC(), Discrepancies: []models.Discrepancy{}}
+	recs, err := r.loadRecords()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range r.walletSet(wallets) {
+		wr, ds := r.reconcileWallet(ctx, w, recs)
+		rep.Wallets = append(rep.Wallets, wr)
+		rep.Discrepancies = append(rep.Discrepancies, ds...)
+	}
+	rep.FinishedAt = r.now().UTC()
+	if err := r.store.Put(reconcileCollection, reconcileLastKey, rep); err != nil {
+		log.Printf("reconcile: save report: %v", err)
+	}
+	return rep, nil
+}
+
+// Last — отчёт последнего прогона (nil, если сверки ещё не было).
+func (r *Reconciler) Last() (*models.ReconciliationReport, error) {
+	var rep models.ReconciliationReport
+	ok, err := r.store.Get(reconcileCollection, reconcileLastKey, &rep)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// RunEvery — периодическая сверка до отмены контекста.
+func (r *Reconciler) RunEvery(ctx context.Context, interval time.Duration, wallets func() []string) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			rep, err := r.Run(ctx, wallets())
+			if err != nil {
+				log.Printf("reconcile: %v", err)
+				continue
+			}
+			if n := len(rep.Discrepancies); n > 0 {
+				log.Printf("reconcile: %d discrepancies", n)
+			}
+		}
+	}
+}
+
+// records — наши записи, из которых строятся ожидания для каждого кошелька.
+type records struct {
+	invoices []models.Invoice // оплаченные
+	orphans  map[string]bool  // id событий
+	entries  []ledger.Entry
+}
+
+func (r *Reconciler) loadRecords() (*records, error) {
+	all, err := r.invoices.List()
+	if err != nil {
+		return nil, err
+	}
+	recs := &records{orphans: make(map[string]bool)}
+	for _, inv := range all {
+		if inv.Status == models.InvoicePaid && inv.TxHash != "" {
+			recs.invoices = append(recs.invoices, inv)
+		}
+	}
+	if r.orphans != nil {
+		list, err := r.orphans.List("")
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range list {
+			recs.orphans[normKey(orphanEvent(&o))] = true
+		}
+	}
+	if r.ledger != nil {
+		if recs.entries, err = r.ledger.Entries(""); err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+func (r *Reconciler) walletSet(wallets []string) []string {
+	var out []string
+	add := func(w string) {
+		for _, x := range out {
+			if SameAddress(x, w) {
+				return
+			}
+		}
+		out = append(out, w)
+	}
+	for _, w := range wallets {
+		if w != "" {
+			add(w)
+		}
+	}
+	if r.deposits != nil {
+		all, _ := r.deposits.List()
+		for _, d := range all {
+			add(d.Address)
+		}
+	}
+	return out
+}
+
+// chainHistory — переводы кошелька по всей истории: id события -> сумма в TON.
+type chainHistory struct {
+	in, out  map[string]decimal.Decimal
+	ids      map[string]string // нормализованный id -> как в TonAPI
+	events   int
+	complete bool
+}
+
+func (r *Reconciler) history(ctx context.Context, wallet string) (*chainHistory, error) {
+	h := &chainHistory{in: make(map[string]decimal.Decimal), out: make(map[string]decimal.Decimal), ids: make(map[string]string)}
+	var before int64
+	for page := 0; page < r.maxPages; page++ {
+		evs, err := r.client.GetAccountEventsPage(ctx, wallet, r.pageSize, before)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range evs.Events {
+			h.events++
+			for _, a := range ev.Actions {
+				if !equalsFold(a.Type, "TonTransfer") || !settled(a) {
+					continue // прерванные и отскочившие переводы деньги не перемещают
+				}
+				amount, err := nanosStrToTon(a.Amount)
+				if err != nil {
+					continue
+				}
+				id := normKey(ev.EventID)
+				h.ids[id] = ev.EventID
+				switch {
+				case SameAddress(a.Recipient, wallet):
+					h.in[id] = h.in[id].Add(amount)
+				case SameAddress(a.Sender, wallet):
+					h.out[id] = h.out[id].Add(amount)
+				}
+			}
+		}
+		if evs.NextFrom == 0 || len(evs.Events) == 0 {
+			h.complete = true
+			return h, nil
+		}
+		before = evs.NextFrom
+	}
+	return h, nil
+}
+
+func (r *Reconciler) reconcileWallet(ctx context.Context, wallet string, recs *records) (models.WalletReconciliation, []models.Discrepancy) {
+	wr := models.WalletReconciliation{Wallet: wallet}
+	h, err := r.history(ctx, wallet)
+	if err != nil {
+		wr.Error = err.Error()
+		return wr, nil
+	}
+	wr.Events, wr.Incoming, wr.Outgoing, wr.Complete = h.events, len(h.in), len(h.out), h.complete
+
+	var ds []models.Discrepancy
+	add := func(d models.Discrepancy) {
+		d.Wallet = wallet
+		ds = append(ds, d)
+	}
+
+	// что должно быть в сети по нашим записям
+	recordedIn := make(map[string]bool)
+	for id := range recs.orphans {
+		recordedIn[id] = true
+	}
+	for _, inv := range recs.invoices {
+		to := inv.MerchantAddress
+		if inv.DepositAddress != "" {
+			to = inv.DepositAddress
+		}
+		if !SameAddress(to, wallet) {
+			continue
+		}
+		tx := normKey(inv.TxHash)
+		recordedIn[tx] = true
+		paid, _ := decimal.NewFromString(inv.PaidAmountTon)
+		if chain, ok := h.in[tx]; ok && !chain.Equal(paid) {
+			add(models.Discrepancy{Kind: models.DiscrepancyAmountMismatch, EventID: inv.TxHash, InvoiceID: inv.ID, Expected: paid.StringFixed(9), Actual: chain.StringFixed(9)})
+		} else if !ok && h.complete {
+			add(models.Discrepancy{Kind: models.DiscrepancyMissingOnChain, EventID: inv.TxHash, InvoiceID: inv.ID, Expected: paid.StringFixed(9)})
+		}
+		if r.ledger != nil {
+			r.checkInvoiceEntry(inv, recs.entries, add)
+		}
+	}
+
+	account := ledger.Wallet(LedgerWallet(wallet))
+	recordedOut := make(map[string]bool)
+	postedIn := make(map[string]bool)
+	opened := false
+	for _, e := range recs.entries {
+		for _, ln := range e.Lines {
+			if ln.Account != account {
+				continue
+			}
+			if e.Kind == ledger.KindOpening {
+				opened = true
+				continue
+			}
+			tx := normKey(entryTx(e))
+			if ln.Debit.IsPositive() {
+				recordedIn[tx] = true
+				postedIn[tx] = true
+				continue
+			}
+			recordedOut[tx] = true
+			if _, ok := h.out[tx]; !ok && h.complete {
+				add(models.Discrepancy{Kind: models.DiscrepancyMissingOnChain, EventID: entryTx(e), Expected: ln.Credit.StringFixed(9), Details: e.ID})
+			}
+		}
+	}
+
+	for _, id := range sortedKeys(h.in) {
+		if !recordedIn[id] {
+			add(models.Discrepancy{Kind: models.DiscrepancyUnrecordedTransfer, EventID: h.ids[id], Actual: h.in[id].StringFixed(9)})
+		}
+	}
+	for _, id := range sortedKeys(h.out) {
+		if !recordedOut[id] {
+			add(models.Discrepancy{Kind: models.DiscrepancyUnrecordedOutgoing, EventID: h.ids[id], Actual: h.out[id].StringFixed(9)})
+		}
+	}
+
+	nanos, _, err := r.client.GetAccount(ctx, wallet)
+	if err != nil {
+		wr.Error = fmt.Sprintf("balance: %v", err)
+		return wr, ds
+	}
+	chain := decimal.RequireFromString(nanosIntToTonString(nanos))
+	wr.ChainBalanceTon = chain.StringFixed(9)
+	if r.ledger != nil {
+		bal, err := r.ledger.Balance(account, ledger.CurrencyTON)
+		if err == nil {
+			wr.LedgerBalanceTon = bal.StringFixed(9)
+			// «потерянные» платежи в журнал не проводятся, но деньги на кошельке есть
+			expected := bal
+			for id := range recs.orphans {
+				if amount, ok := h.in[id]; ok && !postedIn[id] {
+					expected = expected.Add(amount)
+				}
+			}
+			// без входящего остатка журнал не знает средств, пришедших до начала учёта, — сравнивать не с чем
+			if opened && h.complete && !expected.Equal(chain) {
+				add(models.Discrepancy{Kind: models.DiscrepancyBalanceDrift, Expected: expected.StringFixed(9), Actual: wr.ChainBalanceTon, Details: "drift " + chain.Sub(expected).StringFixed(9)})
+			}
+		}
+	}
+	return wr, ds
+}
+
+// checkInvoiceEntry — у оплаченного инвойса есть проводка на ту же сумму.
+func (r *Reconciler) checkInvoiceEntry(inv models.Invoice, entries []ledger.Entry, add func(models.Discrepancy)) {
+	id := ledger.KindPayment + ":" + inv.ID
+	for _, e := range entries {
+		if e.ID != id {
+			continue
+		}
+		paid, _ := decimal.NewFromString(inv.PaidAmountTon)
+		if amount := e.Lines[0].Debit; !amount.Equal(paid) {
+			add(models.Discrepancy{Kind: models.DiscrepancyAmountMismatch, EventID: inv.TxHash, InvoiceID: inv.ID, Expected: paid.StringFixed(9), Actual: amount.StringFixed(9), Details: "ledger " + e.ID})
+		}
+		return
+	}
+	add(models.Discrepancy{Kind: models.DiscrepancyMissingLedger, EventID: inv.TxHash, InvoiceID: inv.ID, Expected: inv.PaidAmountTon})
+}
+
+// entryTx — транзакция проводки: у оплат это Memo (Reference — id инвойса).
+func entryTx(e ledger.Entry) string {
+	if e.Kind == ledger.KindPayment && e.Memo != "" {
+		return e.Memo
+	}
+	return e.Reference
+}
+
+func sortedKeys(m map[string]decimal.Decimal) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"payment-service/ledger"
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+func TestReconcile_ReportsDiscrepancies(t *testing.T) {
+	w := testWallet(1)
+	raw := LedgerWallet(w) // TonAPI отдаёт адреса в raw-формате
+	in := func(id, nanos string) Event {
+		return Event{EventID: id, Actions: []EventAction{{Type: "TonTransfer", Amount: nanos, Recipient: raw, Sender: "0:" + "bb"}}}
+	}
+	var pages []int64
+	mock := &mockTonAPI{
+		pageFn: func(_ context.Context, _ string, _ int, before int64) (Events, error) {
+			pages = append(pages, before)
+			if before == 0 {
+				return Events{NextFrom: 100, Events: []Event{
+					in("E3", "700000000"),
+					{EventID: "E4", Actions: []EventAction{{Type: "TonTransfer", Amount: "300000000", Sender: raw, Recipient: "0:cc"}}},
+				}}, nil
+			}
+			return Events{Events: []Event{in("E1", "2000000000"), in("E2", "1500000000")}}, nil
+		},
+		accountFn: func(context.Context, string) (int64, string, error) { return 10_000_000_000, "active", nil },
+	}
+
+	store := storage.NewMemory()
+	invoices := newTestInvoices(t)
+	journal := ledger.New(store)
+	invoices.SetLedger(journal)
+	for _, p := range []struct{ comment, amount, event string }{{"A", "2", "E1"}, {"B", "1", "E2"}, {"C", "1", "E9"}} {
+		invoices.Create(context.Background(), models.CreateInvoiceRequest{MerchantAddress: w, AmountTon: p.amount, Comment: p.comment})
+		invoices.OnTransfer(context.Background(), IncomingTransfer{EventID: p.event, Wallet: w, Amount: p.amount, Comment: p.comment})
+	}
+
+	// остаток на начало учёта 5 + оплаты 4 = 9, в сети 10
+	journal.Post(ledger.Opening("start", raw, decimal.NewFromInt(5), ledger.CurrencyTON, time.Now()))
+
+	r := NewReconciler(NewTONServiceWithClient(mock), store, invoices, nil, journal)
+	rep, err := r.Run(context.Background(), []string{w, raw})
+	if err != nil { t.Fatalf("run: %v", err) }
+	if len(pages) != 2 || pages[1] != 100 { t.Fatalf("pagination: %v", pages) }
+	if len(rep.Wallets) != 1 || !rep.Wallets[0].Complete || rep.Wallets[0].Events != 4 { t.Fatalf("wallets: %+v", rep.Wallets) }
+
+	got := map[string]string{}
+	for _, d := range rep.Discrepancies {
+		got[d.Kind] += d.EventID + " "
+	}
+	want := map[string]string{
+		models.DiscrepancyAmountMismatch:     "E2 ",
+		models.DiscrepancyMissingOnChain:     "E9 ",
+		models.DiscrepancyUnrecordedTransfer: "E3 ",
+		models.DiscrepancyUnrecordedOutgoing: "E4 ",
+		models.DiscrepancyBalanceDrift:       " ",
+	}
+	if len(got) != len(want) { t.Fatalf("discrepancies: %+v", rep.Discrepancies) }
+	for k, v := range want {
+		if got[k] != v { t.Errorf("%s: got %q, want %q", k, got[k], v) }
+	}
+
+	last, _ := r.Last()
+	if last == nil || len(last.Discrepancies) != len(rep.Discrepancies) { t.Fatalf("last report not saved") }
+}
+
+// «Потерянный» платёж лежит на кошельке, но не в журнале: это не расхождение баланса.
+// Кошелёк без входящего остатка по балансу не сверяется вовсе.
+func TestReconcile_BalanceCountsOrphansAndNeedsOpening(t *testing.T) {
+	w := testWallet(1)
+	raw := LedgerWallet(w)
+	mock := &mockTonAPI{
+		pageFn: func(context.Context, string, int, int64) (Events, error) {
+			return Events{Events: []Event{
+				{EventID: "E2", Actions: []EventAction{{Type: "TonTransfer", Amount: "700000000", Recipient: raw, Sender: "0:bb"}}},
+				{EventID: "E1", Actions: []EventAction{{Type: "TonTransfer", Amount: "2000000000", Recipient: raw, Sender: "0:bb", Payload: &EventPayload{Type: "comment", Text: "A"}}}},
+			}}, nil
+		},
+		accountFn: func(context.Context, string) (int64, string, error) { return 3_700_000_000, "active", nil },
+	}
+	store := storage.NewMemory()
+	invoices := newTestInvoices(t)
+	journal := ledger.New(store)
+	invoices.SetLedger(journal)
+	orphans := NewOrphanService(store, invoices)
+	invoices.SetOrphans(orphans)
+	invoices.Create(context.Background(), models.CreateInvoiceRequest{MerchantAddress: w, AmountTon: "2", Comment: "A"})
+	invoices.OnTransfer(context.Background(), IncomingTransfer{EventID: "E1", Wallet: w, Amount: "2", Comment: "A"})
+	invoices.OnTransfer(context.Background(), IncomingTransfer{EventID: "E2", Wallet: w, Amount: "0.7"})
+
+	r := NewReconciler(NewTONServiceWithClient(mock), store, invoices, orphans, journal)
+	drift := func() []models.Discrepancy {
+		rep, err := r.Run(context.Background(), []string{w})
+		if err != nil { t.Fatalf("run: %v", err) }
+		return rep.Discrepancies
+	}
+	if ds := drift(); len(ds) != 0 { t.Fatalf("without opening balance: %+v", ds) }
+
+	journal.Post(ledger.Opening("start", raw, decimal.NewFromInt(1), ledger.CurrencyTON, time.Now()))
+	if ds := drift(); len(ds) != 0 { t.Fatalf("opening 1 + paid 2 + orphan 0.7 = chain 3.7: %+v", ds) }
+
+	journal.Post(ledger.Opening("more", raw, decimal.NewFromInt(1), ledger.CurrencyTON, time.Now()))
+	ds := drift()
+	if len(ds) != 1 || ds[0].Kind != models.DiscrepancyBalanceDrift || ds[0].Expected != "4.700000000" { t.Fatalf("drift: %+v", ds) }
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/liteclient"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+)
+
+// LiteSweepSender — подписывает и отправляет перевод через liteserver'ы (tonutils-go).
+// TonAPI здесь не подходит: нужен seqno и отправка внешнего сообщения от имени кошелька.
+type LiteSweepSender struct {
+	configURL string
+	key       ed25519.PrivateKey
+	version   wallet.VersionConfig
+
+	mu  sync.Mutex
+	api ton.APIClientWrapped
+}
+
+func NewLiteSweepSender(configURL, masterSeed, version string) (*LiteSweepSender, error) {
+	seed, err := hex.DecodeString(strings.TrimSpace(masterSeed))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("deposit master seed must be %d bytes in hex", ed25519.SeedSize)
+	}
+	v, _, _, err := depositWalletVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return &LiteSweepSender{configURL: configURL, key: ed25519.NewKeyFromSeed(seed), version: v}, nil
+}
+
+// connect — подключение к пулу liteserver'ов лениво, при первом сметании.
+func (l *LiteSweepSender) connect(ctx context.Context) (ton.APIClientWrapped, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.api != nil {
+		return l.api, nil
+	}
+	pool := liteclient.NewConnectionPool()
+	if err := pool.AddConnectionsFromConfigUrl(ctx, l.configURL); err != nil {
+		return nil, fmt.Errorf("liteserver connect: %w", err)
+	}
+	l.api = ton.NewAPIClient(pool).WithRetry()
+	return l.api, nil
+}
+
+// Ping — доступность liteserver'ов: текущий блок мастерчейна.
+func (l *LiteSweepSender) Ping(ctx context.Context) error {
+	api, err := l.connect(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = api.CurrentMasterchainInfo(ctx)
+	return err
+}
+
+func (l *LiteSweepSender) SweepAll(ctx context.Context, subwalletID uint32, dest string) (string, error) {
+	api, err := l.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	to, err := parseTonAddress(dest)
+	if err != nil {
+		return "", fmt.Errorf("bad destination: %w", err)
+	}
+	master, err := wallet.FromPrivateKey(api, l.key, l.version)
+	if err != nil {
+		return "", err
+	}
+	w, err := master.GetSubwallet(subwalletID)
+	if err != nil {
+		return "", err
+	}
+	body, err := wallet.CreateCommentCell("sweep")
+	if err != nil {
+		return "", err
+	}
+	// mode 128: весь остаток баланса, комиссия — из него же
+	msg := &wallet.Message{
+		Mode: wallet.CarryAllRemainingBalance,
+		InternalMessage: &tlb.InternalMessage{
+			IHRDisabled: true,
+			Bounce:      false,
+			DstAddr:     to,
+			Amount:      tlb.ZeroCoins,
+			Body:        body,
+		},
+	}
+	hash, err := w.SendManyWaitTxHash(ctx, []*wallet.Message{msg})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
@@ -2,11 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 	"payment-service/models"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type TONService struct{ client TonAPI }
@@ -14,16 +16,21 @@ type TONService struct{ client TonAPI }
 func NewTONServiceWithClient(client TonAPI) *TONService { return &TONService{client: client} }
 
 func equalsFold(a, b string) bool { return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) }
+func normKey(s string) string { return strings.ToLower(strings.TrimSpace(s)) }
 
 func nanosStrToTon(nanoStr string) (decimal.Decimal, error) {
 	d, err := decimal.NewFromString(nanoStr); if err != nil { return decimal.Zero, err }
 	return d.Div(decimal.NewFromInt(1_000_000_000)).Truncate(9), nil
 }
+// tonToNanos — обратное к nanosStrToTon: TON -> нанотоны (точность та же, 9 знаков, с отсечением).
+func tonToNanos(ton decimal.Decimal) decimal.Decimal { return ton.Truncate(9).Mul(decimal.NewFromInt(1_000_000_000)) }
 func nanosIntToTonString(n int64) string {
 	return decimal.NewFromInt(n).Div(decimal.NewFromInt(1_000_000_000)).Truncate(9).StringFixed(9)
 }
 
-func (s *TONService) CheckPayment(ctx context.Context, req models.CheckPaymentRequest) (bool, error) {
+func (s *TONService) CheckPayment(ctx context.Context, req models.CheckPaymentRequest) (paid bool, err error) {
+	ctx, span := startSpan(ctx, "TONService.CheckPayment", attribute.String("ton.account", req.MerchantAddress))
+	defer func() { span.SetAttributes(attribute.Bool("payment.paid", paid)); endSpan(span, err) }()
 	limit := req.Limit; if limit <= 0 || limit > 200 { limit = 50 }
 	minTon, err := decimal.NewFromString(req.MinAmountTon); if err != nil { return false, fmt.Errorf("bad MinAmountTon: %w", err) }
 
@@ -32,7 +39,7 @@ func (s *TONService) CheckPayment(ctx context.Context, req models.CheckPaymentRe
 
 	for _, ev := range evs.Events {
 		for _, a := range ev.Actions {
-			if !equalsFold(a.Type, "TonTransfer") { continue }
+			if !equalsFold(a.Type, "TonTransfer") || !settled(a) { continue }
 			if !equalsFold(a.Recipient, req.MerchantAddress) { continue }
 			comment := ""
 			if a.Payload != nil && equalsFold(a.Payload.Type, "comment") { comment = a.Payload.Text }
@@ -45,12 +52,22 @@ func (s *TONService) CheckPayment(ctx context.Context, req models.CheckPaymentRe
 }
 
 func (s *TONService) WaitPayment(ctx context.Context, req models.CheckPaymentRequest, timeout, tick time.Duration) (bool, error) {
+	return s.PollPayment(ctx, req, timeout, tick, nil)
+}
+
+// PollPayment — WaitPayment с отчётом о каждой проверке (attempt с 1); ошибка onCheck прерывает ожидание.
+func (s *TONService) PollPayment(ctx context.Context, req models.CheckPaymentRequest, timeout, tick time.Duration, onCheck func(attempt int, paid bool) error) (paid bool, err error) {
+	ctx, span := startSpan(ctx, "TONService.PollPayment", attribute.String("ton.account", req.MerchantAddress))
+	defer func() { span.SetAttributes(attribute.Bool("payment.paid", paid)); endSpan(span, err) }()
 	if tick <= 0 { tick = 3 * time.Second }
 	if timeout <= 0 { timeout = 30 * time.Second }
 	deadline := time.Now().Add(timeout)
-	for {
+	for attempt := 1; ; attempt++ {
 		ok, err := s.CheckPayment(ctx, req)
 		if err != nil { return false, err }
+		if onCheck != nil {
+			if err := onCheck(attempt, ok); err != nil { return false, err }
+		}
 		if ok { return true, nil }
 		if time.Now().After(deadline) { return false, nil }
 		select {
@@ -60,84 +77,169 @@ func (s *TONService) WaitPayment(ctx context.Context, req models.CheckPaymentReq
 	}
 }
 
-func (s *TONService) GetAccountInfo(ctx context.Context, accountID string) (*models.AccountInfo, error) {
+func (s *TONService) GetAccountInfo(ctx context.Context, accountID string) (_ *models.AccountInfo, err error) {
+	ctx, span := startSpan(ctx, "TONService.GetAccountInfo", attribute.String("ton.account", accountID))
+	defer func() { endSpan(span, err) }()
 	balNanos, status, err := s.client.GetAccount(ctx, accountID)
 	if err != nil { return nil, fmt.Errorf("failed to get account: %w", err) }
 	return &models.AccountInfo{ Address: accountID, Balance: nanosIntToTonString(balNanos), Status: status }, nil
 }
 
-// Проверяем, что у адреса есть событие с таким txHash (event_id)
-// и среди действий есть TonTransfer, где участвует walletAddress.
-func (s *TONService) ValidateTransaction(ctx context.Context, txHash, walletAddress string) (bool, error) {
-	evs, err := s.client.GetAccountEvents(ctx, walletAddress, 100)
+// ValidateTransaction — событие по txHash (event_id, hash транзакции или сообщения) и перевод (TON или джеттон),
+// где участвует walletAddress. Заданные в req ожидания сверяются с переводом;
+// Valid — перевод найден, не прерван, не отскочил и расхождений нет.
+func (s *TONService) ValidateTransaction(ctx context.Context, req models.PaymentCheckByTxRequest) (_ *models.TransactionValidation, err error) {
+	ctx, span := startSpan(ctx, "TONService.ValidateTransaction", attribute.String("ton.account", req.WalletAddress), attribute.String("ton.tx_hash", req.TxHash))
+	defer func() { endSpan(span, err) }()
+	ev, found, err := s.lookupEvent(ctx, req.TxHash)
 	if err != nil {
-		return false, fmt.Errorf("GetAccountEvents: %w", err)
+		return nil, err
 	}
-	for _, ev := range evs.Events {
-		if ev.EventID != "" && ev.EventID == txHash {
-			// Ищем участие адреса в переводе
-			for _, a := range ev.Actions {
-				if equalsFold(a.Type, "TonTransfer") &&
-					(equalsFold(a.Recipient, walletAddress) || equalsFold(a.Sender, walletAddress)) {
-					return true, nil
-				}
-			}
-			// Если действий нет/не распарсили — на твое усмотрение.
-			// Здесь считаем валидным по совпадению event_id.
-			return true, nil
+	if !found {
+		return &models.TransactionValidation{}, nil
+	}
+	// из нескольких переводов адреса берём наиболее похожий на ожидаемый
+	var best *models.TransactionValidation
+	for _, t := range eventTransactions(ev) {
+		if t.Kind != models.TxKindTonTransfer && t.Kind != models.TxKindJettonTransfer {
+			continue
+		}
+		if !SameAddress(t.To, req.WalletAddress) && !SameAddress(t.From, req.WalletAddress) {
+			continue
+		}
+		res := &models.TransactionValidation{Status: t.Status, Transfer: &t, Mismatches: transferMismatches(t, req)}
+		res.Valid = t.Status == models.TxStatusOK && len(res.Mismatches) == 0
+		if best == nil || len(res.Mismatches) < len(best.Mismatches) {
+			best = res
 		}
 	}
-	return false, nil
+	if best == nil {
+		// событие есть, но перевода с участием адреса нет
+		return &models.TransactionValidation{Status: models.TxStatusOK, Mismatches: []models.FieldMismatch{
+			{Field: "wallet_address", Expected: req.WalletAddress},
+		}}, nil
+	}
+	return best, nil
+}
+
+// lookupEvent — событие по event_id, hash транзакции или hash входящего сообщения
+// (hex или base64) без обхода истории кошелька.
+func (s *TONService) lookupEvent(ctx context.Context, hash string) (Event, bool, error) {
+	h, err := normalizeHash(hash)
+	if err != nil {
+		h = strings.TrimSpace(hash) // event_id в формате провайдера
+	}
+	ev, found, err := s.eventByTx(ctx, h)
+	if found || err != nil {
+		return ev, found, err
+	}
+	// hash сообщения -> транзакция, которая его обработала
+	txHash, err := s.client.GetMessageTransaction(ctx, h)
+	if errors.Is(err, ErrTonAPINotFound) || err == nil && txHash == "" {
+		return Event{}, false, nil
+	}
+	if err != nil {
+		return Event{}, false, fmt.Errorf("GetMessageTransaction: %w", err)
+	}
+	return s.eventByTx(ctx, txHash)
+}
+
+// eventByTx — событие по event_id/hash транзакции; если событие ещё не
+// проиндексировано, оно собирается из самой транзакции.
+func (s *TONService) eventByTx(ctx context.Context, hash string) (Event, bool, error) {
+	ev, err := s.client.GetEvent(ctx, hash)
+	if err == nil {
+		return ev, true, nil
+	}
+	if !errors.Is(err, ErrTonAPINotFound) {
+		return Event{}, false, fmt.Errorf("GetEvent: %w", err)
+	}
+	tx, err := s.client.GetTransaction(ctx, hash)
+	if errors.Is(err, ErrTonAPINotFound) {
+		return Event{}, false, nil
+	}
+	if err != nil {
+		return Event{}, false, fmt.Errorf("GetTransaction: %w", err)
+	}
+	return eventFromTransaction(tx), true, nil
+}
+
+// eventFromTransaction — TonTransfer из входящего сообщения транзакции.
+func eventFromTransaction(tx Transaction) Event {
+	ts := tx.Utime
+	ev := Event{EventID: tx.Hash, Lt: tx.Lt, Timestamp: &ts}
+	if m := tx.InMsg; m != nil && m.Source != "" && m.Value > 0 {
+		a := EventAction{Type: "TonTransfer", Amount: fmt.Sprint(m.Value), Sender: m.Source, Recipient: m.Destination, Bounced: m.Bounced, Status: models.TxStatusOK}
+		if !tx.Success || tx.Aborted {
+			a.Status = models.TxStatusFailed
+		}
+		if m.Comment != "" {
+			a.Payload = &EventPayload{Type: "comment", Text: m.Comment}
+		}
+		ev.Actions = append(ev.Actions, a)
+	}
+	return ev
+}
+
+// transferMismatches — сверка перевода с заданными в запросе ожиданиями.
+func transferMismatches(t models.TransactionInfo, req models.PaymentCheckByTxRequest) []models.FieldMismatch {
+	var out []models.FieldMismatch
+	if req.SenderAddress != "" && !SameAddress(t.From, req.SenderAddress) {
+		out = append(out, models.FieldMismatch{Field: "sender_address", Expected: req.SenderAddress, Actual: t.From})
+	}
+	if req.Currency != "" {
+		jetton := ""
+		if t.Details != nil && t.Details.Jetton != nil {
+			jetton = t.Details.Jetton.Address
+		}
+		if !equalsFold(t.Currency, req.Currency) && (jetton == "" || !SameAddress(jetton, req.Currency)) {
+			out = append(out, models.FieldMismatch{Field: "currency", Expected: req.Currency, Actual: t.Currency})
+		}
+	}
+	if req.Amount != "" {
+		want, werr := decimal.NewFromString(req.Amount)
+		got, gerr := decimal.NewFromString(t.Amount)
+		if werr != nil || gerr != nil || !want.Equal(got) {
+			out = append(out, models.FieldMismatch{Field: "amount", Expected: req.Amount, Actual: t.Amount})
+		}
+	}
+	if req.Comment != "" && t.Comment != req.Comment {
+		out = append(out, models.FieldMismatch{Field: "comment", Expected: req.Comment, Actual: t.Comment})
+	}
+	return out
 }
 
 // История TON-переводов (входящие/исходящие) по аккаунту.
-// Маппим к models.TransactionInfo, как ждёт handler (Hash, From, To, Amount, Status, Timestamp, Comment, Currency).
-func (s *TONService) GetTransactionHistory(ctx context.Context, accountID string, limit int) ([]models.TransactionInfo, error) {
+// Фильтры применяются на нашей стороне: страницы TonAPI подтягиваются,
+// пока не наберётся limit подходящих переводов (или не кончится история).
+func (s *TONService) GetTransactionHistory(ctx context.Context, accountID string, limit int, f models.TransactionFilter) (_ []models.TransactionInfo, err error) {
+	ctx, span := startSpan(ctx, "TONService.GetTransactionHistory", attribute.String("ton.account", accountID), attribute.Int("history.limit", limit))
+	defer func() { endSpan(span, err) }()
 	if limit <= 0 || limit > 200 {
 		limit = 50
 	}
-	evs, err := s.client.GetAccountEvents(ctx, accountID, limit)
+	match, err := transferMatcher(accountID, f)
 	if err != nil {
-		return nil, fmt.Errorf("GetAccountEvents: %w", err)
+		return nil, err
 	}
 	out := make([]models.TransactionInfo, 0, limit)
-	for _, ev := range evs.Events {
-		var ts time.Time
-		if ev.Timestamp != nil && *ev.Timestamp > 0 {
-			ts = time.Unix(*ev.Timestamp, 0).UTC()
+	err = s.walkTransactions(ctx, accountID, f.From, f.To, historyMaxPages, func(t models.TransactionInfo) error {
+		if !match(&t) {
+			return nil
 		}
-		for _, a := range ev.Actions {
-			if !equalsFold(a.Type, "TonTransfer") {
-				continue
-			}
-			amt, err := nanosStrToTon(a.Amount)
-			if err != nil {
-				continue
-			}
-			comment := ""
-			if a.Payload != nil && equalsFold(a.Payload.Type, "comment") {
-				comment = a.Payload.Text
-			}
-			out = append(out, models.TransactionInfo{
-				Hash:      ev.EventID,
-				From:      a.Sender,
-				To:        a.Recipient,
-				Amount:    amt.StringFixed(9),
-				Status:    "ok",            // TonAPI обычно не даёт статус для action — ставим "ok"
-				Timestamp: ts,
-				Comment:   comment,
-				Currency:  "TON",
-			})
-			if len(out) >= limit {
-				return out, nil
-			}
+		out = append(out, t)
+		if len(out) >= limit {
+			return errStopWalk
 		}
-	}
-	return out, nil
+		return nil
+	})
+	return out, err
 }
 
 // Баланс кошелька строкой "X.YYYYYYYYY"
-func (s *TONService) GetWalletBalance(ctx context.Context, accountID string) (string, error) {
+func (s *TONService) GetWalletBalance(ctx context.Context, accountID string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "TONService.GetWalletBalance", attribute.String("ton.account", accountID))
+	defer func() { endSpan(span, err) }()
 	nanos, _, err := s.client.GetAccount(ctx, accountID)
 	if err != nil {
 		return "", fmt.Errorf("GetAccount: %w", err)
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,13 +15,22 @@ import (
 
 type mockTonAPI struct {
 	eventsFn   func(ctx context.Context, accountID string, limit int) (Events, error)
+	pageFn     func(ctx context.Context, accountID string, limit int, beforeLt int64) (Events, error)
 	accountFn  func(ctx context.Context, accountID string) (int64, string, error)
 	jettonsFn  func(ctx context.Context, accountID string) (any, error)
 	nftItemsFn func(ctx context.Context, accountID string) ([]map[string]any, error)
+	msgTxFn    func(ctx context.Context, msgHash string) (string, error)
+	eventFn    func(ctx context.Context, eventID string) (Event, error)
+	txFn       func(ctx context.Context, hash string) (Transaction, error)
 }
 func (m *mockTonAPI) GetAccountEvents(ctx context.Context, accountID string, limit int) (Events, error) {
 	return m.eventsFn(ctx, accountID, limit)
 }
+func (m *mockTonAPI) GetAccountEventsPage(ctx context.Context, accountID string, limit int, beforeLt int64) (Events, error) {
+	if m.pageFn != nil { return m.pageFn(ctx, accountID, limit, beforeLt) }
+	if beforeLt > 0 { return Events{}, nil }
+	return m.eventsFn(ctx, accountID, limit)
+}
 func (m *mockTonAPI) GetAccount(ctx context.Context, accountID string) (int64, string, error) {
 	if m.accountFn == nil { return 0, "", nil }
 	return m.accountFn(ctx, accountID)
@@ -31,6 +44,28 @@ func (m *mockTonAPI) GetAccountNftItems(ctx context.Context, accountID string) (
 	return m.nftItemsFn(ctx, accountID)
 }
 
+func (m *mockTonAPI) GetMessageTransaction(ctx context.Context, msgHash string) (string, error) {
+	if m.msgTxFn == nil { return "", nil }
+	return m.msgTxFn(ctx, msgHash)
+}
+
+// GetEvent по умолчанию ищет событие среди eventsFn.
+func (m *mockTonAPI) GetEvent(ctx context.Context, eventID string) (Event, error) {
+	if m.eventFn != nil { return m.eventFn(ctx, eventID) }
+	if m.eventsFn != nil {
+		evs, err := m.eventsFn(ctx, "", 100)
+		if err != nil { return Event{}, err }
+		for _, ev := range evs.Events {
+			if ev.EventID == eventID { return ev, nil }
+		}
+	}
+	return Event{}, ErrTonAPINotFound
+}
+func (m *mockTonAPI) GetTransaction(ctx context.Context, hash string) (Transaction, error) {
+	if m.txFn == nil { return Transaction{}, ErrTonAPINotFound }
+	return m.txFn(ctx, hash)
+}
+
 func TestCheckPayment_MatchTrue(t *testing.T) {
 	mock := &mockTonAPI{
 		eventsFn: func(ctx context.Context, accountID string, limit int) (Events, error) {
@@ -72,6 +107,78 @@ func TestCheckPayment_WrongComment(t *testing.T) {
 	if ok { t.Fatalf("expected false for wrong comment") }
 }
 
+func TestCheckPayment_IgnoresFailedAndBounced(t *testing.T) {
+	pay := func(id, status string, bounced bool) Event {
+		return Event{EventID: id, Actions: []EventAction{{Type: "TonTransfer", Amount: "3000000000", Recipient: "EQ_MERCHANT", Status: status, Bounced: bounced,
+			Payload: &EventPayload{Type: "comment", Text: "ORD-1"}}}}
+	}
+	mock := &mockTonAPI{eventsFn: func(context.Context, string, int) (Events, error) {
+		return Events{Events: []Event{pay("E1", "failed", false), pay("E2", "ok", true)}}, nil
+	}}
+	svc := NewTONServiceWithClient(mock)
+	ok, err := svc.CheckPayment(context.Background(), models.CheckPaymentRequest{MerchantAddress: "EQ_MERCHANT", Comment: "ORD-1", MinAmountTon: "3"})
+	if err != nil || ok { t.Fatalf("failed/bounced transfer counted as paid: %v %v", ok, err) }
+
+	for id, want := range map[string]string{"E1": models.TxStatusFailed, "E2": models.TxStatusBounced} {
+		res, err := svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: id, WalletAddress: "EQ_MERCHANT"})
+		if err != nil || res.Valid || res.Status != want || res.Transfer == nil || res.Transfer.Status != want { t.Fatalf("%s: %+v, err %v", id, res, err) }
+	}
+	if res, _ := svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: "E9", WalletAddress: "EQ_MERCHANT"}); res.Valid || res.Status != "" { t.Fatalf("missing event: %+v", res) }
+}
+
+func TestValidateTransaction_ChecksExpectations(t *testing.T) {
+	mock := &mockTonAPI{eventsFn: func(context.Context, string, int) (Events, error) {
+		return Events{Events: []Event{
+			{EventID: "E1", Actions: []EventAction{{Type: "TonTransfer", Amount: "1500000000", Sender: "EQ_PAYER", Recipient: "EQ_MERCHANT", Payload: &EventPayload{Type: "comment", Text: "ORD-1"}}}},
+			{EventID: "E2", Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_X", Recipient: "EQ_Y"}}},
+		}}, nil
+	}}
+	svc := NewTONServiceWithClient(mock)
+	req := models.PaymentCheckByTxRequest{TxHash: "E1", WalletAddress: "EQ_MERCHANT", SenderAddress: "EQ_PAYER", Amount: "1.5", Currency: "ton", Comment: "ORD-1"}
+	res, err := svc.ValidateTransaction(context.Background(), req)
+	if err != nil || !res.Valid || res.Transfer == nil || res.Transfer.Amount != "1.500000000" || len(res.Mismatches) != 0 { t.Fatalf("match: %+v, err %v", res, err) }
+
+	req.SenderAddress, req.Amount, req.Comment = "EQ_OTHER", "2", "ORD-2"
+	res, _ = svc.ValidateTransaction(context.Background(), req)
+	if res.Valid || len(res.Mismatches) != 3 || res.Mismatches[0].Field != "sender_address" || res.Mismatches[1].Field != "amount" || res.Mismatches[2].Actual != "ORD-1" { t.Fatalf("mismatches: %+v", res.Mismatches) }
+
+	// событие есть, но без перевода с участием кошелька — больше не считается валидным
+	res, _ = svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: "E2", WalletAddress: "EQ_MERCHANT"})
+	if res.Valid || len(res.Mismatches) != 1 || res.Mismatches[0].Field != "wallet_address" { t.Fatalf("no transfer: %+v", res) }
+}
+
+func TestValidateTransaction_LooksUpByHash(t *testing.T) {
+	evHash, txHash, msgHash := strings.Repeat("ab", 32), strings.Repeat("cd", 32), strings.Repeat("ef", 32)
+	raw, _ := hex.DecodeString(txHash)
+	var eventCalls []string
+	mock := &mockTonAPI{
+		eventFn: func(_ context.Context, id string) (Event, error) {
+			eventCalls = append(eventCalls, id)
+			if id != evHash { return Event{}, ErrTonAPINotFound }
+			return Event{EventID: evHash, Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Sender: "EQ_PAYER", Recipient: "EQ_MERCHANT"}}}, nil
+		},
+		txFn: func(_ context.Context, h string) (Transaction, error) {
+			if h != txHash { return Transaction{}, ErrTonAPINotFound }
+			return Transaction{Hash: txHash, Utime: 100, Aborted: true, InMsg: &TxMessage{Source: "EQ_PAYER", Destination: "EQ_MERCHANT", Value: 2_000_000_000, Comment: "ORD-1"}}, nil
+		},
+		msgTxFn: func(_ context.Context, h string) (string, error) {
+			if h != msgHash { return "", ErrTonAPINotFound }
+			return evHash, nil
+		},
+	}
+	svc := NewTONServiceWithClient(mock)
+
+	// старое событие — без обхода последних событий кошелька
+	res, err := svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: "0x" + strings.ToUpper(evHash), WalletAddress: "EQ_MERCHANT"})
+	if err != nil || !res.Valid || eventCalls[0] != evHash { t.Fatalf("event id: %+v, err %v, calls %v", res, err, eventCalls) }
+	// base64 hash транзакции, событие ещё не проиндексировано — собирается из транзакции
+	res, _ = svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: base64.URLEncoding.EncodeToString(raw), WalletAddress: "EQ_MERCHANT", Amount: "2", Comment: "ORD-1"})
+	if res.Valid || res.Status != models.TxStatusFailed || len(res.Mismatches) != 0 { t.Fatalf("tx hash: %+v", res) }
+	// hash сообщения
+	if res, _ = svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: msgHash, WalletAddress: "EQ_MERCHANT"}); !res.Valid { t.Fatalf("msg hash: %+v", res) }
+	if res, _ = svc.ValidateTransaction(context.Background(), models.PaymentCheckByTxRequest{TxHash: strings.Repeat("00", 32), WalletAddress: "EQ_MERCHANT"}); res.Valid || res.Status != "" { t.Fatalf("unknown: %+v", res) }
+}
+
 func TestWaitPayment_TwoPolls(t *testing.T) {
 	call := 0
 	mock := &mockTonAPI{
@@ -102,4 +209,40 @@ func TestNanosStrToTon(t *testing.T) {
 	if err != nil { t.Fatalf("err: %v", err) }
 	want := decimal.RequireFromString("3.000000000")
 	if !got.Equal(want) { t.Fatalf("want %s got %s", want, got) }
-}
\ No newline at end of file
+}
+func TestGetTransactionHistory_FiltersAcrossPages(t *testing.T) {
+	tr := func(id, from, to, nanos, comment string, ts int64) Event {
+		return Event{EventID: id, Timestamp: &ts, Actions: []EventAction{{Type: "TonTransfer", Amount: nanos, Sender: from, Recipient: to,
+			Payload: &EventPayload{Type: "comment", Text: comment}}}}
+	}
+	var pages int
+	mock := &mockTonAPI{pageFn: func(_ context.Context, _ string, _ int, before int64) (Events, error) {
+		pages++
+		switch before {
+		case 0:
+			return Events{NextFrom: 1, Events: []Event{
+				tr("E5", "EQ_A", "EQ_M", "5000000000", "ORD-5", 500),
+				tr("E4", "EQ_M", "EQ_A", "4000000000", "ORD-4", 400),
+			}}, nil
+		case 1:
+			return Events{NextFrom: 2, Events: []Event{
+				tr("E3", "EQ_B", "EQ_M", "3000000000", "ORD-3", 300),
+				tr("E2", "EQ_A", "EQ_M", "2000000000", "XX-2", 200),
+			}}, nil
+		}
+		return Events{Events: []Event{tr("E1", "EQ_A", "EQ_M", "1000000000", "ORD-1", 100)}}, nil
+	}}
+	svc := NewTONServiceWithClient(mock)
+
+	got, err := svc.GetTransactionHistory(context.Background(), "EQ_M", 2, models.TransactionFilter{
+		Direction: "in", Counterparty: "EQ_A", Comment: "ORD-", CommentMatch: "prefix", MaxAmount: "4.5",
+	})
+	if err != nil { t.Fatalf("err: %v", err) }
+	if len(got) != 1 || got[0].Hash != "E1" || got[0].Direction != "in" || pages != 3 { t.Fatalf("got %+v after %d pages", got, pages) }
+
+	pages = 0
+	got, _ = svc.GetTransactionHistory(context.Background(), "EQ_M", 10, models.TransactionFilter{From: time.Unix(300, 0), To: time.Unix(500, 0), MinAmount: "3"})
+	if len(got) != 2 || got[0].Hash != "E4" || got[0].Direction != "out" || got[1].Hash != "E3" || pages != 2 { t.Fatalf("range: %+v after %d pages", got, pages) }
+
+	if _, err := svc.GetTransactionHistory(context.Background(), "EQ_M", 10, models.TransactionFilter{Direction: "up"}); !errors.Is(err, ErrBadFilter) { t.Fatalf("want ErrBadFilter, got %v", err) }
+}
@@ -1,6 +1,12 @@
 package services
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrTonAPINotFound — TonAPI ответил 404 (событие/транзакция неизвестны или ещё не проиндексированы).
+var ErrTonAPINotFound = errors.New("tonapi: not found")
 
 type EventPayload struct {
 	Type string // "comment"
@@ -12,19 +18,78 @@ type EventAction struct {
 	Recipient string
 	Sender    string
 	Payload   *EventPayload
+	Details   *ActionDetails // для не-TonTransfer действий
+	Status    string         // "ok" | "failed" (пусто — ok)
+	Bounced   bool           // перевод отскочил и вернулся отправителю
+}
+
+// JettonRef — мастер-контракт джеттона.
+type JettonRef struct {
+	Address  string
+	Symbol   string
+	Name     string
+	Decimals int
+}
+
+// ActionDetails — детали джеттон/NFT-переводов, свопов и вызовов контрактов.
+// Суммы — в минимальных единицах актива (для TON — нанотоны).
+type ActionDetails struct {
+	Jetton       *JettonRef // JettonTransfer
+	JettonAmount string
+	Nft          string // NftItemTransfer: адрес NFT
+	Dex          string // JettonSwap
+	AmountIn     string
+	AmountOut    string
+	JettonIn     *JettonRef // nil — TON
+	JettonOut    *JettonRef
+	Contract     string   // ContractDeploy / SmartContractExec
+	Interfaces   []string // ContractDeploy
+	Operation    string   // SmartContractExec
+	Description  string   // simple_preview.description
 }
 type Event struct {
 	EventID   string
+	Lt        int64 // логическое время события
 	Timestamp *int64
 	Actions   []EventAction
 }
+// Transaction — транзакция аккаунта (/v2/blockchain/transactions/{hash}).
+type Transaction struct {
+	Hash      string // hex
+	Lt        int64
+	Account   string
+	Utime     int64
+	Success   bool
+	Aborted   bool
+	TotalFees int64 // нанотоны
+	InMsg     *TxMessage
+	OutMsgs   []TxMessage
+}
+type TxMessage struct {
+	Hash        string
+	Source      string
+	Destination string
+	Value       int64 // нанотоны
+	FwdFee      int64 // плата за пересылку исходящего сообщения
+	Bounced     bool
+	Comment     string
+}
 type Events struct {
-	Events []Event
+	Events   []Event
+	NextFrom int64 // lt для следующей страницы; 0 — история закончилась
 }
 
 type TonAPI interface {
 	GetAccountEvents(ctx context.Context, accountID string, limit int) (Events, error)
+	// GetAccountEventsPage — страница истории событий старее beforeLt (0 — с самых новых).
+	GetAccountEventsPage(ctx context.Context, accountID string, limit int, beforeLt int64) (Events, error)
 	GetAccount(ctx context.Context, accountID string) (balanceNanos int64, status string, err error)
 	GetAccountJettonsBalances(ctx context.Context, accountID string) (any, error)
 	GetAccountNftItems(ctx context.Context, accountID string) ([]map[string]any, error)
+	// GetMessageTransaction — hash транзакции, обработавшей сообщение (hex).
+	GetMessageTransaction(ctx context.Context, msgHash string) (txHash string, err error)
+	// GetEvent — событие по event_id или hash любой его транзакции (hex); ErrTonAPINotFound, если нет.
+	GetEvent(ctx context.Context, eventID string) (Event, error)
+	// GetTransaction — транзакция по hash (hex); ErrTonAPINotFound, если нет.
+	GetTransaction(ctx context.Context, hash string) (Transaction, error)
 }
\ No newline at end of file
@@ -10,20 +10,29 @@ import (
 	"time"
 
 	"payment-service/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewTONService — фабрика сервиса: REST-адаптер к TonAPI (без SDK).
-func NewTONService(cfg *config.Config) (*TONService, error) {
-	client := NewRestTonAPIAdapter(cfg.TonApiURL, cfg.ApiKey)
+// URL и ключ TonAPI берутся из текущего снимка конфигурации на каждый запрос.
+func NewTONService(cfg *config.Live) (*TONService, error) {
+	client := NewLiveRestTonAPIAdapter(cfg)
 	return &TONService{client: client}, nil
 }
 
 // ---------------- REST TonAPI adapter ----------------
 
 type RestTonAPIAdapter struct {
-	base  string
-	token string
-	http  *http.Client
+	base    string
+	token   string
+	retries int
+	live    *config.Live // если задан — base, token и retries (MAX_RETRIES) берутся из него
+	http    *http.Client
 }
 
 func NewRestTonAPIAdapter(baseURL, token string) *RestTonAPIAdapter {
@@ -34,9 +43,37 @@ func NewRestTonAPIAdapter(baseURL, token string) *RestTonAPIAdapter {
 	}
 }
 
+// NewLiveRestTonAPIAdapter — адаптер, который следует за перезагрузкой TON_API_URL и API_KEY.
+func NewLiveRestTonAPIAdapter(live *config.Live) *RestTonAPIAdapter {
+	a := NewRestTonAPIAdapter("", "")
+	a.live = live
+	return a
+}
+
+// SetRetries — число повторов запроса при сетевой ошибке, 429 и 5xx (адаптер без config.Live; по умолчанию 0).
+func (a *RestTonAPIAdapter) SetRetries(n int) { a.retries = n }
+
+func (a *RestTonAPIAdapter) maxRetries() int {
+	if a.live != nil {
+		return a.live.Get().MaxRetries
+	}
+	return a.retries
+}
+
+func (a *RestTonAPIAdapter) baseURL() string {
+	if a.live != nil {
+		return strings.TrimRight(a.live.Get().TonApiURL, "/")
+	}
+	return a.base
+}
+
 func (a *RestTonAPIAdapter) auth(req *http.Request) {
-	if a.token != "" {
-		req.Header.Set("Authorization", "Bearer "+a.token)
+	token := a.token
+	if a.live != nil {
+		token = a.live.Get().ApiKey
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 }
 
@@ -62,13 +99,18 @@ func parseAddr(raw json.RawMessage) string {
 }
 
 // Сырой ответ TonAPI для /v2/accounts/{addr}/events
-// Мы читаем только то, что нужно для TonTransfer (+комментарий).
+// Действия разбираются по типу: TonTransfer, джеттоны, NFT, свопы, контракты.
 type tonapiEventsResp struct {
-	Events []struct {
-		EventID   string `json:"event_id"`
-		Timestamp int64  `json:"timestamp"`
-		Actions   []json.RawMessage `json:"actions"`
-	} `json:"events"`
+	Events   []tonapiEvent `json:"events"`
+	NextFrom int64         `json:"next_from"`
+}
+
+// tonapiEvent — событие в ответах /v2/accounts/{addr}/events и /v2/events/{id}.
+type tonapiEvent struct {
+	EventID   string            `json:"event_id"`
+	Lt        int64             `json:"lt"`
+	Timestamp int64             `json:"timestamp"`
+	Actions   []json.RawMessage `json:"actions"`
 }
 
 // В TonAPI action — "discriminated union": есть поле "type"
@@ -77,7 +119,9 @@ type tonapiEventsResp struct {
 // - верхний уровень action (если провайдер кладёт плоско);
 // - вложенный объект с деталями (ton_transfer/TonTransfer/transfer/...).
 type actionHeader struct {
-	Type string `json:"type"`
+	Type    string `json:"type"`
+	Status  string `json:"status"` // ok | failed
+	Bounced bool   `json:"bounced"`
 	// возможные "плоские" поля (если повезёт)
 	Amount    string          `json:"amount"`
 	Recipient json.RawMessage `json:"recipient"`
@@ -89,19 +133,34 @@ type actionHeader struct {
 	// всё остальное — оставим для ручного поиска вложенных объектов
 }
 
+// tonapiComment — comment в TonAPI приходит строкой, в старых схемах — объектом {type, text}.
+type tonapiComment struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (c *tonapiComment) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		c.Type, c.Text = "comment", s
+		return nil
+	}
+	type plain tonapiComment
+	return json.Unmarshal(b, (*plain)(c))
+}
+
 type nestedTransfer struct {
-	// разные возможные поля под разные версии схемы
-	Amount      string          `json:"amount"`
-	Value       string          `json:"value"`
+	// разные возможные поля под разные версии схемы;
+	// amount в TonAPI — число, у некоторых провайдеров — строка
+	Amount      json.Number     `json:"amount"`
+	Value       json.Number     `json:"value"`
 	Recipient   json.RawMessage `json:"recipient"`
 	Destination json.RawMessage `json:"destination"`
 	Sender      json.RawMessage `json:"sender"`
 	Source      json.RawMessage `json:"source"`
-	Comment     *struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	} `json:"comment"`
-	Payload *struct {
+	Comment     *tonapiComment  `json:"comment"`
+	Bounced     bool            `json:"bounced"`
+	Payload     *struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"payload"`
@@ -137,95 +196,215 @@ func findNestedTransfer(m map[string]json.RawMessage) *nestedTransfer {
 	return nil
 }
 
+type tonapiJetton struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+func (j *tonapiJetton) ref() *JettonRef {
+	if j == nil || j.Address == "" {
+		return nil
+	}
+	return &JettonRef{Address: j.Address, Symbol: j.Symbol, Name: j.Name, Decimals: j.Decimals}
+}
+
+// nestedDetails — объединение полей JettonTransfer, NftItemTransfer, JettonSwap,
+// ContractDeploy и SmartContractExec.
+type nestedDetails struct {
+	Sender          json.RawMessage `json:"sender"`
+	Recipient       json.RawMessage `json:"recipient"`
+	Amount          string          `json:"amount"`
+	Comment         string          `json:"comment"`
+	Jetton          *tonapiJetton   `json:"jetton"`
+	Nft             string          `json:"nft"`
+	Dex             string          `json:"dex"`
+	AmountIn        string          `json:"amount_in"`
+	AmountOut       string          `json:"amount_out"`
+	TonIn           int64           `json:"ton_in"`
+	TonOut          int64           `json:"ton_out"`
+	JettonMasterIn  *tonapiJetton   `json:"jetton_master_in"`
+	JettonMasterOut *tonapiJetton   `json:"jetton_master_out"`
+	UserWallet      json.RawMessage `json:"user_wallet"`
+	Router          json.RawMessage `json:"router"`
+	Address         string          `json:"address"`
+	Interfaces      []string        `json:"interfaces"`
+	Executor        json.RawMessage `json:"executor"`
+	Contract        json.RawMessage `json:"contract"`
+	TonAttached     int64           `json:"ton_attached"`
+	Operation       string          `json:"operation"`
+	Bounced         bool            `json:"bounced"`
+}
+
+// detailedActions — типы действий, для которых разбираются детали.
+var detailedActions = map[string]bool{
+	"JettonTransfer": true, "NftItemTransfer": true, "JettonSwap": true,
+	"ContractDeploy": true, "SmartContractExec": true,
+}
+
+// parseActionDetails — нормализация не-TonTransfer действия: участники,
+// сумма в нанотонах (если TON участвует) и детали; nil — тип не поддерживается.
+func parseActionDetails(actType string, m map[string]json.RawMessage) *EventAction {
+	raw, ok := m[actType]
+	if !ok || !detailedActions[actType] {
+		return nil
+	}
+	var d nestedDetails
+	if json.Unmarshal(raw, &d) != nil {
+		return nil
+	}
+	act := &EventAction{Type: actType, Details: &ActionDetails{}, Bounced: d.Bounced}
+	if rawPrev, ok := m["simple_preview"]; ok {
+		var prev struct {
+			Description string `json:"description"`
+		}
+		_ = json.Unmarshal(rawPrev, &prev)
+		act.Details.Description = prev.Description
+	}
+	switch actType {
+	case "JettonTransfer":
+		act.Sender, act.Recipient = parseAddr(d.Sender), parseAddr(d.Recipient)
+		act.Details.Jetton, act.Details.JettonAmount = d.Jetton.ref(), d.Amount
+		if d.Comment != "" {
+			act.Payload = &EventPayload{Type: "comment", Text: d.Comment}
+		}
+	case "NftItemTransfer":
+		act.Sender, act.Recipient = parseAddr(d.Sender), parseAddr(d.Recipient)
+		act.Details.Nft = d.Nft
+		if d.Comment != "" {
+			act.Payload = &EventPayload{Type: "comment", Text: d.Comment}
+		}
+	case "JettonSwap":
+		act.Sender, act.Recipient = parseAddr(d.UserWallet), parseAddr(d.Router)
+		act.Details.Dex = d.Dex
+		act.Details.JettonIn, act.Details.JettonOut = d.JettonMasterIn.ref(), d.JettonMasterOut.ref()
+		act.Details.AmountIn, act.Details.AmountOut = d.AmountIn, d.AmountOut
+		if act.Details.JettonIn == nil {
+			act.Details.AmountIn = fmt.Sprint(d.TonIn)
+			act.Amount = act.Details.AmountIn
+		}
+		if act.Details.JettonOut == nil {
+			act.Details.AmountOut = fmt.Sprint(d.TonOut)
+		}
+	case "ContractDeploy":
+		act.Recipient = d.Address
+		act.Details.Contract, act.Details.Interfaces = d.Address, d.Interfaces
+	case "SmartContractExec":
+		act.Sender, act.Recipient = parseAddr(d.Executor), parseAddr(d.Contract)
+		act.Details.Contract, act.Details.Operation = act.Recipient, d.Operation
+		act.Amount = fmt.Sprint(d.TonAttached)
+	}
+	return act
+}
+
 // GetAccountEvents — подтягиваем и нормализуем события из TonAPI под наш Events.
 func (a *RestTonAPIAdapter) GetAccountEvents(ctx context.Context, accountID string, limit int) (Events, error) {
+	return a.GetAccountEventsPage(ctx, accountID, limit, 0)
+}
+
+// GetAccountEventsPage — то же, но постранично: before_lt берётся из NextFrom предыдущей страницы.
+func (a *RestTonAPIAdapter) GetAccountEventsPage(ctx context.Context, accountID string, limit int, beforeLt int64) (Events, error) {
 	if limit <= 0 || limit > 200 {
 		limit = 50
 	}
-	u := fmt.Sprintf("%s/v2/accounts/%s/events?limit=%d", a.base, url.PathEscape(accountID), limit)
-
-	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
-	a.auth(req)
-
-	resp, err := a.http.Do(req)
-	if err != nil {
-		return Events{}, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode/100 != 2 {
-		return Events{}, fmt.Errorf("tonapi events status %d", resp.StatusCode)
+	u := fmt.Sprintf("%s/v2/accounts/%s/events?limit=%d", a.baseURL(), url.PathEscape(accountID), limit)
+	if beforeLt > 0 {
+		u += fmt.Sprintf("&before_lt=%d", beforeLt)
 	}
 
 	var er tonapiEventsResp
-	if err := json.NewDecoder(resp.Body).Decode(&er); err != nil {
+	err := a.get(ctx, "/v2/accounts/{account_id}/events", u, func(resp *http.Response) error {
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("tonapi events status %d", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&er)
+	})
+	if err != nil {
 		return Events{}, err
 	}
 
-	out := Events{Events: make([]Event, 0, len(er.Events))}
+	out := Events{Events: make([]Event, 0, len(er.Events)), NextFrom: er.NextFrom}
 	for _, ev := range er.Events {
-		ts := ev.Timestamp
-		dst := Event{EventID: ev.EventID, Timestamp: &ts}
-
-		for _, rawAct := range ev.Actions {
-			// читаем заголовок action
-			var head actionHeader
-			_ = json.Unmarshal(rawAct, &head)
-			actType := head.Type
-
-			// по умолчанию попробуем взять "плоские" поля
-			amount := head.Amount
-			recipient := parseAddr(head.Recipient)
-			sender := parseAddr(head.Sender)
-			var payload *EventPayload
-			if head.Payload != nil {
-				payload = &EventPayload{Type: head.Payload.Type, Text: head.Payload.Text}
-			}
+		out.Events = append(out.Events, ev.normalize())
+	}
+	return out, nil
+}
 
-			// если плоских полей нет — попробуем найти вложенный объект TonTransfer
-			if amount == "" || recipient == "" || sender == "" {
-				var asMap map[string]json.RawMessage
-				_ = json.Unmarshal(rawAct, &asMap)
-				if tr := findNestedTransfer(asMap); tr != nil {
-					if amount == "" {
-						if tr.Amount != "" {
-							amount = tr.Amount
-						} else if tr.Value != "" {
-							amount = tr.Value
-						}
+// normalize — событие TonAPI в наш Event: действия разбираются по типу.
+func (ev tonapiEvent) normalize() Event {
+	ts := ev.Timestamp
+	dst := Event{EventID: ev.EventID, Lt: ev.Lt, Timestamp: &ts}
+
+	for _, rawAct := range ev.Actions {
+		// читаем заголовок action
+		var head actionHeader
+		_ = json.Unmarshal(rawAct, &head)
+		actType := head.Type
+
+		// по умолчанию попробуем взять "плоские" поля
+		amount := head.Amount
+		recipient := parseAddr(head.Recipient)
+		sender := parseAddr(head.Sender)
+		var payload *EventPayload
+		if head.Payload != nil {
+			payload = &EventPayload{Type: head.Payload.Type, Text: head.Payload.Text}
+		}
+
+		var asMap map[string]json.RawMessage
+		_ = json.Unmarshal(rawAct, &asMap)
+		if act := parseActionDetails(actType, asMap); act != nil {
+			act.Status, act.Bounced = head.Status, act.Bounced || head.Bounced
+			dst.Actions = append(dst.Actions, *act)
+			continue
+		}
+
+		bounced := head.Bounced
+
+		// если плоских полей нет — попробуем найти вложенный объект TonTransfer
+		if amount == "" || recipient == "" || sender == "" {
+			if tr := findNestedTransfer(asMap); tr != nil {
+				bounced = bounced || tr.Bounced
+				if amount == "" {
+					if tr.Amount != "" {
+						amount = tr.Amount.String()
+					} else if tr.Value != "" {
+						amount = tr.Value.String()
 					}
+				}
+				if recipient == "" {
+					recipient = parseAddr(tr.Recipient)
 					if recipient == "" {
-						recipient = parseAddr(tr.Recipient)
-						if recipient == "" {
-							recipient = parseAddr(tr.Destination)
-						}
+						recipient = parseAddr(tr.Destination)
 					}
+				}
+				if sender == "" {
+					sender = parseAddr(tr.Sender)
 					if sender == "" {
-						sender = parseAddr(tr.Sender)
-						if sender == "" {
-							sender = parseAddr(tr.Source)
-						}
+						sender = parseAddr(tr.Source)
 					}
-					if payload == nil {
-						if tr.Payload != nil {
-							payload = &EventPayload{Type: tr.Payload.Type, Text: tr.Payload.Text}
-						} else if tr.Comment != nil {
-							payload = &EventPayload{Type: tr.Comment.Type, Text: tr.Comment.Text}
-						}
+				}
+				if payload == nil {
+					if tr.Payload != nil {
+						payload = &EventPayload{Type: tr.Payload.Type, Text: tr.Payload.Text}
+					} else if tr.Comment != nil {
+						payload = &EventPayload{Type: tr.Comment.Type, Text: tr.Comment.Text}
 					}
 				}
 			}
-
-			dst.Actions = append(dst.Actions, EventAction{
-				Type:      actType,
-				Amount:    amount,
-				Recipient: recipient,
-				Sender:    sender,
-				Payload:   payload,
-			})
 		}
-		out.Events = append(out.Events, dst)
+
+		dst.Actions = append(dst.Actions, EventAction{
+			Type:      actType,
+			Amount:    amount,
+			Recipient: recipient,
+			Sender:    sender,
+			Payload:   payload,
+			Status:    head.Status,
+			Bounced:   bounced,
+		})
 	}
-	return out, nil
+	return dst
 }
 
 type tonapiAccountResp struct {
@@ -234,23 +413,158 @@ type tonapiAccountResp struct {
 }
 
 func (a *RestTonAPIAdapter) GetAccount(ctx context.Context, accountID string) (int64, string, error) {
-	u := fmt.Sprintf("%s/v2/accounts/%s", a.base, url.PathEscape(accountID))
-	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
-	a.auth(req)
-
-	resp, err := a.http.Do(req)
+	u := fmt.Sprintf("%s/v2/accounts/%s", a.baseURL(), url.PathEscape(accountID))
+	var ar tonapiAccountResp
+	err := a.get(ctx, "/v2/accounts/{account_id}", u, func(resp *http.Response) error {
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("tonapi account status %d", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&ar)
+	})
 	if err != nil {
 		return 0, "", err
 	}
+	return ar.Balance, ar.Status, nil
+}
+
+// Status — доступность TonAPI по /v2/status (без обращения к конкретному аккаунту).
+func (a *RestTonAPIAdapter) Status(ctx context.Context) error {
+	var st struct {
+		RestOnline bool `json:"rest_online"`
+	}
+	if err := a.getJSON(ctx, "/v2/status", "/v2/status", "status", &st); err != nil {
+		return err
+	}
+	if !st.RestOnline {
+		return fmt.Errorf("tonapi reports rest_online=false")
+	}
+	return nil
+}
+
+// getJSON — GET base+path и декодирование JSON-ответа в out; route — шаблон path для трассировки.
+func (a *RestTonAPIAdapter) getJSON(ctx context.Context, route, path, what string, out any) error {
+	return a.get(ctx, route, a.baseURL()+path, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrTonAPINotFound, what)
+		}
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("tonapi %s status %d", what, resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	})
+}
+
+// get — GET u со спаном "tonapi GET route" (route — шаблон пути без идентификаторов) и trace context
+// в заголовках. Сетевая ошибка, 429 и 5xx повторяются до maxRetries раз с растущей паузой;
+// handle разбирает последний ответ, его ошибка тоже попадает в спан.
+func (a *RestTonAPIAdapter) get(ctx context.Context, route, u string, handle func(*http.Response) error) (err error) {
+	ctx, span := tracer.Start(ctx, "tonapi GET "+route, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.HTTPRequestMethodGet, semconv.URLTemplate(route), semconv.URLFull(u)))
+	defer func() { endSpan(span, err) }()
+
+	retries := a.maxRetries()
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
+		a.auth(req)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		resp, err = a.http.Do(req)
+		span.SetAttributes(semconv.HTTPRequestResendCount(attempt))
+		if attempt >= retries || !retryable(resp, err) {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * retryBackoff):
+		}
+	}
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
-	if resp.StatusCode/100 != 2 {
-		return 0, "", fmt.Errorf("tonapi account status %d", resp.StatusCode)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
 	}
-	var ar tonapiAccountResp
-	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
-		return 0, "", err
+	return handle(resp)
+}
+
+// retryBackoff — пауза перед первым повтором; перед n-м — n*retryBackoff.
+var retryBackoff = 200 * time.Millisecond
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
 	}
-	return ar.Balance, ar.Status, nil
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+func (a *RestTonAPIAdapter) GetMessageTransaction(ctx context.Context, msgHash string) (string, error) {
+	var tr struct {
+		Hash string `json:"hash"`
+	}
+	if err := a.getJSON(ctx, "/v2/blockchain/messages/{msg_id}/transaction", "/v2/blockchain/messages/"+url.PathEscape(msgHash)+"/transaction", "message transaction", &tr); err != nil {
+		return "", err
+	}
+	return tr.Hash, nil
+}
+
+func (a *RestTonAPIAdapter) GetEvent(ctx context.Context, eventID string) (Event, error) {
+	var ev tonapiEvent
+	if err := a.getJSON(ctx, "/v2/events/{event_id}", "/v2/events/"+url.PathEscape(eventID), "event", &ev); err != nil {
+		return Event{}, err
+	}
+	return ev.normalize(), nil
+}
+
+type tonapiTransaction struct {
+	Hash    string `json:"hash"`
+	Lt      int64  `json:"lt"`
+	Account struct {
+		Address string `json:"address"`
+	} `json:"account"`
+	Utime     int64 `json:"utime"`
+	Success   bool  `json:"success"`
+	Aborted   bool  `json:"aborted"`
+	TotalFees int64 `json:"total_fees"`
+	InMsg     *struct {
+		Hash        string          `json:"hash"`
+		Source      json.RawMessage `json:"source"`
+		Destination json.RawMessage `json:"destination"`
+		Value       int64           `json:"value"`
+		Bounced     bool            `json:"bounced"`
+		DecodedBody *struct {
+			Text string `json:"text"`
+		} `json:"decoded_body"`
+	} `json:"in_msg"`
+	OutMsgs []struct {
+		Destination json.RawMessage `json:"destination"`
+		Value       int64           `json:"value"`
+		FwdFee      int64           `json:"fwd_fee"`
+	} `json:"out_msgs"`
+}
+
+func (a *RestTonAPIAdapter) GetTransaction(ctx context.Context, hash string) (Transaction, error) {
+	var tr tonapiTransaction
+	if err := a.getJSON(ctx, "/v2/blockchain/transactions/{transaction_id}", "/v2/blockchain/transactions/"+url.PathEscape(hash), "transaction", &tr); err != nil {
+		return Transaction{}, err
+	}
+	out := Transaction{Hash: tr.Hash, Lt: tr.Lt, Account: tr.Account.Address, Utime: tr.Utime, Success: tr.Success, Aborted: tr.Aborted, TotalFees: tr.TotalFees}
+	if m := tr.InMsg; m != nil {
+		out.InMsg = &TxMessage{Hash: m.Hash, Source: parseAddr(m.Source), Destination: parseAddr(m.Destination), Value: m.Value, Bounced: m.Bounced}
+		if m.DecodedBody != nil {
+			out.InMsg.Comment = m.DecodedBody.Text
+		}
+	}
+	for _, m := range tr.OutMsgs {
+		out.OutMsgs = append(out.OutMsgs, TxMessage{Destination: parseAddr(m.Destination), Value: m.Value, FwdFee: m.FwdFee})
+	}
+	return out, nil
 }
 
 func (a *RestTonAPIAdapter) GetAccountJettonsBalances(context.Context, string) (any, error) {
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"payment-service/models"
+)
+
+const richEventsJSON = `{"events":[{"event_id":"E1","timestamp":100,"actions":[
+ {"type":"TonTransfer","status":"ok","TonTransfer":{"sender":{"address":"0:a"},"recipient":{"address":"0:m"},"amount":1500000000,"comment":"hi"}},
+ {"type":"JettonTransfer","status":"ok","JettonTransfer":{"sender":{"address":"0:m"},"recipient":{"address":"0:b"},"amount":"2500000","comment":"usdt",
+   "jetton":{"address":"0:usdt","name":"Tether USD","symbol":"USDT","decimals":6}},"simple_preview":{"description":"Transferring 2.5 USDT"}},
+ {"type":"NftItemTransfer","status":"ok","NftItemTransfer":{"sender":{"address":"0:b"},"recipient":{"address":"0:m"},"nft":"0:nft"}},
+ {"type":"JettonSwap","status":"ok","JettonSwap":{"dex":"stonfi","amount_in":"","amount_out":"1000000","ton_in":2000000000,
+   "user_wallet":{"address":"0:m"},"router":{"address":"0:r"},"jetton_master_out":{"address":"0:usdt","symbol":"USDT","decimals":6}}},
+ {"type":"ContractDeploy","status":"ok","ContractDeploy":{"address":"0:m","interfaces":["wallet_v4r2"]}},
+ {"type":"DomainRenew","status":"ok","DomainRenew":{"domain":"x.ton"}}
+]}]}`
+
+const failedEventsJSON = `{"events":[{"event_id":"E2","timestamp":200,"actions":[
+ {"type":"TonTransfer","status":"failed","TonTransfer":{"sender":{"address":"0:a"},"recipient":{"address":"0:m"},"amount":1000000000}},
+ {"type":"TonTransfer","status":"ok","bounced":true,"TonTransfer":{"sender":{"address":"0:m"},"recipient":{"address":"0:b"},"amount":2000000000}}
+]}]}`
+
+func TestRestAdapter_NormalizesActionKinds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(richEventsJSON)) }))
+	defer srv.Close()
+	svc := NewTONServiceWithClient(NewRestTonAPIAdapter(srv.URL, ""))
+
+	got, err := svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{})
+	if err != nil || len(got) != 6 { t.Fatalf("got %d, err %v", len(got), err) }
+	byKind := map[string]models.TransactionInfo{}
+	for _, tx := range got {
+		byKind[tx.Kind] = tx
+	}
+	if tx := byKind[models.TxKindTonTransfer]; tx.Amount != "1.500000000" || tx.Comment != "hi" || tx.Direction != "in" { t.Fatalf("ton: %+v", tx) }
+	if tx := byKind[models.TxKindJettonTransfer]; tx.Amount != "2.5" || tx.Currency != "USDT" || tx.Direction != "out" || tx.Details.Jetton.Address != "0:usdt" || tx.Details.Description == "" { t.Fatalf("jetton: %+v", tx) }
+	if tx := byKind[models.TxKindNftTransfer]; tx.Details.NFT != "0:nft" || tx.From != "0:b" { t.Fatalf("nft: %+v", tx) }
+	if tx := byKind[models.TxKindSwap]; tx.Details.AssetIn != "TON" || tx.Details.AmountIn != "2" || tx.Details.AssetOut != "USDT" || tx.Details.AmountOut != "1" || tx.Details.Dex != "stonfi" { t.Fatalf("swap: %+v", tx.Details) }
+	if tx := byKind[models.TxKindContractDeploy]; tx.Details.Contract != "0:m" || len(tx.Details.Interfaces) != 1 { t.Fatalf("deploy: %+v", tx.Details) }
+	if tx := byKind[models.TxKindOther]; tx.Details.ActionType != "DomainRenew" { t.Fatalf("other: %+v", tx.Details) }
+
+	got, err = svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{Types: []string{"jetton_transfer", "NFT_TRANSFER"}})
+	if err != nil || len(got) != 2 { t.Fatalf("types filter: %+v, err %v", got, err) }
+	if _, err := svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{Types: []string{"mint"}}); err == nil { t.Fatal("unknown type accepted") }
+}
+
+// min_amount/max_amount — в TON: 2.5 USDT не проходит min_amount=1.
+func TestRestAdapter_AmountBoundsOnlyMatchTon(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(richEventsJSON)) }))
+	defer srv.Close()
+	svc := NewTONServiceWithClient(NewRestTonAPIAdapter(srv.URL, ""))
+
+	got, err := svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{MinAmount: "1"})
+	if err != nil || len(got) != 2 { t.Fatalf("min_amount: %+v, err %v", got, err) }
+	for _, tx := range got {
+		if tx.Currency != "TON" { t.Fatalf("non-TON row passed amount bounds: %+v", tx) }
+	}
+	got, err = svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{Types: []string{"jetton_transfer"}, MaxAmount: "100"})
+	if err != nil || len(got) != 0 { t.Fatalf("jetton with max_amount: %+v, err %v", got, err) }
+}
+
+func TestRestAdapter_PropagatesStatusAndBounce(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(failedEventsJSON)) }))
+	defer srv.Close()
+	svc := NewTONServiceWithClient(NewRestTonAPIAdapter(srv.URL, ""))
+
+	got, err := svc.GetTransactionHistory(context.Background(), "0:m", 10, models.TransactionFilter{})
+	if err != nil || len(got) != 2 { t.Fatalf("got %+v, err %v", got, err) }
+	if got[0].Status != models.TxStatusFailed || got[1].Status != models.TxStatusBounced { t.Fatalf("statuses: %s, %s", got[0].Status, got[1].Status) }
+}
+
+func TestRestAdapter_RetriesTransientErrorsOnly(t *testing.T) {
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+	var calls atomic.Int32
+	status := http.StatusBadGateway
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { calls.Add(1); w.WriteHeader(status) }))
+	defer srv.Close()
+	api := NewRestTonAPIAdapter(srv.URL, "")
+	api.SetRetries(2)
+
+	if _, _, err := api.GetAccount(context.Background(), "0:m"); err == nil || calls.Load() != 3 { t.Fatalf("502: %d calls, err %v", calls.Load(), err) }
+	calls.Store(0)
+	status = http.StatusNotFound
+	if _, err := api.GetEvent(context.Background(), "e1"); !errors.Is(err, ErrTonAPINotFound) || calls.Load() != 1 { t.Fatalf("404: %d calls, err %v", calls.Load(), err) }
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"go.opentelemetry.io/otel/attribute"
+	"payment-service/models"
+)
+
+const defaultTonConnectValidity = 5 * time.Minute
+
+// CommentPayloadBOC — тело сообщения с текстовым комментарием
+// (op = 0, далее строка в snake-формате), сериализованное в base64 BOC.
+func CommentPayloadBOC(comment string) (string, error) {
+	b := cell.BeginCell()
+	if err := b.StoreUInt(0, 32); err != nil {
+		return "", err
+	}
+	if err := b.StoreStringSnake(comment); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.EndCell().ToBOC()), nil
+}
+
+// BuildTonConnectTransaction — готовый к отправке запрос sendTransaction.
+func BuildTonConnectTransaction(req models.TonConnectTxRequest, now time.Time) (*models.TonConnectTransaction, error) {
+	if _, err := parseTonAddress(req.MerchantAddress); err != nil {
+		return nil, fmt.Errorf("bad merchant address: %w", err)
+	}
+	ton, err := decimal.NewFromString(strings.TrimSpace(req.AmountTon))
+	if err != nil {
+		return nil, fmt.Errorf("bad AmountTon: %w", err)
+	}
+	nanos := tonToNanos(ton)
+	if !nanos.IsPositive() {
+		return nil, fmt.Errorf("bad AmountTon: must be at least 1 nanoton")
+	}
+
+	validity := defaultTonConnectValidity
+	if req.ValidForSec > 0 {
+		validity = time.Duration(req.ValidForSec) * time.Second
+	}
+
+	msg := models.TonConnectMessage{Address: strings.TrimSpace(req.MerchantAddress), Amount: nanos.String()}
+	if req.Comment != "" {
+		if msg.Payload, err = CommentPayloadBOC(req.Comment); err != nil {
+			return nil, fmt.Errorf("comment payload: %w", err)
+		}
+	}
+	return &models.TonConnectTransaction{
+		ValidUntil: now.Add(validity).Unix(),
+		Messages:   []models.TonConnectMessage{msg},
+	}, nil
+}
+
+// ExternalMessageHash — hash внешнего сообщения из BOC, который вернул кошелёк (hex).
+func ExternalMessageHash(boc string) (string, error) {
+	raw, err := decodeBase64Any(strings.TrimSpace(boc))
+	if err != nil {
+		return "", fmt.Errorf("bad boc encoding: %w", err)
+	}
+	c, err := cell.FromBOC(raw)
+	if err != nil {
+		return "", fmt.Errorf("bad boc: %w", err)
+	}
+	return hex.EncodeToString(c.Hash()), nil
+}
+
+// VerifyTonConnectTransaction — находит транзакцию по hash сообщения и сопоставляет её
+// событие (по hash, без обхода истории кошелька) с ожидаемым переводом мерчанту.
+func (s *TONService) VerifyTonConnectTransaction(ctx context.Context, req models.TonConnectVerifyRequest) (_ *models.TonConnectVerification, err error) {
+	ctx, span := startSpan(ctx, "TONService.VerifyTonConnectTransaction", attribute.String("ton.account", req.MerchantAddress))
+	defer func() { endSpan(span, err) }()
+	var msgHash string
+	switch {
+	case req.BOC != "":
+		msgHash, err = ExternalMessageHash(req.BOC)
+	case req.MessageHash != "":
+		msgHash, err = normalizeHash(req.MessageHash)
+	default:
+		err = fmt.Errorf("boc or message_hash is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var minTon decimal.Decimal
+	if req.AmountTon != "" {
+		if minTon, err = decimal.NewFromString(req.AmountTon); err != nil {
+			return nil, fmt.Errorf("bad AmountTon: %w", err)
+		}
+	}
+
+	out := &models.TonConnectVerification{MessageHash: msgHash}
+	txHash, err := s.client.GetMessageTransaction(ctx, msgHash)
+	if errors.Is(err, ErrTonAPINotFound) || err == nil && txHash == "" {
+		// сообщение ещё не обработано сетью — это не ошибка, клиент повторит позже
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetMessageTransaction: %w", err)
+	}
+	out.TxHash = txHash
+
+	ev, found, err := s.lookupEvent(ctx, txHash)
+	if err != nil || !found {
+		return out, err
+	}
+	out.Found = true
+	for _, t := range incomingTransfers(ev, req.MerchantAddress) {
+		out.Sender, out.Amount, out.Comment, out.Timestamp = t.Sender, t.Amount, t.Comment, t.Timestamp
+		amt := decimal.RequireFromString(t.Amount)
+		if amt.Cmp(minTon) >= 0 && (req.Comment == "" || t.Comment == req.Comment) {
+			out.Matched = true
+			break
+		}
+	}
+	return out, nil
+}
+
+func parseTonAddress(s string) (*address.Address, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		return address.ParseRawAddr(s)
+	}
+	return address.ParseAddr(s)
+}
+
+// normalizeHash — hash транзакции/сообщения из hex или base64(url) в hex нижнего регистра.
+func normalizeHash(s string) (string, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if len(s) == 64 {
+		if b, err := hex.DecodeString(s); err == nil {
+			return hex.EncodeToString(b), nil
+		}
+	}
+	b, err := decodeBase64Any(s)
+	if err != nil || len(b) != 32 {
+		return "", fmt.Errorf("bad hash %q: expected 32 bytes in hex or base64", s)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func decodeBase64Any(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("not a base64 string")
+}
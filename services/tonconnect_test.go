@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"payment-service/models"
+)
+
+func TestCommentPayloadBOC(t *testing.T) {
+	boc, err := CommentPayloadBOC("ORD-AB12CD34")
+	if err != nil { t.Fatalf("err: %v", err) }
+	raw, _ := base64.StdEncoding.DecodeString(boc)
+	c, err := cell.FromBOC(raw)
+	if err != nil { t.Fatalf("parse: %v", err) }
+	s := c.BeginParse()
+	if op := s.MustLoadUInt(32); op != 0 { t.Fatalf("op %d", op) }
+	if txt := s.MustLoadStringSnake(); txt != "ORD-AB12CD34" { t.Fatalf("comment %q", txt) }
+}
+
+func TestBuildTonConnectTransaction(t *testing.T) {
+	merchant := address.NewAddress(0, 0, make([]byte, 32)).String()
+	now := time.Unix(1_700_000_000, 0)
+	tx, err := BuildTonConnectTransaction(models.TonConnectTxRequest{
+		MerchantAddress: merchant, AmountTon: "1.5", Comment: "ORD-1",
+	}, now)
+	if err != nil { t.Fatalf("err: %v", err) }
+	if tx.ValidUntil != now.Add(5*time.Minute).Unix() { t.Fatalf("valid_until %d", tx.ValidUntil) }
+	if len(tx.Messages) != 1 || tx.Messages[0].Amount != "1500000000" || tx.Messages[0].Payload == "" {
+		t.Fatalf("unexpected messages: %+v", tx.Messages)
+	}
+}
+
+func TestVerifyTonConnectTransaction(t *testing.T) {
+	ext := cell.BeginCell().MustStoreUInt(42, 32).EndCell()
+	boc := base64.StdEncoding.EncodeToString(ext.ToBOC())
+	msgHash := hex.EncodeToString(ext.Hash())
+
+	mock := &mockTonAPI{
+		msgTxFn: func(ctx context.Context, h string) (string, error) {
+			if h != msgHash { t.Fatalf("unexpected msg hash %s", h) }
+			return "ABCDEF", nil
+		},
+		// событие берётся по hash транзакции, а не из последних событий кошелька
+		eventsFn: func(context.Context, string, int) (Events, error) { t.Fatal("wallet history scanned"); return Events{}, nil },
+		eventFn: func(ctx context.Context, id string) (Event, error) {
+			if id != "ABCDEF" { return Event{}, ErrTonAPINotFound }
+			return Event{EventID: "abcdef", Actions: []EventAction{
+				{Type: "TonTransfer", Amount: "2000000000", Recipient: "EQ_MERCHANT", Sender: "EQ_USER",
+					Payload: &EventPayload{Type: "comment", Text: "ORD-1"}},
+			}}, nil
+		},
+	}
+	res, err := NewTONServiceWithClient(mock).VerifyTonConnectTransaction(context.Background(), models.TonConnectVerifyRequest{
+		MerchantAddress: "EQ_MERCHANT", BOC: boc, AmountTon: "2", Comment: "ORD-1",
+	})
+	if err != nil { t.Fatalf("err: %v", err) }
+	if !res.Found || !res.Matched || res.Sender != "EQ_USER" { t.Fatalf("unexpected: %+v", res) }
+}
+
+func TestVerifyTonConnectTransaction_PendingVsUpstreamFailure(t *testing.T) {
+	req := models.TonConnectVerifyRequest{MerchantAddress: "EQ_MERCHANT", MessageHash: hex.EncodeToString(make([]byte, 32))}
+	for _, tc := range []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"not indexed yet", fmt.Errorf("%w: message transaction", ErrTonAPINotFound), false},
+		{"upstream 502", errors.New("tonapi message transaction status 502"), true},
+		{"timeout", context.DeadlineExceeded, true},
+	} {
+		mock := &mockTonAPI{msgTxFn: func(context.Context, string) (string, error) { return "", tc.err }}
+		res, err := NewTONServiceWithClient(mock).VerifyTonConnectTransaction(context.Background(), req)
+		if tc.wantErr && (err == nil || !errors.Is(err, tc.err)) { t.Errorf("%s: want error, got %+v, %v", tc.name, res, err) }
+		if !tc.wantErr && (err != nil || res.Found || res.TxHash != "") { t.Errorf("%s: want pending, got %+v, %v", tc.name, res, err) }
+	}
+}
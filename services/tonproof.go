@@ -0,0 +1,266 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+	"payment-service/models"
+)
+
+const (
+	tonProofPrefix   = "ton-proof-item-v2/"
+	tonConnectPrefix = "ton-connect"
+)
+
+var (
+	ErrProofDisabled  = errors.New("ton_proof is disabled: TON_PROOF_DOMAINS is not set")
+	ErrProofPayload   = errors.New("unknown or expired proof payload")
+	ErrProofDomain    = errors.New("proof domain is not allowed")
+	ErrProofExpired   = errors.New("proof timestamp is out of range")
+	ErrProofStateInit = errors.New("state_init does not match address")
+	ErrProofSignature = errors.New("invalid proof signature")
+	ErrSession        = errors.New("invalid or expired session token")
+)
+
+// TonProofService — выдача payload для ton_proof, проверка подписи кошелька
+// и выпуск короткоживущих сессионных токенов (HMAC, без хранения на сервере).
+// Без списка доменов ton_proof выключен: подпись для чужого домена не принимается.
+type TonProofService struct {
+	domains    []string
+	proofTTL   time.Duration
+	sessionTTL time.Duration
+	secret     []byte
+	now        func() time.Time
+
+	mu       sync.Mutex
+	payloads map[string]time.Time // payload -> срок действия
+}
+
+func NewTonProofService(domains []string, proofTTL, sessionTTL time.Duration, secret string) *TonProofService {
+	if proofTTL <= 0 {
+		proofTTL = 15 * time.Minute
+	}
+	if sessionTTL <= 0 {
+		sessionTTL = time.Hour
+	}
+	key := []byte(secret)
+	if len(key) == 0 {
+		// без SESSION_SECRET токены живут до перезапуска процесса
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &TonProofService{
+		domains:    domains,
+		proofTTL:   proofTTL,
+		sessionTTL: sessionTTL,
+		secret:     key,
+		now:        time.Now,
+		payloads:   make(map[string]time.Time),
+	}
+}
+
+// Enabled — задан список допустимых доменов.
+func (s *TonProofService) Enabled() bool { return len(s.domains) > 0 }
+
+// NewPayload — одноразовый nonce, который кошелёк должен подписать.
+func (s *TonProofService) NewPayload() (string, time.Time) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	p := hex.EncodeToString(b)
+	exp := s.now().Add(s.proofTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, e := range s.payloads {
+		if s.now().After(e) {
+			delete(s.payloads, k)
+		}
+	}
+	s.payloads[p] = exp
+	return p, exp
+}
+
+// Verify — проверяет ton_proof и выпускает сессионный токен для кошелька.
+func (s *TonProofService) Verify(req models.TonProofRequest) (*models.TonProofSession, error) {
+	if !s.Enabled() {
+		return nil, ErrProofDisabled
+	}
+	addr, err := parseTonAddress(req.Address)
+	if err != nil {
+		return nil, fmt.Errorf("bad address: %w", err)
+	}
+	if !s.domainAllowed(req.Proof.Domain.Value) {
+		return nil, ErrProofDomain
+	}
+	ts := time.Unix(req.Proof.Timestamp, 0)
+	if now := s.now(); ts.Before(now.Add(-s.proofTTL)) || ts.After(now.Add(time.Minute)) {
+		return nil, ErrProofExpired
+	}
+	// nonce гасится только после проверки подписи, иначе поддельный запрос сжёг бы nonce пользователя
+	if !s.payloadValid(req.Proof.Payload) {
+		return nil, ErrProofPayload
+	}
+
+	// state_init обязан хешироваться ровно в адрес — тогда ключ из него принадлежит кошельку
+	raw, err := decodeBase64Any(req.Proof.StateInit)
+	if err != nil {
+		return nil, fmt.Errorf("bad state_init: %w", err)
+	}
+	siCell, err := cell.FromBOC(raw)
+	if err != nil {
+		return nil, fmt.Errorf("bad state_init: %w", err)
+	}
+	if !hmac.Equal(siCell.Hash(), addr.Data()) {
+		return nil, ErrProofStateInit
+	}
+	var si tlb.StateInit
+	if err := tlb.LoadFromCell(&si, siCell.BeginParse()); err != nil || si.Data == nil {
+		return nil, fmt.Errorf("bad state_init: cannot load data")
+	}
+
+	sig, err := decodeBase64Any(req.Proof.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("bad signature: %w", err)
+	}
+	msg := tonProofMessage(addr.Workchain(), addr.Data(), req.Proof.Domain.Value, req.Proof.Timestamp, req.Proof.Payload)
+
+	for _, pub := range walletPublicKeys(si.Data) {
+		if req.PublicKey != "" && !strings.EqualFold(req.PublicKey, hex.EncodeToString(pub)) {
+			continue
+		}
+		if ed25519.Verify(pub, msg, sig) {
+			if !s.consumePayload(req.Proof.Payload) {
+				return nil, ErrProofPayload // использован параллельным запросом
+			}
+			rawAddr := fmt.Sprintf("%d:%s", addr.Workchain(), hex.EncodeToString(addr.Data()))
+			token, exp := s.issueSession(rawAddr)
+			return &models.TonProofSession{Token: token, Address: rawAddr, ExpiresAt: exp}, nil
+		}
+	}
+	return nil, ErrProofSignature
+}
+
+// SessionWallet — адрес (raw "0:hex"), подтверждённый токеном.
+func (s *TonProofService) SessionWallet(token string) (string, error) {
+	body, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrSession
+	}
+	want := s.sign(body)
+	got, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(got, want) {
+		return "", ErrSession
+	}
+	plain, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return "", ErrSession
+	}
+	wallet, expStr, ok := strings.Cut(string(plain), "|")
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if !ok || err != nil || s.now().Unix() > exp {
+		return "", ErrSession
+	}
+	return wallet, nil
+}
+
+func (s *TonProofService) issueSession(wallet string) (string, time.Time) {
+	exp := s.now().Add(s.sessionTTL)
+	body := base64.RawURLEncoding.EncodeToString([]byte(wallet + "|" + strconv.FormatInt(exp.Unix(), 10)))
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.sign(body)), exp
+}
+
+func (s *TonProofService) sign(body string) []byte {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(body))
+	return m.Sum(nil)
+}
+
+func (s *TonProofService) payloadValid(p string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.payloads[p]
+	return ok && !s.now().After(exp)
+}
+
+func (s *TonProofService) consumePayload(p string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.payloads[p]
+	if !ok {
+		return false
+	}
+	delete(s.payloads, p)
+	return !s.now().After(exp)
+}
+
+func (s *TonProofService) domainAllowed(domain string) bool {
+	for _, d := range s.domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// tonProofMessage — то, что подписывает кошелёк по спецификации TON Connect:
+// sha256(0xffff ++ "ton-connect" ++ sha256(message)).
+func tonProofMessage(workchain int32, addrHash []byte, domain string, ts int64, payload string) []byte {
+	var m []byte
+	m = append(m, tonProofPrefix...)
+	m = binary.BigEndian.AppendUint32(m, uint32(workchain))
+	m = append(m, addrHash...)
+	m = binary.LittleEndian.AppendUint32(m, uint32(len(domain)))
+	m = append(m, domain...)
+	m = binary.LittleEndian.AppendUint64(m, uint64(ts))
+	m = append(m, payload...)
+	inner := sha256.Sum256(m)
+
+	full := append([]byte{0xff, 0xff}, tonConnectPrefix...)
+	full = append(full, inner[:]...)
+	outer := sha256.Sum256(full)
+	return outer[:]
+}
+
+// walletPublicKeys — кандидаты публичного ключа из data стандартных кошельков:
+// v3/v4 — seqno(32) subwallet(32) pubkey; v5 — flag(1) seqno(32) wallet_id(32) pubkey; v2 — seqno(32) pubkey.
+func walletPublicKeys(data *cell.Cell) []ed25519.PublicKey {
+	var out []ed25519.PublicKey
+	for _, skip := range []uint{64, 65, 32} {
+		sl := data.BeginParse()
+		if sl.BitsLeft() < skip+256 {
+			continue
+		}
+		if _, err := sl.LoadSlice(skip); err != nil {
+			continue
+		}
+		key, err := sl.LoadSlice(256)
+		if err != nil {
+			continue
+		}
+		out = append(out, ed25519.PublicKey(key))
+	}
+	return out
+}
+
+// SameAddress — совпадают ли адреса с учётом форматов (raw "0:hex" и user-friendly).
+func SameAddress(a, b string) bool {
+	pa, errA := parseTonAddress(a)
+	pb, errB := parseTonAddress(b)
+	if errA != nil || errB != nil {
+		return equalsFold(a, b)
+	}
+	return pa.Workchain() == pb.Workchain() && hmac.Equal(pa.Data(), pb.Data())
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"payment-service/models"
+)
+
+func signedProof(t *testing.T, svc *TonProofService, priv ed25519.PrivateKey, domain string) models.TonProofRequest {
+	t.Helper()
+	si, err := wallet.GetStateInit(priv.Public().(ed25519.PublicKey), wallet.V4R2, wallet.DefaultSubwallet)
+	if err != nil { t.Fatalf("state init: %v", err) }
+	siCell, err := tlb.ToCell(si)
+	if err != nil { t.Fatalf("state init cell: %v", err) }
+	addr := address.NewAddress(0, 0, siCell.Hash())
+
+	payload, _ := svc.NewPayload()
+	ts := svc.now().Unix()
+	msg := tonProofMessage(0, addr.Data(), domain, ts, payload)
+	return models.TonProofRequest{
+		Address: addr.String(),
+		Proof: models.TonProof{
+			Timestamp: ts,
+			Domain:    models.TonProofDomain{LengthBytes: len(domain), Value: domain},
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, msg)),
+			Payload:   payload,
+			StateInit: base64.StdEncoding.EncodeToString(siCell.ToBOC()),
+		},
+	}
+}
+
+func TestTonProof_VerifyIssuesSession(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	svc := NewTonProofService([]string{"pay.example.com"}, time.Minute, time.Hour, "secret")
+	req := signedProof(t, svc, priv, "pay.example.com")
+
+	session, err := svc.Verify(req)
+	if err != nil { t.Fatalf("verify: %v", err) }
+	wallet, err := svc.SessionWallet(session.Token)
+	if err != nil { t.Fatalf("session: %v", err) }
+	if !SameAddress(wallet, req.Address) { t.Fatalf("session wallet %s != %s", wallet, req.Address) }
+
+	// payload одноразовый
+	if _, err := svc.Verify(req); err != ErrProofPayload { t.Fatalf("want ErrProofPayload, got %v", err) }
+}
+
+func TestTonProof_RejectsForeignKeyAndDomain(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+	svc := NewTonProofService([]string{"pay.example.com"}, time.Minute, time.Hour, "secret")
+
+	req := signedProof(t, svc, priv, "pay.example.com")
+	genuine := req.Proof.Signature
+	msg := tonProofMessage(0, address.MustParseAddr(req.Address).Data(), "pay.example.com", req.Proof.Timestamp, req.Proof.Payload)
+	req.Proof.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(other, msg))
+	if _, err := svc.Verify(req); err != ErrProofSignature { t.Fatalf("want ErrProofSignature, got %v", err) }
+	// поддельная подпись не гасит nonce пользователя
+	req.Proof.Signature = genuine
+	if _, err := svc.Verify(req); err != nil { t.Fatalf("genuine proof after forged one: %v", err) }
+
+	req = signedProof(t, svc, priv, "evil.example.com")
+	if _, err := svc.Verify(req); err != ErrProofDomain { t.Fatalf("want ErrProofDomain, got %v", err) }
+}
+
+func TestTonProof_ExpiredSession(t *testing.T) {
+	svc := NewTonProofService(nil, time.Minute, time.Minute, "secret")
+	token, _ := svc.issueSession("0:abc")
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := svc.SessionWallet(token); err != ErrSession { t.Fatalf("want ErrSession, got %v", err) }
+}
+
+func TestTonProof_DisabledWithoutDomains(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	svc := NewTonProofService(nil, time.Minute, time.Hour, "secret")
+	if svc.Enabled() { t.Fatal("enabled without TON_PROOF_DOMAINS") }
+	if _, err := svc.Verify(signedProof(t, svc, priv, "phishing.example")); err != ErrProofDisabled { t.Fatalf("want ErrProofDisabled, got %v", err) }
+}
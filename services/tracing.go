@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer — спаны методов TONService и запросов к TonAPI. Провайдер задаёт tracing.Setup;
+// без него спаны не записываются, но trace context из входящего запроса передаётся дальше.
+var tracer = otel.Tracer("payment-service/services")
+
+// startSpan — внутренний спан метода сервиса.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan — завершить спан; ошибка записывается событием и статусом Error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
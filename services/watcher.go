@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"payment-service/models"
+	"payment-service/storage"
+)
+
+const watchCursorsCollection = "watch_cursors" // кошелёк -> WatchCursor
+
+// IncomingTransfer — нормализованный входящий TonTransfer на отслеживаемый кошелёк.
+type IncomingTransfer struct {
+	EventID   string    `json:"event_id"`
+	Action    int       `json:"action,omitempty"` // номер действия в событии: в одном событии бывает несколько переводов
+	Wallet    string    `json:"wallet"`
+	Sender    string    `json:"sender"`
+	Amount    string    `json:"amount"` // TON "X.YYYYYYYYY"
+	Comment   string    `json:"comment,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TransferHook — потребитель входящих переводов (hub, сопоставление инвойсов и т.п.).
+type TransferHook func(ctx context.Context, t IncomingTransfer)
+
+// Watcher — периодически опрашивает события кошельков и отдаёт новые
+// входящие переводы зарегистрированным хукам. Это вход «конвейера» сопоставления платежей.
+type Watcher struct {
+	client   TonAPI
+	interval time.Duration
+	limit    int
+	store    *storage.Store
+
+	mu      sync.Mutex
+	wallets []string
+	hooks   []TransferHook
+	// seen — event_id из последнего окна по каждому кошельку; нет ключа — кошелёк ещё не «прогрет»
+	seen map[string]map[string]struct{}
+	// started — запуск Run, lastPoll — последний проход, где удалось опросить хотя бы один кошелёк
+	started  time.Time
+	lastPoll time.Time
+}
+
+func NewWatcher(svc *TONService, wallets []string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &Watcher{
+		client:   svc.client,
+		interval: interval,
+		limit:    50,
+		wallets:  append([]string(nil), wallets...),
+		seen:     make(map[string]map[string]struct{}),
+	}
+}
+
+// SetStore — курсоры опроса сохраняются, и после перезапуска кошельки не «прогреваются»
+// заново: переводы, пришедшие пока сервис стоял, тоже доходят до хуков.
+func (w *Watcher) SetStore(store *storage.Store) { w.store = store }
+
+func (w *Watcher) AddHook(h TransferHook) {
+	w.mu.Lock()
+	w.hooks = append(w.hooks, h)
+	w.mu.Unlock()
+}
+
+// AddWallet — начать отслеживать кошелёк (повторное добавление игнорируется).
+func (w *Watcher) AddWallet(wallet string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, x := range w.wallets {
+		if equalsFold(x, wallet) {
+			return
+		}
+	}
+	w.wallets = append(w.wallets, wallet)
+}
+
+// AddFreshWallet — начать отслеживать новый кошелёк без истории (например, только что
+// выведенный депозитный адрес): прогрева нет, любое его событие считается новым.
+func (w *Watcher) AddFreshWallet(wallet string) {
+	key := normKey(wallet)
+	w.mu.Lock()
+	if _, ok := w.seen[key]; !ok {
+		w.seen[key] = map[string]struct{}{}
+	}
+	w.mu.Unlock()
+	if w.store != nil {
+		if ok, _ := w.store.Get(watchCursorsCollection, LedgerWallet(wallet), &models.WatchCursor{}); !ok {
+			w.saveCursor(wallet, nil)
+		}
+	}
+	w.AddWallet(wallet)
+}
+
+func (w *Watcher) Wallets() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.wallets...)
+}
+
+// Run — цикл опроса до отмены контекста.
+func (w *Watcher) Run(ctx context.Context) {
+	w.mu.Lock()
+	w.started = time.Now()
+	w.mu.Unlock()
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		w.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Poll — один проход по всем кошелькам. Первый проход по кошельку без сохранённого курсора
+// только запоминает текущие события, чтобы не разослать историю как «новые» платежи.
+func (w *Watcher) Poll(ctx context.Context) {
+	wallets := w.Wallets()
+	polled := len(wallets) == 0
+	for _, wallet := range wallets {
+		if err := w.pollWallet(ctx, wallet); err != nil {
+			log.Printf("watcher: %s: %v", wallet, err)
+			continue
+		}
+		polled = true
+	}
+	if polled {
+		w.mu.Lock()
+		w.lastPoll = time.Now()
+		w.mu.Unlock()
+	}
+}
+
+// Lag — сколько прошло с последнего удачного прохода (с запуска Run, если проходов ещё не было).
+// Один «битый» кошелёк отставание не увеличивает — только сбой опроса всех кошельков.
+func (w *Watcher) Lag() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	last := w.lastPoll
+	if last.IsZero() {
+		last = w.started
+	}
+	if last.IsZero() {
+		return 0
+	}
+	return time.Since(last)
+}
+
+func (w *Watcher) pollWallet(ctx context.Context, wallet string) error {
+	evs, err := w.client.GetAccountEvents(ctx, wallet, w.limit)
+	if err != nil {
+		return err
+	}
+
+	key := normKey(wallet)
+	w.mu.Lock()
+	prev, primed := w.seen[key]
+	window := make(map[string]struct{}, len(evs.Events))
+	for _, ev := range evs.Events {
+		window[ev.EventID] = struct{}{}
+	}
+	w.seen[key] = window
+	hooks := append([]TransferHook(nil), w.hooks...)
+	w.mu.Unlock()
+
+	// окна в памяти нет (первый проход после запуска) — новое то, что позже сохранённого курсора
+	isNew := func(ev Event) bool { _, ok := prev[ev.EventID]; return !ok }
+	if !primed {
+		cursor, ok := w.loadCursor(wallet)
+		if !ok {
+			w.saveCursor(wallet, evs.Events)
+			return nil
+		}
+		isNew = func(ev Event) bool {
+			return cursor.EventID == "" || ev.EventID != cursor.EventID && ev.Lt > cursor.Lt
+		}
+	}
+
+	// события приходят от новых к старым — отдаём в хронологическом порядке
+	fresh := false
+	for i := len(evs.Events) - 1; i >= 0; i-- {
+		ev := evs.Events[i]
+		if !isNew(ev) {
+			continue
+		}
+		fresh = true
+		for _, t := range incomingTransfers(ev, wallet) {
+			for _, h := range hooks {
+				h(ctx, t)
+			}
+		}
+	}
+	if fresh {
+		w.saveCursor(wallet, evs.Events)
+	}
+	return nil
+}
+
+func (w *Watcher) loadCursor(wallet string) (models.WatchCursor, bool) {
+	var c models.WatchCursor
+	if w.store == nil {
+		return c, false
+	}
+	ok, err := w.store.Get(watchCursorsCollection, LedgerWallet(wallet), &c)
+	if err != nil {
+		log.Printf("watcher: %s: load cursor: %v", wallet, err)
+	}
+	return c, ok
+}
+
+// saveCursor — курсор на самое новое событие окна (пустое окно — пустой курсор).
+func (w *Watcher) saveCursor(wallet string, evs []Event) {
+	if w.store == nil {
+		return
+	}
+	c := models.WatchCursor{Wallet: wallet, UpdatedAt: time.Now().UTC()}
+	if len(evs) > 0 {
+		c.EventID, c.Lt = evs[0].EventID, evs[0].Lt
+	}
+	if err := w.store.Put(watchCursorsCollection, LedgerWallet(wallet), c); err != nil {
+		log.Printf("watcher: %s: save cursor: %v", wallet, err)
+	}
+}
+
+// incomingTransfers — все TonTransfer события, зачисленные на wallet (без прерванных и отскочивших).
+func incomingTransfers(ev Event, wallet string) []IncomingTransfer {
+	var ts time.Time
+	if ev.Timestamp != nil && *ev.Timestamp > 0 {
+		ts = time.Unix(*ev.Timestamp, 0).UTC()
+	}
+	var out []IncomingTransfer
+	for i, a := range ev.Actions {
+		// TonAPI отдаёт адреса в raw-формате, а кошельки у нас обычно user-friendly
+		if !equalsFold(a.Type, "TonTransfer") || !SameAddress(a.Recipient, wallet) || !settled(a) {
+			continue
+		}
+		amt, err := nanosStrToTon(a.Amount)
+		if err != nil {
+			continue
+		}
+		comment := ""
+		if a.Payload != nil && equalsFold(a.Payload.Type, "comment") {
+			comment = a.Payload.Text
+		}
+		out = append(out, IncomingTransfer{
+			EventID:   ev.EventID,
+			Action:    i,
+			Wallet:    wallet,
+			Sender:    a.Sender,
+			Amount:    amt.StringFixed(9),
+			Comment:   comment,
+			Timestamp: ts,
+		})
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"payment-service/storage"
+)
+
+func TestWatcher_PublishesOnlyNewIncomingTransfers(t *testing.T) {
+	call := 0
+	mock := &mockTonAPI{
+		eventsFn: func(ctx context.Context, accountID string, limit int) (Events, error) {
+			call++
+			old := Event{EventID: "E1", Actions: []EventAction{
+				{Type: "TonTransfer", Amount: "1000000000", Recipient: "EQ_MERCHANT", Sender: "EQ_A"},
+			}}
+			if call == 1 {
+				return Events{Events: []Event{old}}, nil
+			}
+			return Events{Events: []Event{
+				{EventID: "E3", Actions: []EventAction{
+					{Type: "TonTransfer", Amount: "5000000000", Recipient: "EQ_ELSEWHERE", Sender: "EQ_MERCHANT"},
+				}},
+				{EventID: "E2", Actions: []EventAction{
+					{Type: "TonTransfer", Amount: "2500000000", Recipient: "EQ_MERCHANT", Sender: "EQ_B",
+						Payload: &EventPayload{Type: "comment", Text: "ORD-1"}},
+				}},
+				old,
+			}}, nil
+		},
+	}
+	w := NewWatcher(NewTONServiceWithClient(mock), []string{"EQ_MERCHANT"}, time.Second)
+	var got []IncomingTransfer
+	w.AddHook(func(_ context.Context, tr IncomingTransfer) { got = append(got, tr) })
+
+	w.Poll(context.Background()) // прогрев
+	w.Poll(context.Background())
+
+	if len(got) != 1 {
+		t.Fatalf("want 1 transfer, got %d: %+v", len(got), got)
+	}
+	if got[0].EventID != "E2" || got[0].Amount != "2.500000000" || got[0].Comment != "ORD-1" {
+		t.Fatalf("unexpected transfer: %+v", got[0])
+	}
+}
+
+func transferEvent(id string, lt int64, to string) Event {
+	return Event{EventID: id, Lt: lt, Actions: []EventAction{{Type: "TonTransfer", Amount: "1000000000", Recipient: to, Sender: "EQ_A"}}}
+}
+
+// Новый депозитный адрес не прогревается, а курсор переживает перезапуск:
+// переводы, пришедшие между опросами разных процессов, не теряются и не повторяются.
+func TestWatcher_FreshWalletsAndPersistedCursor(t *testing.T) {
+	events := map[string][]Event{}
+	mock := &mockTonAPI{eventsFn: func(_ context.Context, id string, _ int) (Events, error) { return Events{Events: events[id]}, nil }}
+	store := storage.NewMemory()
+	start := func() (*Watcher, *[]string) {
+		w := NewWatcher(NewTONServiceWithClient(mock), []string{"EQ_MERCHANT"}, time.Second)
+		w.SetStore(store)
+		var got []string
+		w.AddHook(func(_ context.Context, tr IncomingTransfer) { got = append(got, tr.EventID) })
+		return w, &got
+	}
+
+	events["EQ_MERCHANT"] = []Event{transferEvent("M1", 10, "EQ_MERCHANT")}
+	w, got := start()
+	w.AddFreshWallet("EQ_DEPOSIT")
+	events["EQ_DEPOSIT"] = []Event{transferEvent("D1", 20, "EQ_DEPOSIT")}
+	w.Poll(context.Background())
+	if strings.Join(*got, ",") != "D1" { t.Fatalf("first poll: %v", *got) }
+
+	// перезапуск: пока сервис стоял, пришли M2 и D2
+	events["EQ_MERCHANT"] = append([]Event{transferEvent("M2", 30, "EQ_MERCHANT")}, events["EQ_MERCHANT"]...)
+	events["EQ_DEPOSIT"] = append([]Event{transferEvent("D2", 40, "EQ_DEPOSIT")}, events["EQ_DEPOSIT"]...)
+	w, got = start()
+	w.AddWallet("EQ_DEPOSIT")
+	w.Poll(context.Background())
+	w.Poll(context.Background())
+	if strings.Join(*got, ",") != "M2,D2" { t.Fatalf("after restart: %v", *got) }
+
+	// курсор свежего адреса без событий тоже сохраняется
+	w.AddFreshWallet("EQ_EMPTY")
+	events["EQ_EMPTY"] = []Event{transferEvent("X1", 50, "EQ_EMPTY")}
+	w, got = start()
+	w.AddWallet("EQ_EMPTY")
+	w.Poll(context.Background())
+	if strings.Join(*got, ",") != "X1" { t.Fatalf("fresh wallet after restart: %v", *got) }
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store — простое key-value хранилище по коллекциям (invoices, merchants, ...).
+// Значения хранятся как JSON. Если задан path, каждое изменение атомарно
+// записывается в файл (tmp + rename); пустой path — только память.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+	data map[string]map[string]json.RawMessage
+}
+
+// Open — загружает хранилище из файла (если он есть).
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, data: make(map[string]map[string]json.RawMessage)}
+	if path == "" {
+		return s, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read storage: %w", err)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &s.data); err != nil {
+			return nil, fmt.Errorf("parse storage %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// NewMemory — хранилище без файла (тесты, dev).
+func NewMemory() *Store {
+	s, _ := Open("")
+	return s
+}
+
+func (s *Store) Get(collection, key string, v any) (bool, error) {
+	s.mu.RLock()
+	raw, ok := s.data[collection][key]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (s *Store) Put(collection, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.data[collection]
+	if !ok {
+		c = make(map[string]json.RawMessage)
+		s.data[collection] = c
+	}
+	c[key] = raw
+	return s.flushLocked()
+}
+
+func (s *Store) Delete(collection, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[collection][key]; !ok {
+		return nil
+	}
+	delete(s.data[collection], key)
+	return s.flushLocked()
+}
+
+// Keys — ключи коллекции в отсортированном порядке.
+func (s *Store) Keys(collection string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.data[collection]))
+	for k := range s.data[collection] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// List — все значения коллекции, упорядоченные по ключу.
+func List[T any](s *Store, collection string) ([]T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.data[collection]))
+	for k := range s.data[collection] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]T, 0, len(keys))
+	for _, k := range keys {
+		var v T
+		if err := json.Unmarshal(s.data[collection][k], &v); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", collection, k, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Ping — доступен ли каталог файла хранилища на запись (для проверки готовности).
+func (s *Store) Ping() error {
+	if s.path == "" {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.ping")
+	if err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// Flush — принудительная запись на диск.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+func (s *Store) flushLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	raw, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write storage: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write storage: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write storage: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write storage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type item struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestStore_PersistsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	s, err := Open(path)
+	if err != nil { t.Fatalf("open: %v", err) }
+	if err := s.Put("items", "b", item{ID: "b", Name: "second"}); err != nil { t.Fatalf("put: %v", err) }
+	if err := s.Put("items", "a", item{ID: "a", Name: "first"}); err != nil { t.Fatalf("put: %v", err) }
+
+	reopened, err := Open(path)
+	if err != nil { t.Fatalf("reopen: %v", err) }
+	items, err := List[item](reopened, "items")
+	if err != nil { t.Fatalf("list: %v", err) }
+	if len(items) != 2 || items[0].ID != "a" || items[1].Name != "second" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	if err := reopened.Delete("items", "a"); err != nil { t.Fatalf("delete: %v", err) }
+	var got item
+	if ok, _ := reopened.Get("items", "a", &got); ok { t.Fatalf("item a still present") }
+}
+
+func TestStore_PingChecksDirectory(t *testing.T) {
+	if err := NewMemory().Ping(); err != nil { t.Fatalf("memory: %v", err) }
+	s, err := Open(filepath.Join(t.TempDir(), "data.json"))
+	if err != nil { t.Fatalf("open: %v", err) }
+	if err := s.Ping(); err != nil { t.Fatalf("ping: %v", err) }
+	s.path = filepath.Join(t.TempDir(), "missing", "data.json")
+	if err := s.Ping(); err == nil { t.Fatalf("ping of missing directory succeeded") }
+}
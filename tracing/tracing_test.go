@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"payment-service/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestSetup_StdoutExporterAndW3CPropagation(t *testing.T) {
+	var out bytes.Buffer
+	Stdout = &out
+	shutdown, err := Setup(context.Background(), &config.Config{TracesExporter: "stdout"}, "1.2.3")
+	if err != nil { t.Fatalf("setup: %v", err) }
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "work")
+	h := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h))
+	span.End()
+	if tp := h.Get("traceparent"); !strings.HasPrefix(tp, "00-"+span.SpanContext().TraceID().String()) { t.Fatalf("traceparent %q", tp) }
+
+	if err := shutdown(context.Background()); err != nil { t.Fatalf("shutdown: %v", err) }
+	for _, want := range []string{`"Name":"work"`, `"Value":"payment-service"`, `"Value":"1.2.3"`} {
+		if !strings.Contains(out.String(), want) { t.Errorf("stdout export lacks %s:\n%s", want, out.String()) }
+	}
+
+	shutdown, err = Setup(context.Background(), &config.Config{TracesExporter: "otlp", OTLPEndpoint: "http://127.0.0.1:4318"}, "dev")
+	if err != nil { t.Fatalf("otlp: %v", err) }
+	_ = shutdown(context.Background())
+	if _, err := Setup(context.Background(), &config.Config{TracesExporter: "zipkin"}, "dev"); err == nil { t.Fatal("unknown exporter accepted") }
+}
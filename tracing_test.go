@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"payment-service/config"
+	"payment-service/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// Один запрос check-payment: HTTP-спан продолжает трассу клиента, спан TONService — его потомок,
+// спан TonAPI — потомок сервиса с шаблоном URL, статусом и числом повторов; traceparent уходит в TonAPI.
+func TestTracing_SpansFromRequestToTonAPI(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var calls atomic.Int32
+	var outbound atomic.Value
+	tonapi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outbound.Store(r.Header.Get("traceparent"))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // первый запрос повторяется
+			return
+		}
+		_, _ = io.WriteString(w, `{"events":[{"event_id":"e1","timestamp":1700000000,"actions":[
+		 {"type":"TonTransfer","status":"ok","TonTransfer":{"sender":{"address":"0:a"},"recipient":{"address":"`+testWallet+`"},"amount":1500000000,"comment":"hi"}}]}]}`)
+	}))
+	t.Cleanup(tonapi.Close)
+
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TON_API_URL", tonapi.URL)
+	t.Setenv("EXPORT_DIR", t.TempDir())
+	cfg, err := config.Load("")
+	if err != nil { t.Fatalf("config: %v", err) }
+	a, err := newApp(config.Static(cfg), storage.NewMemory())
+	if err != nil { t.Fatalf("app: %v", err) }
+
+	const clientTrace, clientSpan = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest("POST", "/api/check-payment", strings.NewReader(`{"merchant_address":"`+testWallet+`","comment":"hi","min_amount_ton":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+clientTrace+"-"+clientSpan+"-01")
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"data":true`) { t.Fatalf("check-payment: %d %s", w.Code, w.Body) }
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		spans[s.Name()] = s
+	}
+	server, service, client := spans["POST /api/check-payment"], spans["TONService.CheckPayment"], spans["tonapi GET /v2/accounts/{account_id}/events"]
+	if server == nil || service == nil || client == nil { t.Fatalf("spans: %v", spans) }
+
+	if server.SpanContext().TraceID().String() != clientTrace || server.Parent().SpanID().String() != clientSpan || !server.Parent().IsRemote() { t.Fatalf("server span does not continue client trace: %v", server.Parent()) }
+	if server.SpanKind() != trace.SpanKindServer || spanAttr(server, "http.route").AsString() != "/api/check-payment" || spanAttr(server, "http.response.status_code").AsInt64() != 200 { t.Fatalf("server span: %v", server.Attributes()) }
+	if service.Parent().SpanID() != server.SpanContext().SpanID() || !spanAttr(service, "payment.paid").AsBool() { t.Fatalf("service span: %v %v", service.Parent(), service.Attributes()) }
+	if client.Parent().SpanID() != service.SpanContext().SpanID() || client.SpanKind() != trace.SpanKindClient { t.Fatalf("tonapi span parent: %v", client.Parent()) }
+	if spanAttr(client, "url.template").AsString() != "/v2/accounts/{account_id}/events" || spanAttr(client, "http.response.status_code").AsInt64() != 200 || spanAttr(client, "http.request.resend_count").AsInt64() != 1 { t.Fatalf("tonapi span: %v", client.Attributes()) }
+
+	want := "00-" + clientTrace + "-" + client.SpanContext().SpanID().String() + "-01"
+	if got, _ := outbound.Load().(string); got != want { t.Fatalf("outbound traceparent %q, want %q", got, want) }
+}
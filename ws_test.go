@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialWS(t *testing.T, srv *httptest.Server, token string) *websocket.Conn {
+	t.Helper()
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/ws", header)
+	if err != nil { t.Fatalf("dial: %v", err) }
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func wsCall(t *testing.T, conn *websocket.Conn, msg map[string]any) map[string]any {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil { t.Fatalf("write: %v", err) }
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var reply map[string]any
+	if err := conn.ReadJSON(&reply); err != nil { t.Fatalf("read %v: %v", msg["action"], err) }
+	return reply
+}
+
+// Анонимное соединение закрывается, как только перезагрузка конфигурации требует аутентификацию;
+// соединение с ключом остаётся.
+func TestWS_AnonymousClosedWhenAuthBecomesRequired(t *testing.T) {
+	a := testApp(t)
+	srv := httptest.NewServer(a.router)
+	t.Cleanup(srv.Close)
+
+	anon := dialWS(t, srv, "")
+	if r := wsCall(t, anon, map[string]any{"action": "subscribe", "wallets": []string{testWallet}}); r["type"] != "ack" || r["denied"] != nil { t.Fatalf("anonymous subscribe: %v", r) }
+	admin := dialWS(t, srv, adminKey)
+	if r := wsCall(t, admin, map[string]any{"action": "subscribe", "wallets": []string{testWallet}}); r["type"] != "ack" { t.Fatalf("admin subscribe: %v", r) }
+
+	t.Setenv("AUTH_REQUIRED", "true")
+	if err := a.live.Reload(); err != nil { t.Fatalf("reload: %v", err) }
+
+	_ = anon.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var ce *websocket.CloseError
+	if _, _, err := anon.ReadMessage(); !errors.As(err, &ce) || ce.Code != websocket.ClosePolicyViolation { t.Fatalf("anonymous connection after reload: %v", err) }
+	if r := wsCall(t, admin, map[string]any{"action": "ping"}); r["type"] != "pong" { t.Fatalf("admin connection after reload: %v", r) }
+}